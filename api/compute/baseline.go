@@ -0,0 +1,480 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+const (
+	// BaselineMajorityClass always predicts the most frequent training class.
+	BaselineMajorityClass = "majorityClass"
+	// BaselineNearestNeighbours predicts the most frequent class of the
+	// nearest training rows.
+	BaselineNearestNeighbours = "nearestNeighbours"
+	// BaselineMean always predicts the training target mean.
+	BaselineMean = "mean"
+	// BaselineLinear predicts using a least squares linear fit.
+	BaselineLinear = "linear"
+
+	baselineNeighbours   = 5
+	baselineMaxNeighbors = 5000
+	baselineRidge        = 1e-6
+)
+
+// baselineData holds the numeric features and the target of a dataset.
+type baselineData struct {
+	features [][]float64
+	target   []string
+}
+
+// persistBaselines scores trivial baseline models on the persisted train and
+// test split of the request with the request metrics, predicting the
+// primary target only. Baselines are a reference point only so failures are
+// logged rather than failing the request.
+func (s *SolutionRequest) persistBaselines(solutionStorage api.SolutionStorage, requestID string, trainSchemaFile string, testSchemaFile string) {
+	// a resumed request only stores the scores missing from its first run
+	existing, err := solutionStorage.FetchBaselineScores(requestID)
+	if err != nil {
+		log.Warnf("unable to fetch baselines of request %s: %v", requestID, err)
+		return
+	}
+	stored := make(map[string]bool)
+	for _, score := range existing {
+		stored[baselineScoreKey(score.Baseline, score.Metric)] = true
+	}
+
+	numerical := s.Task == defaultTaskTypeNumerical || s.Task == TaskTypeForecasting
+	if !numerical && s.Task != defaultTaskTypeCategorical {
+		log.Infof("no baselines for %s task of request %s", s.Task, requestID)
+		return
+	}
+
+	trainHeader, trainRows, err := readDatasetRows(trainSchemaFile)
+	if err != nil {
+		log.Warnf("unable to read baseline train data of request %s: %v", requestID, err)
+		return
+	}
+	testHeader, testRows, err := readDatasetRows(testSchemaFile)
+	if err != nil {
+		log.Warnf("unable to read baseline test data of request %s: %v", requestID, err)
+		return
+	}
+
+	train, test, err := prepareBaselineData(trainHeader, trainRows, testHeader, testRows, s.TargetFeature, s.Filters.Variables)
+	if err != nil {
+		log.Warnf("unable to prepare baseline data of request %s: %v", requestID, err)
+		return
+	}
+
+	predictions := computeBaselines(train, test, numerical)
+	for _, baseline := range sortedBaselines(predictions) {
+		for _, metric := range s.Metrics {
+			if stored[baselineScoreKey(baseline, convertMetricToTA2(metric))] {
+				continue
+			}
+			score, err := scorePredictions(metric, test.target, predictions[baseline])
+			if err != nil {
+				log.Infof("unable to score %s baseline of request %s: %v", baseline, requestID, err)
+				continue
+			}
+			err = solutionStorage.PersistBaselineScore(requestID, baseline, convertMetricToTA2(metric), score)
+			if err != nil {
+				log.Warnf("unable to persist %s baseline of request %s: %v", baseline, requestID, err)
+				return
+			}
+		}
+	}
+}
+
+func baselineScoreKey(baseline string, metric string) string {
+	return baseline + ":" + metric
+}
+
+// prepareBaselineData extracts the target and the numeric selected features
+// of the train and test rows. Features are standardized with the train
+// statistics and missing values are replaced by the train mean.
+func prepareBaselineData(trainHeader []string, trainRows [][]string, testHeader []string, testRows [][]string, target string, variables []string) (*baselineData, *baselineData, error) {
+	trainTarget, err := getSplitColumnIndex(trainHeader, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	testTarget, err := getSplitColumnIndex(testHeader, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// only keep the selected features that are numeric in the train data
+	type column struct {
+		train int
+		test  int
+		mean  float64
+		std   float64
+	}
+	columns := make([]*column, 0)
+	for _, variable := range variables {
+		if variable == target || variable == model.D3MIndexFieldName {
+			continue
+		}
+		trainIndex, err := getSplitColumnIndex(trainHeader, variable)
+		if err != nil {
+			continue
+		}
+		testIndex, err := getSplitColumnIndex(testHeader, variable)
+		if err != nil {
+			continue
+		}
+
+		values := make([]float64, 0, len(trainRows))
+		numeric := true
+		for _, row := range trainRows {
+			if row[trainIndex] == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(row[trainIndex], 64)
+			if err != nil {
+				numeric = false
+				break
+			}
+			values = append(values, value)
+		}
+		if !numeric || len(values) == 0 {
+			continue
+		}
+
+		mean, std := meanStd(values)
+		if std == 0 {
+			// constant columns carry no information
+			continue
+		}
+		columns = append(columns, &column{train: trainIndex, test: testIndex, mean: mean, std: std})
+	}
+
+	extract := func(rows [][]string, targetIndex int, isTrain bool) *baselineData {
+		data := &baselineData{
+			features: make([][]float64, len(rows)),
+			target:   make([]string, len(rows)),
+		}
+		for i, row := range rows {
+			data.target[i] = row[targetIndex]
+			data.features[i] = make([]float64, len(columns))
+			for j, c := range columns {
+				index := c.test
+				if isTrain {
+					index = c.train
+				}
+				value, err := strconv.ParseFloat(row[index], 64)
+				if err != nil {
+					value = c.mean
+				}
+				data.features[i][j] = (value - c.mean) / c.std
+			}
+		}
+		return data
+	}
+
+	train := extract(trainRows, trainTarget, true)
+	if len(train.target) == 0 {
+		return nil, nil, errors.New("no train rows")
+	}
+
+	return train, extract(testRows, testTarget, false), nil
+}
+
+// computeBaselines returns the test predictions of each baseline. Feature
+// based baselines are skipped when there are no numeric features.
+func computeBaselines(train *baselineData, test *baselineData, numerical bool) map[string][]string {
+	predictions := make(map[string][]string)
+	hasFeatures := len(train.features[0]) > 0
+
+	if numerical {
+		y, err := parseTargetValues(train.target)
+		if err != nil {
+			log.Warnf("unable to compute regression baselines: %v", err)
+			return predictions
+		}
+		mean, _ := meanStd(y)
+		predictions[BaselineMean] = constantPredictions(strconv.FormatFloat(mean, 'g', -1, 64), len(test.target))
+		if hasFeatures {
+			predictions[BaselineLinear] = predictLinear(train.features, y, test.features)
+		}
+		return predictions
+	}
+
+	predictions[BaselineMajorityClass] = constantPredictions(majorityClass(train.target), len(test.target))
+	if hasFeatures {
+		predictions[BaselineNearestNeighbours] = predictNearestNeighbours(train, test.features, baselineNeighbours)
+	}
+	return predictions
+}
+
+func sortedBaselines(predictions map[string][]string) []string {
+	baselines := make([]string, 0, len(predictions))
+	for baseline := range predictions {
+		baselines = append(baselines, baseline)
+	}
+	sort.Strings(baselines)
+	return baselines
+}
+
+func constantPredictions(value string, count int) []string {
+	predictions := make([]string, count)
+	for i := range predictions {
+		predictions[i] = value
+	}
+	return predictions
+}
+
+// majorityClass returns the most frequent class, the smallest one on ties.
+func majorityClass(classes []string) string {
+	counts := make(map[string]int)
+	for _, class := range classes {
+		counts[class]++
+	}
+	majority := ""
+	max := -1
+	for class, count := range counts {
+		if count > max || (count == max && class < majority) {
+			majority = class
+			max = count
+		}
+	}
+	return majority
+}
+
+func predictNearestNeighbours(train *baselineData, features [][]float64, k int) []string {
+	// bound the cost by using an evenly spaced sample of the train rows
+	step := 1
+	if len(train.features) > baselineMaxNeighbors {
+		step = int(math.Ceil(float64(len(train.features)) / baselineMaxNeighbors))
+	}
+
+	type neighbour struct {
+		distance float64
+		class    string
+	}
+	predictions := make([]string, len(features))
+	for i, row := range features {
+		neighbours := make([]*neighbour, 0, len(train.features)/step+1)
+		for j := 0; j < len(train.features); j += step {
+			distance := 0.0
+			for f, value := range row {
+				d := value - train.features[j][f]
+				distance += d * d
+			}
+			neighbours = append(neighbours, &neighbour{distance, train.target[j]})
+		}
+		sort.SliceStable(neighbours, func(a, b int) bool {
+			return neighbours[a].distance < neighbours[b].distance
+		})
+		if len(neighbours) > k {
+			neighbours = neighbours[:k]
+		}
+		classes := make([]string, len(neighbours))
+		for n, neighbour := range neighbours {
+			classes[n] = neighbour.class
+		}
+		predictions[i] = majorityClass(classes)
+	}
+	return predictions
+}
+
+// predictLinear fits an ordinary least squares model with an intercept,
+// slightly regularized to cope with collinear features.
+func predictLinear(x [][]float64, y []float64, test [][]float64) []string {
+	n := len(x[0]) + 1
+
+	// accumulate the normal equations
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n+1)
+	}
+	for r, row := range x {
+		augmented := append([]float64{1}, row...)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				a[i][j] += augmented[i] * augmented[j]
+			}
+			a[i][n] += augmented[i] * y[r]
+		}
+	}
+	for i := 1; i < n; i++ {
+		a[i][i] += baselineRidge * float64(len(x))
+	}
+
+	weights := solveLinearSystem(a)
+
+	predictions := make([]string, len(test))
+	for i, row := range test {
+		value := weights[0]
+		for j, feature := range row {
+			value += weights[j+1] * feature
+		}
+		predictions[i] = strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return predictions
+}
+
+// solveLinearSystem solves an augmented matrix using gaussian elimination
+// with partial pivoting. Singular directions get a weight of 0.
+func solveLinearSystem(a [][]float64) []float64 {
+	n := len(a)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		if math.Abs(a[col][col]) < 1e-12 {
+			continue
+		}
+		for row := 0; row < n; row++ {
+			if row == col {
+				continue
+			}
+			factor := a[row][col] / a[col][col]
+			for c := col; c <= n; c++ {
+				a[row][c] -= factor * a[col][c]
+			}
+		}
+	}
+
+	weights := make([]float64, n)
+	for i := range weights {
+		if math.Abs(a[i][i]) >= 1e-12 {
+			weights[i] = a[i][n] / a[i][i]
+		}
+	}
+	return weights
+}
+
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	squares := 0.0
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+func parseTargetValues(values []string) ([]float64, error) {
+	parsed := make([]float64, len(values))
+	for i, value := range values {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse target value of row %d", i)
+		}
+		parsed[i] = v
+	}
+	return parsed, nil
+}
+
+// scorePredictions computes a TA3 metric for the predictions, as done for the
+// baselines and the cross validation folds.
+func scorePredictions(metric string, actual []string, predicted []string) (float64, error) {
+	if len(actual) == 0 || len(actual) != len(predicted) {
+		return 0, errors.New("no predictions to score")
+	}
+
+	switch strings.ToLower(metric) {
+	case "accuracy", "f1micro":
+		// micro averaged f1 is the accuracy for single label problems
+		correct := 0
+		for i := range actual {
+			if actual[i] == predicted[i] {
+				correct++
+			}
+		}
+		return float64(correct) / float64(len(actual)), nil
+
+	case "f1macro":
+		classes := make(map[string]bool)
+		for _, class := range actual {
+			classes[class] = true
+		}
+		sum := 0.0
+		for class := range classes {
+			var tp, fp, fn float64
+			for i := range actual {
+				if predicted[i] == class && actual[i] == class {
+					tp++
+				} else if predicted[i] == class {
+					fp++
+				} else if actual[i] == class {
+					fn++
+				}
+			}
+			if tp > 0 {
+				sum += 2 * tp / (2*tp + fp + fn)
+			}
+		}
+		return sum / float64(len(classes)), nil
+	}
+
+	// regression metrics
+	y, err := parseTargetValues(actual)
+	if err != nil {
+		return 0, err
+	}
+	p, err := parseTargetValues(predicted)
+	if err != nil {
+		return 0, err
+	}
+	var squares, absolutes float64
+	for i := range y {
+		squares += (y[i] - p[i]) * (y[i] - p[i])
+		absolutes += math.Abs(y[i] - p[i])
+	}
+	count := float64(len(y))
+
+	switch strings.ToLower(metric) {
+	case "meansquarederror":
+		return squares / count, nil
+	case "rootmeansquarederror":
+		return math.Sqrt(squares / count), nil
+	case "meanabsoluteerror":
+		return absolutes / count, nil
+	case "meanabsolutepercentageerror":
+		return meanAbsolutePercentageError(y, p)
+	case "rsquared":
+		_, std := meanStd(y)
+		total := std * std * count
+		if total == 0 {
+			return 0, errors.New("target has no variance")
+		}
+		return 1 - squares/total, nil
+	}
+
+	return 0, errors.Errorf("metric %s not supported for local scoring", metric)
+}
@@ -0,0 +1,106 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScorePredictions(t *testing.T) {
+	actual := []string{"a", "a", "b", "b"}
+	predicted := []string{"a", "a", "a", "b"}
+
+	score, err := scorePredictions("accuracy", actual, predicted)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, score)
+
+	// f1 of a is 0.8, f1 of b is 2/3
+	score, err = scorePredictions("f1Macro", actual, predicted)
+	assert.NoError(t, err)
+	assert.InDelta(t, (0.8+2.0/3.0)/2, score, 1e-9)
+
+	score, err = scorePredictions("meanAbsoluteError", []string{"1", "2", "3"}, []string{"2", "2", "2"})
+	assert.NoError(t, err)
+	assert.InDelta(t, 2.0/3.0, score, 1e-9)
+
+	// zero actual values are skipped
+	score, err = scorePredictions("meanAbsolutePercentageError", []string{"0", "2", "4"}, []string{"1", "1", "5"})
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.375, score, 1e-9)
+
+	score, err = scorePredictions("rSquared", []string{"1", "2", "3"}, []string{"2", "2", "2"})
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, score, 1e-9)
+
+	_, err = scorePredictions("objectDetectionAP", actual, predicted)
+	assert.Error(t, err)
+}
+
+func TestPrepareBaselineData(t *testing.T) {
+	header := []string{"d3mIndex", "x", "label", "target"}
+	rows := [][]string{
+		{"0", "1", "u", "a"},
+		{"1", "3", "v", "b"},
+		{"2", "", "u", "a"},
+	}
+
+	train, test, err := prepareBaselineData(header, rows, header, rows[:1], "target", []string{"d3mIndex", "x", "label", "target"})
+	assert.NoError(t, err)
+
+	// the text column is dropped and the missing value is set to the mean
+	assert.Equal(t, []float64{-1}, train.features[0])
+	assert.Equal(t, []float64{1}, train.features[1])
+	assert.Equal(t, []float64{0}, train.features[2])
+	assert.Equal(t, []string{"a", "b", "a"}, train.target)
+	assert.Equal(t, []string{"a"}, test.target)
+}
+
+func TestComputeBaselinesRegression(t *testing.T) {
+	train := &baselineData{target: make([]string, 0)}
+	for i := 0; i < 10; i++ {
+		train.features = append(train.features, []float64{float64(i)})
+		train.target = append(train.target, strconv.Itoa(2*i+1))
+	}
+	test := &baselineData{
+		features: [][]float64{{20}},
+		target:   []string{"41"},
+	}
+
+	predictions := computeBaselines(train, test, true)
+	assert.Equal(t, []string{"10"}, predictions[BaselineMean])
+
+	value, err := strconv.ParseFloat(predictions[BaselineLinear][0], 64)
+	assert.NoError(t, err)
+	assert.InDelta(t, 41, value, 1e-3)
+}
+
+func TestComputeBaselinesClassification(t *testing.T) {
+	train := &baselineData{
+		features: [][]float64{{0}, {0.1}, {0.2}, {5}, {5.1}, {5.2}, {5.3}},
+		target:   []string{"a", "a", "a", "b", "b", "b", "b"},
+	}
+	test := &baselineData{
+		features: [][]float64{{0.05}, {5.05}},
+		target:   []string{"a", "b"},
+	}
+
+	predictions := computeBaselines(train, test, false)
+	assert.Equal(t, []string{"b", "b"}, predictions[BaselineMajorityClass])
+	assert.Equal(t, []string{"a", "b"}, predictions[BaselineNearestNeighbours])
+}
@@ -16,15 +16,16 @@
 package compute
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"math/rand"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/mitchellh/hashstructure"
@@ -41,6 +42,7 @@ import (
 const (
 	trainFilenamePrefix = "train"
 	testFilenamePrefix  = "test"
+	dataSplitsFilename  = "dataSplits.json"
 )
 
 // FilteredDataProvider defines a function that will fetch data from a back end source given
@@ -96,8 +98,8 @@ type DataReference struct {
 }
 
 // Hash the filter set
-func getFilteredDatasetHash(dataset string, target string, filterParams *api.FilterParams, isTrain bool) (uint64, error) {
-	hash, err := hashstructure.Hash([]interface{}{dataset, target, *filterParams, isTrain}, nil)
+func getFilteredDatasetHash(dataset string, target string, filterParams *api.FilterParams, split *SplitSpec, isTrain bool) (uint64, error) {
+	hash, err := hashstructure.Hash([]interface{}{dataset, target, *filterParams, *split, isTrain}, nil)
 	if err != nil {
 		return 0, errors.Wrapf(err, "failed to generate hashcode for %s", dataset)
 	}
@@ -108,27 +110,128 @@ func updateSchemaReferenceFile(schema string, prevReferenceFile string, newRefer
 	return strings.Replace(schema, fmt.Sprintf("\"resPath\": \"%s\"", prevReferenceFile), fmt.Sprintf("\"resPath\": \"%s\"", newReferenceFile), 1)
 }
 
-func splitTrainTest(sourceFile string, trainFile string, testFile string, hasHeader bool) error {
-	// create the writers
-	outputTrain := &bytes.Buffer{}
-	writerTrain := csv.NewWriter(outputTrain)
-	outputTest := &bytes.Buffer{}
-	writerTest := csv.NewWriter(outputTest)
+func splitTrainTest(sourceFile string, trainFile string, testFile string, hasHeader bool, split *SplitSpec, target string) error {
+	header, lines, err := readSplitSource(sourceFile, hasHeader)
+	if err != nil {
+		return err
+	}
+
+	// assign rows to either train or test as specified by the split
+	train, err := split.assign(header, lines, target)
+	if err != nil {
+		return errors.Wrap(err, "unable to split data")
+	}
 
+	return writeSplit(trainFile, testFile, header, lines, train)
+}
+
+// readSplitSource reads the whole csv source of a split, since the rows need
+// to be known to stratify or order them.
+func readSplitSource(sourceFile string, hasHeader bool) ([]string, [][]string, error) {
 	// open the file
 	file, err := os.Open(sourceFile)
 	if err != nil {
-		return errors.Wrap(err, "failed to open source file")
+		return nil, nil, errors.Wrap(err, "failed to open source file")
 	}
+	defer file.Close()
 	reader := csv.NewReader(file)
 
-	// write header to both outputs
+	var header []string
 	if hasHeader {
-		header, err := reader.Read()
+		header, err = reader.Read()
 		if err != nil {
-			return errors.Wrap(err, "unable to read header row")
+			return nil, nil, errors.Wrap(err, "unable to read header row")
+		}
+	}
+
+	lines := make([][]string, 0)
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read line from file")
+		}
+		lines = append(lines, line)
+	}
+
+	return header, lines, nil
+}
+
+// readDatasetRows reads the header and rows of the main data resource of a
+// dataset.
+func readDatasetRows(schemaFile string) ([]string, [][]string, error) {
+	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	mainDR := meta.GetMainDataResource()
+
+	return readSplitSource(path.Join(path.Dir(schemaFile), mainDR.ResPath), true)
+}
+
+// readResultValues pairs the predictions of a result file with the actual
+// target values of the test rows.
+func readResultValues(resultURI string, testHeader []string, testRows [][]string, target string) ([]string, []string, error) {
+	testIndex, err := getSplitColumnIndex(testHeader, model.D3MIndexFieldName)
+	if err != nil {
+		return nil, nil, err
+	}
+	testTarget, err := getSplitColumnIndex(testHeader, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	actuals := make(map[string]string)
+	for _, row := range testRows {
+		actuals[row[testIndex]] = row[testTarget]
+	}
+
+	file, err := os.Open(resultURI)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to open result file")
+	}
+	defer file.Close()
+	records, err := csv.NewReader(bufio.NewReader(file)).ReadAll()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to read result file")
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("result file is empty")
+	}
+	resultIndex, err := getSplitColumnIndex(records[0], model.D3MIndexFieldName)
+	if err != nil {
+		return nil, nil, err
+	}
+	resultTarget, err := getSplitColumnIndex(records[0], target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actual := make([]string, 0)
+	predicted := make([]string, 0)
+	for _, record := range records[1:] {
+		value, ok := actuals[record[resultIndex]]
+		if !ok {
+			continue
 		}
-		err = writerTrain.Write(header)
+		actual = append(actual, value)
+		predicted = append(predicted, record[resultTarget])
+	}
+	return actual, predicted, nil
+}
+
+// writeSplit writes the rows flagged for training to the train file and the
+// others to the test file, repeating the header in both.
+func writeSplit(trainFile string, testFile string, header []string, lines [][]string, train []bool) error {
+	// create the writers
+	outputTrain := &bytes.Buffer{}
+	writerTrain := csv.NewWriter(outputTrain)
+	outputTest := &bytes.Buffer{}
+	writerTest := csv.NewWriter(outputTest)
+
+	// write header to both outputs
+	if header != nil {
+		err := writerTrain.Write(header)
 		if err != nil {
 			return errors.Wrap(err, "unable to write header to train output")
 		}
@@ -138,21 +241,14 @@ func splitTrainTest(sourceFile string, trainFile string, testFile string, hasHea
 		}
 	}
 
-	// randomly assign rows to either train or test
-	for {
-		line, err := reader.Read()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return errors.Wrap(err, "failed to read line from file")
-		}
-		if rand.Float64() < trainTestSplitThreshold {
-			err = writerTrain.Write(line)
+	for i, line := range lines {
+		if train[i] {
+			err := writerTrain.Write(line)
 			if err != nil {
 				return errors.Wrap(err, "unable to write data to train output")
 			}
 		} else {
-			err = writerTest.Write(line)
+			err := writerTest.Write(line)
 			if err != nil {
 				return errors.Wrap(err, "unable to write data to test output")
 			}
@@ -161,7 +257,7 @@ func splitTrainTest(sourceFile string, trainFile string, testFile string, hasHea
 	writerTrain.Flush()
 	writerTest.Flush()
 
-	err = util.WriteFileWithDirs(trainFile, outputTrain.Bytes(), os.ModePerm)
+	err := util.WriteFileWithDirs(trainFile, outputTrain.Bytes(), os.ModePerm)
 	if err != nil {
 		return errors.Wrap(err, "unable to output train data")
 	}
@@ -175,12 +271,24 @@ func splitTrainTest(sourceFile string, trainFile string, testFile string, hasHea
 }
 
 // PersistOriginalData copies the original data and splits it into a train &
-// test subset to be used as needed.
-func PersistOriginalData(datasetName string, schemaFile string, sourceDataFolder string, tmpDataFolder string) (string, string, error) {
+// test subset to be used as needed. Each distinct split of a dataset is stored
+// in its own folder along with a record of how it was made. A nil split uses
+// the default random split.
+func PersistOriginalData(datasetName string, schemaFile string, sourceDataFolder string, tmpDataFolder string, split *SplitSpec, target string) (string, string, error) {
+	split, err := split.normalize()
+	if err != nil {
+		return "", "", err
+	}
+	splitHash, err := split.hash(target)
+	if err != nil {
+		return "", "", err
+	}
+
 	// The complete data is copied into separate train & test folders.
-	// The main data is then split randomly.
-	trainFolder := path.Join(tmpDataFolder, datasetName, trainFilenamePrefix)
-	testFolder := path.Join(tmpDataFolder, datasetName, testFilenamePrefix)
+	// The main data is then split as specified.
+	splitFolder := path.Join(tmpDataFolder, datasetName, fmt.Sprintf("s%s", strconv.FormatUint(splitHash, 10)))
+	trainFolder := path.Join(splitFolder, trainFilenamePrefix)
+	testFolder := path.Join(splitFolder, testFilenamePrefix)
 	trainSchemaFile := path.Join(trainFolder, schemaFile)
 	testSchemaFile := path.Join(testFolder, schemaFile)
 
@@ -206,7 +314,7 @@ func PersistOriginalData(datasetName string, schemaFile string, sourceDataFolder
 	}
 
 	// copy the data over
-	err := copy.Copy(sourceDataFolder, trainFolder)
+	err = copy.Copy(sourceDataFolder, trainFolder)
 	if err != nil {
 		return "", "", errors.Wrap(err, "unable to copy dataset folder to train")
 	}
@@ -230,11 +338,21 @@ func PersistOriginalData(datasetName string, schemaFile string, sourceDataFolder
 	dataPath := path.Join(sourceDataFolder, mainDR.ResPath)
 	trainDataFile := path.Join(trainFolder, mainDR.ResPath)
 	testDataFile := path.Join(testFolder, mainDR.ResPath)
-	err = splitTrainTest(dataPath, trainDataFile, testDataFile, true)
+	err = splitTrainTest(dataPath, trainDataFile, testDataFile, true, split, target)
 	if err != nil {
 		return "", "", err
 	}
 
+	// record the split next to the data it produced
+	splitsJSON, err := json.MarshalIndent(split.DataSplits(), "", "\t")
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to marshal data splits")
+	}
+	err = util.WriteFileWithDirs(path.Join(splitFolder, dataSplitsFilename), splitsJSON, os.ModePerm)
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to output data splits")
+	}
+
 	return trainSchemaFile, testSchemaFile, nil
 }
 
@@ -0,0 +1,231 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+
+	"github.com/uncharted-distil/distil/api/compute/ta2mock"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+type dispatchHarness struct {
+	server   *ta2mock.Server
+	client   *compute.Client
+	meta     *ta2mock.MetadataStorage
+	data     *ta2mock.DataStorage
+	solution *ta2mock.SolutionStorage
+	folder   string
+}
+
+func newDispatchHarness(t *testing.T, config *ta2mock.Config) *dispatchHarness {
+	folder, err := ioutil.TempDir("", "dispatch")
+	assert.NoError(t, err)
+
+	dataset, err := ta2mock.WriteDataset(folder, "dispatch_dataset", 20)
+	assert.NoError(t, err)
+	SetDatasetDir(path.Join(folder, "tmp"))
+
+	server := ta2mock.NewServer(config)
+	endpoint, err := server.Start()
+	assert.NoError(t, err)
+
+	client, err := compute.NewClient(endpoint, false, "distil-test", 10*time.Second, 100, true)
+	assert.NoError(t, err)
+
+	return &dispatchHarness{
+		server:   server,
+		client:   client,
+		meta:     ta2mock.NewMetadataStorage(dataset),
+		data:     ta2mock.NewDataStorage(),
+		solution: ta2mock.NewSolutionStorage(),
+		folder:   folder,
+	}
+}
+
+func (h *dispatchHarness) close() {
+	h.client.Close()
+	h.server.Stop()
+	os.RemoveAll(h.folder)
+}
+
+// dispatch runs a search to completion and returns its request id.
+func (h *dispatchHarness) dispatch(t *testing.T) string {
+	return h.dispatchRequest(t, fmt.Sprintf(`{
+		"dataset": "dispatch_dataset",
+		"target": "%s",
+		"task": "regression",
+		"subTask": "univariate",
+		"metrics": ["meanAbsoluteError"],
+		"filters": {"variables": ["%s", "%s"]}
+	}`, ta2mock.TargetName, ta2mock.FeatureName, ta2mock.TargetName))
+}
+
+func (h *dispatchHarness) dispatchRequest(t *testing.T, data string) string {
+	request, err := NewSolutionRequest([]byte(data))
+	assert.NoError(t, err)
+
+	err = request.PersistAndDispatch(h.client, h.solution, h.meta, h.data)
+	assert.NoError(t, err)
+
+	err = request.Listen(func(status SolutionStatus) {})
+	assert.NoError(t, err)
+
+	// the search is ended once the request is done
+	ended := h.server.EndedSearches()
+	assert.Len(t, ended, 1)
+	return ended[0]
+}
+
+func TestPersistAndDispatch(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 2})
+	defer h.close()
+
+	requestID := h.dispatch(t)
+
+	req, err := h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	assert.Equal(t, RequestCompletedStatus, req.Progress)
+	assert.Equal(t, ta2mock.TargetName, req.TargetFeature())
+
+	// every solution is scored, fitted and produced
+	assert.Len(t, req.Solutions, 2)
+	for _, sol := range req.Solutions {
+		assert.Equal(t, SolutionCompletedStatus, sol.Progress)
+		assert.Len(t, sol.Scores, 1)
+		assert.NotNil(t, sol.Result)
+	}
+	assert.Equal(t, 1.0, req.Solutions[0].Scores[0].Score)
+	assert.Equal(t, 0.5, req.Solutions[1].Scores[0].Score)
+	assert.Len(t, h.data.Results(), 2)
+
+	// the request no longer needs to be resumed
+	state, err := h.solution.FetchRequestState(requestID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", state.ResumeState)
+}
+
+func TestPersistAndDispatchSolutionError(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 2, FailedSolutions: 1})
+	defer h.close()
+
+	requestID := h.dispatch(t)
+
+	req, err := h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	assert.Equal(t, RequestCompletedStatus, req.Progress)
+	assert.Len(t, req.Solutions, 2)
+	assert.Equal(t, SolutionCompletedStatus, req.Solutions[0].Progress)
+	assert.Equal(t, SolutionErroredStatus, req.Solutions[1].Progress)
+	assert.Len(t, h.data.Results(), 1)
+}
+
+func TestPersistAndDispatchMultipleTargets(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 1})
+	defer h.close()
+
+	requestID := h.dispatchRequest(t, fmt.Sprintf(`{
+		"dataset": "dispatch_dataset",
+		"targets": ["%s", "%s"],
+		"task": "regression",
+		"subTask": "multivariate",
+		"metrics": ["meanAbsoluteError"],
+		"filters": {"variables": ["%s", "%s"]}
+	}`, ta2mock.TargetName, ta2mock.FeatureName, ta2mock.FeatureName, ta2mock.TargetName))
+
+	req, err := h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	assert.Equal(t, RequestCompletedStatus, req.Progress)
+	assert.Equal(t, ta2mock.TargetName, req.TargetFeature())
+	assert.Equal(t, []string{ta2mock.TargetName, ta2mock.FeatureName}, req.TargetFeatures())
+	assert.Len(t, req.Solutions, 1)
+	assert.Len(t, h.data.Results(), 1)
+}
+
+func TestPersistAndDispatchForecasting(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 1})
+	defer h.close()
+
+	// the feature increases with the row so it serves as the time
+	requestID := h.dispatchRequest(t, fmt.Sprintf(`{
+		"dataset": "dispatch_dataset",
+		"target": "%s",
+		"task": "%s",
+		"subTask": "univariate",
+		"metrics": ["meanAbsoluteError", "%s"],
+		"forecast": {"timeColumn": "%s", "horizon": 5},
+		"filters": {"variables": ["%s", "%s"]}
+	}`, ta2mock.TargetName, TaskTypeForecasting, api.MetricMeanAbsolutePercentageError, ta2mock.FeatureName, ta2mock.FeatureName, ta2mock.TargetName))
+
+	req, err := h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	assert.Len(t, req.Solutions, 1)
+
+	// the percentage error is scored from the predictions, which echo the
+	// target in the mock
+	scores := make(map[string]float64)
+	for _, score := range req.Solutions[0].Scores {
+		scores[score.Metric] = score.Score
+	}
+	assert.Len(t, scores, 2)
+	assert.Equal(t, 0.0, scores[api.MetricMeanAbsolutePercentageError])
+}
+
+func TestPersistAndDispatchCrossValidation(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 1})
+	defer h.close()
+
+	requestID := h.dispatchRequest(t, fmt.Sprintf(`{
+		"dataset": "dispatch_dataset",
+		"target": "%s",
+		"task": "regression",
+		"subTask": "univariate",
+		"metrics": ["meanAbsoluteError"],
+		"crossValidationFolds": 2,
+		"filters": {"variables": ["%s", "%s"]}
+	}`, ta2mock.TargetName, ta2mock.FeatureName, ta2mock.TargetName))
+
+	req, err := h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	assert.Len(t, req.Solutions, 1)
+
+	// each fold is fitted on its own train set before the final fit
+	fits := h.server.FitDatasets()
+	assert.Len(t, fits, 3)
+	assert.Contains(t, fits[0], "folds2/0/train")
+	assert.Contains(t, fits[1], "folds2/1/train")
+
+	// the predictions echo the target in the mock so every fold is perfect
+	scoreTypes := make(map[string]int)
+	for _, score := range req.Solutions[0].Scores {
+		scoreTypes[score.Type]++
+		if score.Type != api.ScoreTypeHoldOut {
+			assert.Equal(t, "MEAN_ABSOLUTE_ERROR", score.Metric)
+			assert.Equal(t, 0.0, score.Score)
+		}
+	}
+	assert.Equal(t, 2, scoreTypes[api.ScoreTypeFold])
+	assert.Equal(t, 1, scoreTypes[api.ScoreTypeFoldMean])
+	assert.Equal(t, 1, scoreTypes[api.ScoreTypeFoldStd])
+}
@@ -0,0 +1,225 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/util"
+)
+
+const (
+	exportFolder           = "exports"
+	exportSolutionFilename = "solution.json"
+	exportScoresFilename   = "scores.json"
+	exportPipelineFilename = "pipeline.json"
+	exportPredictionsFile  = "predictions.csv"
+	exportPredictionsDir   = "predictions"
+	exportedPipelinesDir   = "pipelines_ranked"
+)
+
+// ExportedSolution describes the solution written to an export bundle. The
+// pipeline path is where the TA2 system exported the pipeline description,
+// empty if it could not be found.
+type ExportedSolution struct {
+	SolutionID       string            `json:"solutionId"`
+	FittedSolutionID string            `json:"fittedSolutionId"`
+	RequestID        string            `json:"requestId"`
+	Dataset          string            `json:"dataset"`
+	Target           string            `json:"target"`
+	Targets          []string          `json:"targets"`
+	Features         []*api.Feature    `json:"features"`
+	Filters          *api.FilterParams `json:"filters"`
+	Split            *SplitSpec        `json:"split"`
+	PipelinePath     string            `json:"pipelinePath"`
+	ExportedTime     time.Time         `json:"exportedTime"`
+}
+
+// ExportSolution has the TA2 system export the pipeline of the fitted
+// solution.
+func ExportSolution(client *compute.Client, solutionStorage api.SolutionStorage, solutionID string) error {
+	sol, err := fetchFittedSolution(solutionStorage, solutionID)
+	if err != nil {
+		return err
+	}
+
+	err = client.ExportSolution(context.Background(), sol.Result.FittedSolutionID)
+	if err != nil {
+		return errors.Wrapf(err, "unable to export fitted solution %s", sol.Result.FittedSolutionID)
+	}
+
+	return nil
+}
+
+// WriteSolutionBundle writes a bundle describing an exported solution under
+// the output folder. The bundle holds the solution and request details, the
+// pipeline description exported by the TA2 system, the problem document, the
+// scores and the predictions, and its folder is returned.
+func WriteSolutionBundle(solutionStorage api.SolutionStorage, metaStorage api.MetadataStorage, solutionID string, outputDir string) (string, error) {
+	sol, err := fetchFittedSolution(solutionStorage, solutionID)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := solutionStorage.FetchRequestBySolutionID(solutionID)
+	if err != nil {
+		return "", err
+	}
+	if req == nil {
+		return "", errors.Errorf("export failed - no request found for solution %s", solutionID)
+	}
+
+	split, err := ParseSplitSpec(req.Split)
+	if err != nil {
+		return "", err
+	}
+
+	targetVars := make([]*model.Variable, 0)
+	for _, target := range req.TargetFeatures() {
+		targetVar, err := metaStorage.FetchVariable(req.Dataset, target)
+		if err != nil {
+			return "", err
+		}
+		targetVars = append(targetVars, targetVar)
+	}
+	if len(targetVars) == 0 {
+		return "", errors.Errorf("export failed - no target found for solution %s", solutionID)
+	}
+
+	bundleDir := path.Join(outputDir, exportFolder, solutionID)
+	err = os.RemoveAll(bundleDir)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to remove previous export")
+	}
+
+	// the pipeline description is written by the TA2 system
+	pipelinePath := findExportedPipeline(outputDir, solutionID, sol.Result.FittedSolutionID)
+	if pipelinePath != "" {
+		err = util.Copy(pipelinePath, path.Join(bundleDir, exportPipelineFilename))
+		if err != nil {
+			return "", errors.Wrap(err, "unable to copy pipeline description")
+		}
+	} else {
+		log.Warnf("no exported pipeline description found for solution %s", solutionID)
+	}
+
+	exported := &ExportedSolution{
+		SolutionID:       solutionID,
+		FittedSolutionID: sol.Result.FittedSolutionID,
+		RequestID:        req.RequestID,
+		Dataset:          req.Dataset,
+		Target:           req.TargetFeature(),
+		Targets:          req.TargetFeatures(),
+		Features:         req.Features,
+		Filters:          req.Filters,
+		Split:            split,
+		PipelinePath:     pipelinePath,
+		ExportedTime:     time.Now(),
+	}
+	err = writeExportJSON(path.Join(bundleDir, exportSolutionFilename), exported)
+	if err != nil {
+		return "", err
+	}
+
+	problem, _, err := CreateProblemSchema(bundleDir, req.Dataset, targetVars[0], req.Filters, split)
+	if err != nil {
+		return "", err
+	}
+	// the problem document lists every target of the request
+	for i, targetVar := range targetVars[1:] {
+		problem.Inputs.Data[0].Targets = append(problem.Inputs.Data[0].Targets, &ProblemPersistTarget{
+			TargetIndex: i + 1,
+			ResID:       defaultResourceID,
+			ColIndex:    -1,
+			ColName:     targetVar.DisplayName,
+		})
+	}
+	err = writeExportJSON(path.Join(bundleDir, D3MProblem), problem)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeExportJSON(path.Join(bundleDir, exportScoresFilename), sol.Scores)
+	if err != nil {
+		return "", err
+	}
+
+	// some TA2 systems write their predictions as a folder of files
+	predictionsPath := path.Join(bundleDir, exportPredictionsFile)
+	info, err := os.Stat(sol.Result.ResultURI)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read solution predictions")
+	}
+	if info.IsDir() {
+		predictionsPath = path.Join(bundleDir, exportPredictionsDir)
+	}
+	err = util.Copy(sol.Result.ResultURI, predictionsPath)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to write solution predictions")
+	}
+
+	log.Infof("exported solution %s to %s", solutionID, bundleDir)
+
+	return bundleDir, nil
+}
+
+func fetchFittedSolution(solutionStorage api.SolutionStorage, solutionID string) (*api.Solution, error) {
+	sol, err := solutionStorage.FetchSolution(solutionID)
+	if err != nil {
+		return nil, err
+	}
+	if sol == nil || sol.Result == nil || sol.Result.FittedSolutionID == "" {
+		return nil, errors.Errorf("export failed - no fitted solution found for solution %s", solutionID)
+	}
+	return sol, nil
+}
+
+// findExportedPipeline returns the path of the pipeline description exported
+// by the TA2 system, named after either the solution or the fitted solution,
+// or an empty string if there is none.
+func findExportedPipeline(outputDir string, solutionID string, fittedSolutionID string) string {
+	for _, id := range []string{solutionID, fittedSolutionID} {
+		filename := path.Join(outputDir, exportedPipelinesDir, id+".json")
+		if fileExists(filename) {
+			return filename
+		}
+	}
+	return ""
+}
+
+func writeExportJSON(filename string, data interface{}) error {
+	bytes, err := json.MarshalIndent(data, "", "\t")
+	if err != nil {
+		return errors.Wrapf(err, "unable to marshal %s", path.Base(filename))
+	}
+
+	err = util.WriteFileWithDirs(filename, bytes, os.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "unable to write %s", path.Base(filename))
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uncharted-distil/distil/api/compute/ta2mock"
+)
+
+func TestWriteSolutionBundle(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 1})
+	defer h.close()
+
+	requestID := h.dispatchRequest(t, fmt.Sprintf(`{
+		"dataset": "dispatch_dataset",
+		"target": "%s",
+		"task": "regression",
+		"subTask": "univariate",
+		"metrics": ["meanAbsoluteError"],
+		"split": {"trainRatio": 0.75, "seed": 7},
+		"filters": {"variables": ["%s", "%s"]}
+	}`, ta2mock.TargetName, ta2mock.FeatureName, ta2mock.TargetName))
+
+	req, err := h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	assert.Len(t, req.Solutions, 1)
+	solutionID := req.Solutions[0].SolutionID
+
+	// the pipeline description exported by the TA2 system
+	outputDir := path.Join(h.folder, "outputs")
+	pipelinePath := path.Join(outputDir, exportedPipelinesDir, solutionID+".json")
+	err = os.MkdirAll(path.Dir(pipelinePath), os.ModePerm)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(pipelinePath, []byte(`{"id": "pipeline"}`), 0644)
+	assert.NoError(t, err)
+
+	bundleDir, err := WriteSolutionBundle(h.solution, h.meta, solutionID, outputDir)
+	assert.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path.Join(bundleDir, exportPipelineFilename))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id": "pipeline"}`, string(data))
+	assert.True(t, fileExists(path.Join(bundleDir, exportPredictionsFile)))
+
+	data, err = ioutil.ReadFile(path.Join(bundleDir, exportSolutionFilename))
+	assert.NoError(t, err)
+	exported := &ExportedSolution{}
+	err = json.Unmarshal(data, exported)
+	assert.NoError(t, err)
+	assert.Equal(t, pipelinePath, exported.PipelinePath)
+	assert.Equal(t, int64(7), exported.Split.Seed)
+
+	// the problem describes the split the solution was trained on
+	problem, err := LoadProblemSchemaFromFile(path.Join(bundleDir, D3MProblem))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.25, problem.Inputs.DataSplits.TestSize, 1e-9)
+	assert.Equal(t, 7, problem.Inputs.DataSplits.RandomSeed)
+}
+
+func TestExportSolutionUnsupported(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 1})
+	defer h.close()
+
+	requestID := h.dispatchRequest(t, fmt.Sprintf(`{
+		"dataset": "dispatch_dataset",
+		"target": "%s",
+		"task": "regression",
+		"subTask": "univariate",
+		"metrics": ["meanAbsoluteError"],
+		"filters": {"variables": ["%s", "%s"]}
+	}`, ta2mock.TargetName, ta2mock.FeatureName, ta2mock.TargetName))
+
+	req, err := h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	assert.Len(t, req.Solutions, 1)
+
+	// the mock rejects the export rather than failing the connection
+	err = ExportSolution(h.client, h.solution, req.Solutions[0].SolutionID)
+	assert.Error(t, err)
+}
+
+func TestWriteSolutionBundlePredictionFolder(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 1})
+	defer h.close()
+
+	requestID := h.dispatch(t)
+	req, err := h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	sol := req.Solutions[0]
+
+	// some TA2 systems produce a folder of predictions
+	resultDir := path.Join(h.folder, "produced")
+	err = os.MkdirAll(resultDir, os.ModePerm)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(path.Join(resultDir, "predictions.csv"), []byte("d3mIndex,target\n0,1\n"), 0644)
+	assert.NoError(t, err)
+	err = h.solution.PersistSolutionResult(sol.SolutionID, sol.Result.FittedSolutionID, sol.Result.ResultUUID, resultDir, sol.Result.Progress, time.Now())
+	assert.NoError(t, err)
+
+	// no pipeline description is required
+	bundleDir, err := WriteSolutionBundle(h.solution, h.meta, sol.SolutionID, path.Join(h.folder, "outputs"))
+	assert.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path.Join(bundleDir, exportPredictionsDir, "predictions.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "d3mIndex,target\n0,1\n", string(data))
+	assert.False(t, fileExists(path.Join(bundleDir, exportPipelineFilename)))
+}
@@ -0,0 +1,254 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-ingest/metadata"
+	"github.com/unchartedsoftware/plog"
+)
+
+const (
+	foldsFolderPrefix = "folds"
+)
+
+// DatasetFold is a single cross validation fold of a persisted train split,
+// given as the schema files of its train and test datasets.
+type DatasetFold struct {
+	Index           int
+	TrainSchemaFile string
+	TestSchemaFile  string
+}
+
+// FoldScore is the aggregated cross validation score of a metric.
+type FoldScore struct {
+	Metric string
+	Scores []float64
+	Mean   float64
+	Std    float64
+}
+
+// PersistFolds splits the data of a persisted train split into k cross
+// validation folds. Each fold is tested on its own rows and trained on the
+// rows of the other folds. Folds are reused if they were already generated
+// for the split.
+func PersistFolds(trainSchemaFile string, k int, split *SplitSpec, target string) ([]*DatasetFold, error) {
+	split, err := split.normalize()
+	if err != nil {
+		return nil, err
+	}
+	if k < 2 {
+		return nil, errors.Errorf("cross validation needs at least 2 folds but %d were requested", k)
+	}
+
+	trainFolder := path.Dir(trainSchemaFile)
+	schemaFile := path.Base(trainSchemaFile)
+	foldsFolder := path.Join(path.Dir(trainFolder), fmt.Sprintf("%s%d", foldsFolderPrefix, k))
+
+	folds := make([]*DatasetFold, k)
+	existing := true
+	for i := range folds {
+		foldFolder := path.Join(foldsFolder, strconv.Itoa(i))
+		folds[i] = &DatasetFold{
+			Index:           i,
+			TrainSchemaFile: path.Join(foldFolder, trainFilenamePrefix, schemaFile),
+			TestSchemaFile:  path.Join(foldFolder, testFilenamePrefix, schemaFile),
+		}
+		existing = existing && fileExists(folds[i].TrainSchemaFile) && fileExists(folds[i].TestSchemaFile)
+	}
+	if existing {
+		log.Infof("folds for '%s' already generated", trainFolder)
+		return folds, nil
+	}
+
+	if dirExists(foldsFolder) {
+		err := os.RemoveAll(foldsFolder)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to remove folds from previous attempt")
+		}
+	}
+
+	// read the dataset document
+	meta, err := metadata.LoadMetadataFromOriginalSchema(trainSchemaFile)
+	if err != nil {
+		return nil, err
+	}
+	mainDR := meta.GetMainDataResource()
+
+	header, lines, err := readSplitSource(path.Join(trainFolder, mainDR.ResPath), true)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := split.assignFolds(header, lines, target, k)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to assign folds")
+	}
+
+	for _, fold := range folds {
+		foldTrainFolder := path.Dir(fold.TrainSchemaFile)
+		foldTestFolder := path.Dir(fold.TestSchemaFile)
+
+		err = copy.Copy(trainFolder, foldTrainFolder)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to copy dataset folder to fold train")
+		}
+		err = copy.Copy(trainFolder, foldTestFolder)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to copy dataset folder to fold test")
+		}
+
+		train := make([]bool, len(lines))
+		for i, assignment := range assignments {
+			train[i] = assignment != fold.Index
+		}
+
+		err = writeSplit(path.Join(foldTrainFolder, mainDR.ResPath), path.Join(foldTestFolder, mainDR.ResPath), header, lines, train)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return folds, nil
+}
+
+// assignFolds returns the fold of each row. Rows are dealt out to the folds
+// at random using the seed of the split, keeping the target value proportions
+// of each fold when stratified. Chronological splits use contiguous blocks
+// of time instead.
+func (s *SplitSpec) assignFolds(header []string, rows [][]string, target string, k int) ([]int, error) {
+	if len(rows) < k {
+		return nil, errors.Errorf("unable to split %d rows into %d folds", len(rows), k)
+	}
+
+	assignments := make([]int, len(rows))
+
+	if s.TimeColumn != "" {
+		colIndex, err := getSplitColumnIndex(header, s.TimeColumn)
+		if err != nil {
+			return nil, err
+		}
+		times := make([]float64, len(rows))
+		for i, row := range rows {
+			t, err := parseSplitTime(row[colIndex])
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to parse time of row %d", i)
+			}
+			times[i] = t
+		}
+
+		order := make([]int, len(rows))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return times[order[i]] < times[order[j]]
+		})
+		for position, index := range order {
+			assignments[index] = position * k / len(rows)
+		}
+		return assignments, nil
+	}
+
+	rng := rand.New(rand.NewSource(s.Seed))
+
+	groups := [][]int{}
+	if s.Stratify {
+		colIndex, err := getSplitColumnIndex(header, target)
+		if err != nil {
+			return nil, err
+		}
+
+		strata := make(map[string]int)
+		for i, row := range rows {
+			group, ok := strata[row[colIndex]]
+			if !ok {
+				group = len(groups)
+				strata[row[colIndex]] = group
+				groups = append(groups, []int{})
+			}
+			groups[group] = append(groups[group], i)
+		}
+	} else {
+		all := make([]int, len(rows))
+		for i := range all {
+			all[i] = i
+		}
+		groups = append(groups, all)
+	}
+
+	// continue dealing where the previous group stopped to balance fold sizes
+	next := 0
+	for _, group := range groups {
+		rng.Shuffle(len(group), func(i, j int) {
+			group[i], group[j] = group[j], group[i]
+		})
+		for _, index := range group {
+			assignments[index] = next
+			next = (next + 1) % k
+		}
+	}
+
+	return assignments, nil
+}
+
+// aggregateFoldScores computes the mean and sample standard deviation of the
+// fold scores of each metric, sorted by metric.
+func aggregateFoldScores(scores map[string][]float64) []*FoldScore {
+	aggregated := make([]*FoldScore, 0)
+	for metric, values := range scores {
+		if len(values) == 0 {
+			continue
+		}
+
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		mean := sum / float64(len(values))
+
+		std := 0.0
+		if len(values) > 1 {
+			squares := 0.0
+			for _, v := range values {
+				squares += (v - mean) * (v - mean)
+			}
+			std = math.Sqrt(squares / float64(len(values)-1))
+		}
+
+		aggregated = append(aggregated, &FoldScore{
+			Metric: metric,
+			Scores: values,
+			Mean:   mean,
+			Std:    std,
+		})
+	}
+
+	sort.Slice(aggregated, func(i, j int) bool {
+		return aggregated[i].Metric < aggregated[j].Metric
+	})
+
+	return aggregated
+}
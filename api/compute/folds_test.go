@@ -0,0 +1,79 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignFolds(t *testing.T) {
+	header := []string{"d3mIndex", "label", "date"}
+	rows := splitTestRows(30)
+
+	split, err := (&SplitSpec{Seed: 3, Stratify: true}).normalize()
+	assert.NoError(t, err)
+
+	assignments, err := split.assignFolds(header, rows, "label", 3)
+	assert.NoError(t, err)
+
+	sizes := make([]int, 3)
+	rare := make([]int, 3)
+	for i, fold := range assignments {
+		sizes[fold]++
+		if rows[i][1] == "rare" {
+			rare[fold]++
+		}
+	}
+	assert.Equal(t, []int{10, 10, 10}, sizes)
+	assert.Equal(t, []int{1, 1, 1}, rare)
+
+	again, err := split.assignFolds(header, rows, "label", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, assignments, again)
+
+	_, err = split.assignFolds(header, rows[:2], "label", 3)
+	assert.Error(t, err)
+}
+
+func TestAssignFoldsChronological(t *testing.T) {
+	header := []string{"d3mIndex", "label", "date"}
+	rows := splitTestRows(6)
+
+	split, err := (&SplitSpec{TimeColumn: "date"}).normalize()
+	assert.NoError(t, err)
+
+	// dates decrease with the row index so the last rows are the earliest
+	assignments, err := split.assignFolds(header, rows, "label", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 2, 1, 1, 0, 0}, assignments)
+}
+
+func TestAggregateFoldScores(t *testing.T) {
+	aggregated := aggregateFoldScores(map[string][]float64{
+		"F1_MACRO": {0.5, 0.7, 0.9},
+		"ACCURACY": {0.8},
+	})
+
+	assert.Len(t, aggregated, 2)
+	assert.Equal(t, "ACCURACY", aggregated[0].Metric)
+	assert.InDelta(t, 0.8, aggregated[0].Mean, 1e-9)
+	assert.InDelta(t, 0, aggregated[0].Std, 1e-9)
+	assert.Equal(t, "F1_MACRO", aggregated[1].Metric)
+	assert.InDelta(t, 0.7, aggregated[1].Mean, 1e-9)
+	assert.InDelta(t, 0.2, aggregated[1].Std, 1e-9)
+}
@@ -0,0 +1,156 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+const (
+	// TaskTypeForecasting is the task of predicting the future values of
+	// time series.
+	TaskTypeForecasting = "timeSeriesForecasting"
+)
+
+// ForecastSpec describes the time series to forecast. The rows of the last
+// horizon time steps are held out for testing, the time ratio of the split
+// being used instead when no horizon is set. The series column identifies
+// the series of a row when the dataset holds several of them.
+type ForecastSpec struct {
+	TimeColumn   string `json:"timeColumn"`
+	SeriesColumn string `json:"seriesColumn"`
+	Horizon      int    `json:"horizon"`
+}
+
+// DefaultForecastingMetrics returns the metrics of forecasting tasks.
+func DefaultForecastingMetrics() []string {
+	return []string{"meanAbsoluteError", "rootMeanSquaredError", api.MetricMeanAbsolutePercentageError}
+}
+
+// split returns the chronological split holding out the forecast horizon,
+// keeping the train ratio and seed of the requested split.
+func (f *ForecastSpec) split(requested *SplitSpec) (*SplitSpec, error) {
+	if f == nil || f.TimeColumn == "" {
+		return nil, errors.New("forecasting requires a time column")
+	}
+	if f.Horizon < 0 {
+		return nil, errors.Errorf("forecast horizon %d must not be negative", f.Horizon)
+	}
+
+	split := NewDefaultSplitSpec()
+	if requested != nil {
+		split.TrainRatio = requested.TrainRatio
+		split.Seed = requested.Seed
+	}
+	split.TimeColumn = f.TimeColumn
+	split.SeriesColumn = f.SeriesColumn
+	split.Horizon = f.Horizon
+
+	return split.normalize()
+}
+
+// ta2Metrics returns the metrics the TA2 system scores.
+func ta2Metrics(metrics []string) []string {
+	supported := make([]string, 0)
+	for _, metric := range metrics {
+		if metric != api.MetricMeanAbsolutePercentageError {
+			supported = append(supported, metric)
+		}
+	}
+	return supported
+}
+
+// convertMetricToTA2 returns the TA2 name of a metric, under which its scores
+// are stored.
+func convertMetricToTA2(metric string) string {
+	if metric == api.MetricMeanAbsolutePercentageError {
+		return metric
+	}
+	return compute.ConvertMetricsFromTA3ToTA2([]string{metric})[0].GetMetric().String()
+}
+
+// meanAbsolutePercentageError scores the predictions, skipping the rows with
+// an actual value of zero.
+func meanAbsolutePercentageError(actual []float64, predicted []float64) (float64, error) {
+	sum := 0.0
+	count := 0
+	for i := range actual {
+		if actual[i] == 0 {
+			continue
+		}
+		sum += math.Abs((actual[i] - predicted[i]) / actual[i])
+		count++
+	}
+	if count == 0 {
+		return 0, errors.New("no non zero actual values to score")
+	}
+	return sum / float64(count), nil
+}
+
+// persistLocalScores scores the predictions of a solution for the requested
+// metrics that the TA2 system does not support.
+func (s *SolutionRequest) persistLocalScores(solutionStorage api.SolutionStorage, solutionID string, resultURI string, testHeader []string, testRows [][]string) {
+	local := false
+	for _, metric := range s.Metrics {
+		if metric == api.MetricMeanAbsolutePercentageError {
+			local = true
+		}
+	}
+	if !local {
+		return
+	}
+
+	actual, predicted, err := readResultPredictions(resultURI, testHeader, testRows, s.TargetFeature)
+	if err != nil {
+		log.Warnf("unable to read predictions of solution %s: %v", solutionID, err)
+		return
+	}
+
+	score, err := meanAbsolutePercentageError(actual, predicted)
+	if err != nil {
+		log.Warnf("unable to score solution %s: %v", solutionID, err)
+		return
+	}
+	err = solutionStorage.PersistSolutionScore(solutionID, api.MetricMeanAbsolutePercentageError, score)
+	if err != nil {
+		log.Warnf("unable to persist score of solution %s: %v", solutionID, err)
+	}
+}
+
+// readResultPredictions pairs the numeric predictions of a result file with
+// the actual target values of the test rows.
+func readResultPredictions(resultURI string, testHeader []string, testRows [][]string, target string) ([]float64, []float64, error) {
+	actualValues, predictedValues, err := readResultValues(resultURI, testHeader, testRows, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actual, err := parseTargetValues(actualValues)
+	if err != nil {
+		return nil, nil, err
+	}
+	predicted, err := parseTargetValues(predictedValues)
+	if err != nil {
+		return nil, nil, err
+	}
+	return actual, predicted, nil
+}
@@ -0,0 +1,157 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/pipeline"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// ProducePredictions runs the fitted version of a solution against an
+// ingested dataset and stores the predictions as a new result set of that
+// dataset. The dataset needs every feature the solution was trained on, with
+// the same types. The target column is not required.
+func ProducePredictions(client *compute.Client, solutionStorage api.SolutionStorage, metaStorage api.MetadataStorage,
+	dataStorage api.DataStorage, solutionID string, dataset string) (*api.Prediction, error) {
+	sol, err := solutionStorage.FetchSolution(solutionID)
+	if err != nil {
+		return nil, err
+	}
+	if sol == nil || sol.Result == nil || sol.Result.FittedSolutionID == "" {
+		return nil, errors.Errorf("no fitted solution found for solution %s", solutionID)
+	}
+	fittedSolutionID := sol.Result.FittedSolutionID
+
+	req, err := solutionStorage.FetchRequestBySolutionID(solutionID)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, errors.Errorf("no request found for solution %s", solutionID)
+	}
+
+	// make sure the dataset can be fed to the fitted solution
+	trainVariables, err := metaStorage.FetchVariables(req.Dataset, true, true)
+	if err != nil {
+		return nil, err
+	}
+	predictVariables, err := metaStorage.FetchVariables(dataset, true, true)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPredictionSchema(trainVariables, predictVariables, req.Features)
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := metaStorage.FetchDataset(dataset, false, false)
+	if err != nil {
+		return nil, err
+	}
+	schemaURI, err := datasetURI(path.Join(env.ResolvePath(ds.Source, ds.Folder), compute.D3MDataSchema))
+	if err != nil {
+		return nil, err
+	}
+
+	// generate predictions
+	responses, err := client.GeneratePredictions(context.Background(), createProduceSolutionRequest(schemaURI, fittedSolutionID))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, response := range responses {
+		if response.Progress.State != pipeline.ProgressState_COMPLETED {
+			continue
+		}
+
+		resultURI, err := getProducedResultURI(response)
+		if err != nil {
+			return nil, err
+		}
+		resultID := getResultID(resultURI)
+
+		err = dataStorage.PersistResult(dataset, model.NormalizeDatasetID(dataset), resultURI, req.TargetFeatures())
+		if err != nil {
+			return nil, err
+		}
+
+		prediction := &api.Prediction{
+			ResultUUID:       resultID,
+			SolutionID:       solutionID,
+			FittedSolutionID: fittedSolutionID,
+			Dataset:          dataset,
+			ResultURI:        resultURI,
+			Progress:         SolutionCompletedStatus,
+			CreatedTime:      time.Now(),
+		}
+		err = solutionStorage.PersistPrediction(prediction.ResultUUID, prediction.SolutionID, prediction.FittedSolutionID,
+			prediction.Dataset, prediction.ResultURI, prediction.Progress, prediction.CreatedTime)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("produced predictions of solution %s on dataset %s", solutionID, dataset)
+
+		return prediction, nil
+	}
+
+	return nil, errors.Errorf("no predictions produced by solution %s for dataset %s", solutionID, dataset)
+}
+
+// checkPredictionSchema verifies that the prediction variables include every
+// training feature of the request with matching types.
+func checkPredictionSchema(trainVariables []*model.Variable, predictVariables []*model.Variable, features []*api.Feature) error {
+	trainTypes := make(map[string]string)
+	for _, v := range trainVariables {
+		trainTypes[v.Name] = v.Type
+	}
+	predictTypes := make(map[string]string)
+	for _, v := range predictVariables {
+		predictTypes[v.Name] = v.Type
+	}
+
+	problems := make([]string, 0)
+	for _, f := range features {
+		if f.FeatureType == model.FeatureTypeTarget {
+			continue
+		}
+		predictType, ok := predictTypes[f.FeatureName]
+		if !ok {
+			problems = append(problems, "missing `"+f.FeatureName+"`")
+			continue
+		}
+		trainType, ok := trainTypes[f.FeatureName]
+		if ok && trainType != predictType {
+			problems = append(problems, "`"+f.FeatureName+"` is "+predictType+" instead of "+trainType)
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("dataset does not match the solution features: %s", strings.Join(problems, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func TestCheckPredictionSchema(t *testing.T) {
+	train := []*model.Variable{
+		{Name: "age", Type: model.IntegerType},
+		{Name: "city", Type: model.CategoricalType},
+		{Name: "label", Type: model.CategoricalType},
+	}
+	features := []*api.Feature{
+		{FeatureName: "age", FeatureType: model.FeatureTypeTrain},
+		{FeatureName: "city", FeatureType: model.FeatureTypeTrain},
+		{FeatureName: "label", FeatureType: model.FeatureTypeTarget},
+	}
+
+	err := checkPredictionSchema(train, train, features)
+	assert.NoError(t, err)
+
+	// the target is what gets predicted
+	err = checkPredictionSchema(train, train[:2], features)
+	assert.NoError(t, err)
+
+	err = checkPredictionSchema(train, []*model.Variable{
+		{Name: "age", Type: model.FloatType},
+		{Name: "label", Type: model.CategoricalType},
+	}, features)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "missing `city`")
+	assert.Contains(t, err.Error(), "`age` is "+model.FloatType)
+}
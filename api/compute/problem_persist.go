@@ -76,12 +76,15 @@ type ProblemPersistInput struct {
 
 // ProblemPersistDataSplits contains the information about the data splits.
 type ProblemPersistDataSplits struct {
-	Method     string  `json:"method"`
-	TestSize   float64 `json:"testSize"`
-	Stratified bool    `json:"stratified"`
-	NumRepeats int     `json:"numRepeats"`
-	RandomSeed int     `json:"randomSeed"`
-	SplitsFile string  `json:"splitsFile"`
+	Method       string  `json:"method"`
+	TestSize     float64 `json:"testSize"`
+	Stratified   bool    `json:"stratified"`
+	NumRepeats   int     `json:"numRepeats"`
+	RandomSeed   int     `json:"randomSeed"`
+	SplitsFile   string  `json:"splitsFile"`
+	TimeColumn   string  `json:"timeColumn,omitempty"`
+	SeriesColumn string  `json:"seriesColumn,omitempty"`
+	Horizon      int     `json:"horizon,omitempty"`
 }
 
 // ProblemPersistData ties targets to a dataset.
@@ -127,6 +130,14 @@ func DefaultMetrics(targetType string) []string {
 	return []string{defaultNumericalMetric}
 }
 
+// DefaultTaskMetrics returns the default metrics of a task.
+func DefaultTaskMetrics(task string, targetType string) []string {
+	if task == TaskTypeForecasting {
+		return DefaultForecastingMetrics()
+	}
+	return DefaultMetrics(targetType)
+}
+
 // DefaultTaskType returns a default task.
 func DefaultTaskType(targetType string) string {
 	if model.IsCategorical(targetType) {
@@ -144,10 +155,15 @@ func DefaultTaskSubType(targetType string) string {
 }
 
 // CreateProblemSchema captures the problem information in the required D3M
-// problem format.
-func CreateProblemSchema(datasetDir string, dataset string, targetVar *model.Variable, filters *api.FilterParams) (*ProblemPersist, string, error) {
-	// parse the dataset and its filter state and generate a hashcode from both
-	hash, err := getFilteredDatasetHash(dataset, targetVar.Name, filters, true)
+// problem format. A nil split records the default train / test split.
+func CreateProblemSchema(datasetDir string, dataset string, targetVar *model.Variable, filters *api.FilterParams, split *SplitSpec) (*ProblemPersist, string, error) {
+	split, err := split.normalize()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// parse the dataset, its filter state and the split and generate a hashcode from them
+	hash, err := getFilteredDatasetHash(dataset, targetVar.Name, filters, split, true)
 	if err != nil {
 		return nil, "", errors.Wrap(err, "unable to build dataset filter hash")
 	}
@@ -185,6 +201,7 @@ func CreateProblemSchema(datasetDir string, dataset string, targetVar *model.Var
 	pInput := &ProblemPersistInput{
 		Data:               []*ProblemPersistData{pData},
 		PerformanceMetrics: []*ProblemPersistPerformanceMetric{pMetric},
+		DataSplits:         split.DataSplits(),
 	}
 
 	problemID := strings.Replace(dataset, "_dataset", "", -1)
@@ -0,0 +1,272 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+var (
+	resumedRequests   = make(map[string]*ResumedRequest)
+	resumedRequestsMu = &sync.Mutex{}
+)
+
+// requestResumeState is what is persisted to dispatch a request again after
+// a restart.
+type requestResumeState struct {
+	Request         json.RawMessage `json:"request"`
+	DatasetID       string          `json:"datasetId"`
+	DatasetURITrain string          `json:"datasetUriTrain"`
+	DatasetURITest  string          `json:"datasetUriTest"`
+	Folds           []*foldURI      `json:"folds"`
+}
+
+// ResumedRequest relays the statuses of a request resumed after a restart
+// to the clients subscribing to it.
+type ResumedRequest struct {
+	RequestID string
+	mu        *sync.Mutex
+	statuses  []SolutionStatus
+	listeners []SolutionStatusListener
+	finished  chan struct{}
+	err       error
+}
+
+func newResumedRequest(requestID string) *ResumedRequest {
+	return &ResumedRequest{
+		RequestID: requestID,
+		mu:        &sync.Mutex{},
+		finished:  make(chan struct{}),
+	}
+}
+
+// publish records the status and relays it to the listeners, which are
+// called without holding the lock so they can be slow or subscribe again.
+func (r *ResumedRequest) publish(status SolutionStatus) {
+	r.mu.Lock()
+	r.statuses = append(r.statuses, status)
+	listeners := make([]SolutionStatusListener, len(r.listeners))
+	copy(listeners, r.listeners)
+	r.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(status)
+	}
+}
+
+func (r *ResumedRequest) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+	close(r.finished)
+}
+
+// Subscribe replays the statuses relayed so far to the listener and keeps it
+// updated until the request finishes.
+func (r *ResumedRequest) Subscribe(listener SolutionStatusListener) error {
+	r.mu.Lock()
+	statuses := make([]SolutionStatus, len(r.statuses))
+	copy(statuses, r.statuses)
+	r.listeners = append(r.listeners, listener)
+	r.mu.Unlock()
+
+	for _, status := range statuses {
+		listener(status)
+	}
+
+	<-r.finished
+	return r.err
+}
+
+// GetResumedRequest returns the resumed request with the given id, or nil if
+// the request is not being resumed.
+func GetResumedRequest(requestID string) *ResumedRequest {
+	resumedRequestsMu.Lock()
+	defer resumedRequestsMu.Unlock()
+	return resumedRequests[requestID]
+}
+
+func registerResumedRequest(r *ResumedRequest) {
+	resumedRequestsMu.Lock()
+	defer resumedRequestsMu.Unlock()
+	resumedRequests[r.RequestID] = r
+}
+
+func unregisterResumedRequest(requestID string) {
+	resumedRequestsMu.Lock()
+	defer resumedRequestsMu.Unlock()
+	delete(resumedRequests, requestID)
+}
+
+// SubscribeSolutionsRequest represents a request to follow the solutions of a
+// previously created request.
+type SubscribeSolutionsRequest struct {
+	RequestID string `json:"requestId"`
+}
+
+// NewSubscribeSolutionsRequest instantiates a new SubscribeSolutionsRequest.
+func NewSubscribeSolutionsRequest(data []byte) (*SubscribeSolutionsRequest, error) {
+	req := &SubscribeSolutionsRequest{}
+	err := json.Unmarshal(data, &req)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *SolutionRequest) persistResumeState(solutionStorage api.SolutionStorage, requestID string, datasetID string, datasetURITrain string, datasetURITest string) error {
+	request, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal request")
+	}
+
+	state, err := json.Marshal(&requestResumeState{
+		Request:         request,
+		DatasetID:       datasetID,
+		DatasetURITrain: datasetURITrain,
+		DatasetURITest:  datasetURITest,
+		Folds:           s.foldURIs,
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal resume state")
+	}
+
+	return solutionStorage.PersistRequestState(requestID, string(state), "")
+}
+
+// ResumeRequests reconciles the requests left unfinished by a previous run of
+// the server. Each request is attached again to its TA2 search so that new
+// solutions keep being dispatched, while solutions interrupted mid dispatch
+// are marked as errored. Requests that cannot be resumed are marked as
+// errored along with the reason.
+func ResumeRequests(client *compute.Client, solutionStorage api.SolutionStorage, dataStorage api.DataStorage) error {
+	requests, err := solutionStorage.FetchResumableRequests([]string{RequestPendingStatus, RequestRunningStatus})
+	if err != nil {
+		return err
+	}
+
+	for _, req := range requests {
+		err = resumeRequest(client, solutionStorage, dataStorage, req)
+		if err != nil {
+			log.Warnf("unable to resume request %s: %v", req.RequestID, err)
+			err = abandonRequest(solutionStorage, req, err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		log.Infof("resumed request %s", req.RequestID)
+	}
+
+	return nil
+}
+
+func resumeRequest(client *compute.Client, solutionStorage api.SolutionStorage, dataStorage api.DataStorage, req *api.Request) error {
+	state, err := solutionStorage.FetchRequestState(req.RequestID)
+	if err != nil {
+		return err
+	}
+	if state == nil || state.ResumeState == "" {
+		return errors.New("request was interrupted before it was dispatched")
+	}
+
+	resume := &requestResumeState{}
+	err = json.Unmarshal([]byte(state.ResumeState), resume)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse resume state")
+	}
+
+	s, err := NewSolutionRequest(resume.Request)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse request")
+	}
+	s.foldURIs = resume.Folds
+
+	// solutions already persisted are not dispatched again
+	known, err := solutionStorage.FetchSolutionIDsByProgress(req.RequestID,
+		[]string{SolutionPendingStatus, SolutionRunningStatus, SolutionErroredStatus, SolutionCompletedStatus})
+	if err != nil {
+		return err
+	}
+	s.skipSolutions = make(map[string]bool)
+	for _, solutionID := range known {
+		s.skipSolutions[solutionID] = true
+	}
+
+	err = abandonSolutions(solutionStorage, req.RequestID)
+	if err != nil {
+		return err
+	}
+
+	relay := newResumedRequest(req.RequestID)
+	registerResumedRequest(relay)
+
+	// the search is already running so it is admitted regardless of the
+	// limits, its user being unknown after a restart
+	ticket := GetSearchScheduler().reserve(s, "")
+
+	go s.dispatchRequest(client, solutionStorage, dataStorage, req.RequestID, resume.DatasetID, resume.DatasetURITrain, resume.DatasetURITest)
+	go func() {
+		defer ticket.Release()
+		err := s.Listen(relay.publish)
+		if err != nil {
+			log.Warnf("resumed request %s failed: %v", req.RequestID, err)
+		}
+		relay.finish(err)
+		unregisterResumedRequest(req.RequestID)
+	}()
+
+	return nil
+}
+
+func abandonRequest(solutionStorage api.SolutionStorage, req *api.Request, reason error) error {
+	err := abandonSolutions(solutionStorage, req.RequestID)
+	if err != nil {
+		return err
+	}
+
+	err = solutionStorage.PersistRequest(req.RequestID, req.Dataset, RequestErroredStatus, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return solutionStorage.PersistRequestState(req.RequestID, "", reason.Error())
+}
+
+func abandonSolutions(solutionStorage api.SolutionStorage, requestID string) error {
+	unfinished, err := solutionStorage.FetchSolutionIDsByProgress(requestID, []string{SolutionPendingStatus, SolutionRunningStatus})
+	if err != nil {
+		return err
+	}
+
+	for _, solutionID := range unfinished {
+		log.Infof("solution %s of request %s was interrupted by a restart", solutionID, requestID)
+		err = solutionStorage.PersistSolution(requestID, solutionID, SolutionErroredStatus, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
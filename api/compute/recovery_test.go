@@ -0,0 +1,241 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uncharted-distil/distil/api/compute/ta2mock"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// resumeStateRecorder keeps the last resume state of the requests, which
+// is otherwise cleared once a request finishes.
+type resumeStateRecorder struct {
+	*ta2mock.SolutionStorage
+	resumeState string
+}
+
+func (r *resumeStateRecorder) PersistRequestState(requestID string, resumeState string, errorReason string) error {
+	if resumeState != "" {
+		r.resumeState = resumeState
+	}
+	return r.SolutionStorage.PersistRequestState(requestID, resumeState, errorReason)
+}
+
+func waitUntil(t *testing.T, check func() bool) {
+	timeout := time.After(10 * time.Second)
+	for !check() {
+		select {
+		case <-timeout:
+			t.Fatal("timed out waiting")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// dispatchRecorded runs a search to completion, returning its request id
+// along with the resume state it stored while running.
+func (h *dispatchHarness) dispatchRecorded(t *testing.T) (string, *requestResumeState) {
+	recorder := &resumeStateRecorder{SolutionStorage: h.solution}
+	request, err := NewSolutionRequest([]byte(fmt.Sprintf(`{
+		"dataset": "dispatch_dataset",
+		"target": "%s",
+		"task": "regression",
+		"subTask": "univariate",
+		"metrics": ["meanAbsoluteError"],
+		"filters": {"variables": ["%s", "%s"]}
+	}`, ta2mock.TargetName, ta2mock.FeatureName, ta2mock.TargetName)))
+	assert.NoError(t, err)
+
+	err = request.PersistAndDispatch(h.client, recorder, h.meta, h.data)
+	assert.NoError(t, err)
+	err = request.Listen(func(status SolutionStatus) {})
+	assert.NoError(t, err)
+	requestID := h.server.EndedSearches()[0]
+
+	// the baselines are scored in the background, the mean and linear ones
+	// for the single metric
+	waitUntil(t, func() bool {
+		baselines, err := h.solution.FetchBaselineScores(requestID)
+		assert.NoError(t, err)
+		return len(baselines) == 2
+	})
+
+	resume := &requestResumeState{}
+	err = json.Unmarshal([]byte(recorder.resumeState), resume)
+	assert.NoError(t, err)
+	return requestID, resume
+}
+
+func TestResumedRequestSubscribe(t *testing.T) {
+	relay := newResumedRequest("request")
+	registerResumedRequest(relay)
+	assert.Equal(t, relay, GetResumedRequest("request"))
+
+	relay.publish(SolutionStatus{RequestID: "request", Progress: RequestRunningStatus})
+
+	received := make(chan SolutionStatus, 2)
+	done := make(chan error)
+	go func() {
+		done <- relay.Subscribe(func(status SolutionStatus) {
+			received <- status
+		})
+	}()
+
+	// statuses relayed before subscribing are replayed
+	assert.Equal(t, RequestRunningStatus, (<-received).Progress)
+
+	relay.publish(SolutionStatus{RequestID: "request", SolutionID: "solution", Progress: SolutionPendingStatus})
+	assert.Equal(t, SolutionPendingStatus, (<-received).Progress)
+
+	relay.finish(errors.New("search ended"))
+	assert.EqualError(t, <-done, "search ended")
+
+	unregisterResumedRequest("request")
+	assert.Nil(t, GetResumedRequest("request"))
+}
+
+func TestResumedRequestSlowListener(t *testing.T) {
+	relay := newResumedRequest("request")
+	waitFor := func(check func() bool) {
+		for {
+			relay.mu.Lock()
+			ok := check()
+			relay.mu.Unlock()
+			if ok {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	block := make(chan struct{})
+	go relay.Subscribe(func(status SolutionStatus) {
+		<-block
+	})
+	waitFor(func() bool { return len(relay.listeners) == 1 })
+
+	// the slow listener is called without the lock held
+	go relay.publish(SolutionStatus{RequestID: "request", Progress: RequestRunningStatus})
+	waitFor(func() bool { return len(relay.statuses) == 1 })
+
+	received := make(chan SolutionStatus, 1)
+	go relay.Subscribe(func(status SolutionStatus) {
+		received <- status
+	})
+	select {
+	case status := <-received:
+		assert.Equal(t, RequestRunningStatus, status.Progress)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscribing was blocked by a slow listener")
+	}
+
+	close(block)
+	relay.finish(nil)
+}
+
+func TestResumeRequests(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 2})
+	defer h.close()
+
+	requestID, resume := h.dispatchRecorded(t)
+	fits := len(h.server.FitDatasets())
+	results := len(h.data.Results())
+
+	// simulate a restart while the second solution was being dispatched
+	req, err := h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	interrupted := req.Solutions[1].SolutionID
+	state, err := json.Marshal(resume)
+	assert.NoError(t, err)
+	assert.NoError(t, h.solution.PersistRequest(requestID, req.Dataset, RequestRunningStatus, time.Now()))
+	assert.NoError(t, h.solution.PersistRequestState(requestID, string(state), ""))
+	assert.NoError(t, h.solution.PersistSolution(requestID, interrupted, SolutionRunningStatus, time.Now()))
+
+	// the request is attached to its search again and relays its statuses
+	err = ResumeRequests(h.client, h.solution, h.data)
+	assert.NoError(t, err)
+	relay := GetResumedRequest(requestID)
+	if assert.NotNil(t, relay) {
+		assert.NoError(t, relay.Subscribe(func(status SolutionStatus) {}))
+	}
+
+	req, err = h.solution.FetchRequest(requestID)
+	assert.NoError(t, err)
+	assert.Equal(t, RequestCompletedStatus, req.Progress)
+	assert.Len(t, req.Solutions, 2)
+	assert.Equal(t, SolutionCompletedStatus, req.Solutions[0].Progress)
+	assert.Equal(t, SolutionErroredStatus, req.Solutions[1].Progress)
+
+	// the known solutions are not fitted or produced again
+	assert.Len(t, h.server.FitDatasets(), fits)
+	assert.Len(t, h.data.Results(), results)
+}
+
+func TestResumeRequestsUndispatched(t *testing.T) {
+	storage := ta2mock.NewSolutionStorage()
+	assert.NoError(t, storage.PersistRequest("request", "dataset", RequestPendingStatus, time.Now()))
+	assert.NoError(t, storage.PersistSolution("request", "solution", SolutionPendingStatus, time.Now()))
+
+	// a request without resume state is abandoned along with its solutions
+	err := ResumeRequests(nil, storage, ta2mock.NewDataStorage())
+	assert.NoError(t, err)
+	req, err := storage.FetchRequest("request")
+	assert.NoError(t, err)
+	assert.Equal(t, RequestErroredStatus, req.Progress)
+	assert.Equal(t, SolutionErroredStatus, req.Solutions[0].Progress)
+	state, err := storage.FetchRequestState("request")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, state.ErrorReason)
+}
+
+func TestPersistBaselinesResumed(t *testing.T) {
+	h := newDispatchHarness(t, &ta2mock.Config{Solutions: 1})
+	defer h.close()
+
+	requestID, resume := h.dispatchRecorded(t)
+	request, err := NewSolutionRequest(resume.Request)
+	assert.NoError(t, err)
+	trainSchemaFile := strings.Replace(resume.DatasetURITrain, "file://", "", 1)
+	testSchemaFile := strings.Replace(resume.DatasetURITest, "file://", "", 1)
+
+	// the stored baselines are not scored again
+	request.persistBaselines(h.solution, requestID, trainSchemaFile, testSchemaFile)
+	baselines, err := h.solution.FetchBaselineScores(requestID)
+	assert.NoError(t, err)
+	assert.Len(t, baselines, 2)
+
+	// only the missing ones are when the first run was interrupted
+	assert.NoError(t, h.solution.PersistBaselineScore("partial", BaselineMean, baselines[0].Metric, -1))
+	request.persistBaselines(h.solution, "partial", trainSchemaFile, testSchemaFile)
+	partial, err := h.solution.FetchBaselineScores("partial")
+	assert.NoError(t, err)
+	assert.Len(t, partial, 2)
+	scores := make(map[string]*api.BaselineScore)
+	for _, score := range partial {
+		scores[score.Baseline] = score
+	}
+	assert.Equal(t, -1.0, scores[BaselineMean].Score)
+	assert.NotNil(t, scores[BaselineLinear])
+}
@@ -0,0 +1,367 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// RequestQueuedStatus represents that the solution request is waiting for
+	// the TA2 system to have capacity for its search.
+	RequestQueuedStatus = "REQUEST_QUEUED"
+
+	defaultBudgetWindow   = time.Hour
+	budgetRecheckInterval = time.Minute
+)
+
+var (
+	searchScheduler   = NewScheduler(&SchedulerConfig{})
+	searchSchedulerMu = &sync.RWMutex{}
+)
+
+// SchedulerConfig holds the limits of the solution searches. The budgets are
+// the search time a single user and all users can consume over the budget
+// window, counting the time searches actually ran. A search is only started
+// while its user and all users are under budget, so a budget can be exceeded
+// by the searches running when it is reached. Zero values are unlimited,
+// except for the window which defaults to an hour.
+type SchedulerConfig struct {
+	MaxConcurrent int
+	MaxPriority   int
+	UserBudget    time.Duration
+	GlobalBudget  time.Duration
+	BudgetWindow  time.Duration
+}
+
+// QueueListener executes when the position of a queued search changes,
+// positions starting at 1. The queue ID identifies the search until it is
+// dispatched.
+type QueueListener func(queueID string, position int)
+
+// Scheduler queues solution searches by priority, then by arrival, and
+// admits them while the TA2 system has capacity. A search held back by the
+// budget of its user does not hold back the searches of other users.
+type Scheduler struct {
+	config   SchedulerConfig
+	mu       *sync.Mutex
+	queue    []*SearchTicket
+	queued   map[string]*SearchTicket
+	running  int
+	usage    []*searchUsage
+	sequence int64
+	timer    *time.Timer
+	recheck  time.Duration
+	now      func() time.Time
+}
+
+// SearchTicket is the place of a solution search in the scheduler.
+type SearchTicket struct {
+	ID        string
+	scheduler *Scheduler
+	user      string
+	priority  int
+	sequence  int64
+	position  int
+	listener  QueueListener
+	admitted  chan struct{}
+	cancelled chan struct{}
+	usage     *searchUsage
+	released  bool
+}
+
+// searchUsage is the time a search of a user ran, the end being zero while
+// the search is running.
+type searchUsage struct {
+	user  string
+	start time.Time
+	end   time.Time
+}
+
+type queueNotification struct {
+	listener QueueListener
+	queueID  string
+	position int
+}
+
+// NewScheduler creates a scheduler enforcing the supplied limits.
+func NewScheduler(config *SchedulerConfig) *Scheduler {
+	s := &Scheduler{
+		config:  *config,
+		mu:      &sync.Mutex{},
+		queue:   make([]*SearchTicket, 0),
+		queued:  make(map[string]*SearchTicket),
+		usage:   make([]*searchUsage, 0),
+		recheck: budgetRecheckInterval,
+		now:     time.Now,
+	}
+	if s.config.BudgetWindow <= 0 {
+		s.config.BudgetWindow = defaultBudgetWindow
+	}
+	return s
+}
+
+// SetSearchScheduler sets the scheduler of the solution searches.
+func SetSearchScheduler(scheduler *Scheduler) {
+	searchSchedulerMu.Lock()
+	defer searchSchedulerMu.Unlock()
+	searchScheduler = scheduler
+}
+
+// GetSearchScheduler returns the scheduler of the solution searches.
+func GetSearchScheduler() *Scheduler {
+	searchSchedulerMu.RLock()
+	defer searchSchedulerMu.RUnlock()
+	return searchScheduler
+}
+
+// Enqueue queues the search of a request for the user, as identified by the
+// server. The listener is notified of the queue position of the search until
+// it is admitted.
+func (s *Scheduler) Enqueue(req *SolutionRequest, user string, listener QueueListener) (*SearchTicket, error) {
+	if req.Priority > s.config.MaxPriority {
+		return nil, errors.Errorf("priority %d exceeds the max priority of %d", req.Priority, s.config.MaxPriority)
+	}
+
+	s.mu.Lock()
+	ticket := s.newTicket(req, user)
+	ticket.listener = listener
+	s.queue = append(s.queue, ticket)
+	s.queued[ticket.ID] = ticket
+	sort.SliceStable(s.queue, func(i, j int) bool {
+		if s.queue[i].priority != s.queue[j].priority {
+			return s.queue[i].priority > s.queue[j].priority
+		}
+		return s.queue[i].sequence < s.queue[j].sequence
+	})
+	notifications := s.schedule()
+	s.mu.Unlock()
+
+	notify(notifications)
+	return ticket, nil
+}
+
+// Cancel removes a queued search from the queue, returning false if no
+// search is queued with the ID.
+func (s *Scheduler) Cancel(queueID string) bool {
+	s.mu.Lock()
+	ticket, ok := s.queued[queueID]
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	s.remove(ticket)
+	close(ticket.cancelled)
+	notifications := s.schedule()
+	s.mu.Unlock()
+
+	notify(notifications)
+	return true
+}
+
+// reserve admits the search of a request right away, regardless of the
+// limits, for searches already running on the TA2 system.
+func (s *Scheduler) reserve(req *SolutionRequest, user string) *SearchTicket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ticket := s.newTicket(req, user)
+	s.start(ticket)
+	return ticket
+}
+
+func (s *Scheduler) newTicket(req *SolutionRequest, user string) *SearchTicket {
+	s.sequence++
+	return &SearchTicket{
+		ID:        uuid.NewV4().String(),
+		scheduler: s,
+		user:      user,
+		priority:  req.Priority,
+		sequence:  s.sequence,
+		admitted:  make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+}
+
+func (s *Scheduler) start(ticket *SearchTicket) {
+	ticket.usage = &searchUsage{
+		user:  ticket.user,
+		start: s.now(),
+	}
+	s.usage = append(s.usage, ticket.usage)
+	s.running++
+	close(ticket.admitted)
+}
+
+// remove marks a queued ticket as released and takes it out of the queue.
+func (s *Scheduler) remove(ticket *SearchTicket) {
+	ticket.released = true
+	delete(s.queued, ticket.ID)
+	for i, queued := range s.queue {
+		if queued == ticket {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			break
+		}
+	}
+}
+
+// consumed returns the search time consumed within the budget window by the
+// user, or by all users when all is set, and drops the usage that ended
+// before the window.
+func (s *Scheduler) consumed(user string, all bool, now time.Time) time.Duration {
+	from := now.Add(-s.config.BudgetWindow)
+	usage := make([]*searchUsage, 0, len(s.usage))
+	total := time.Duration(0)
+	for _, u := range s.usage {
+		end := u.end
+		if end.IsZero() {
+			end = now
+		} else if end.Before(from) {
+			continue
+		}
+		usage = append(usage, u)
+		if !all && u.user != user {
+			continue
+		}
+		start := u.start
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	s.usage = usage
+	return total
+}
+
+// fits returns whether the ticket is held back by the capacity shared by all
+// users, whether it is held back by the budget of its user, and whether a
+// budget is what holds it back.
+func (s *Scheduler) fits(ticket *SearchTicket, now time.Time) (bool, bool, bool) {
+	if s.config.MaxConcurrent > 0 && s.running >= s.config.MaxConcurrent {
+		return false, true, false
+	}
+	if s.config.GlobalBudget > 0 && s.consumed("", true, now) >= s.config.GlobalBudget {
+		return false, true, true
+	}
+	if s.config.UserBudget > 0 && s.consumed(ticket.user, false, now) >= s.config.UserBudget {
+		return true, false, true
+	}
+	return true, true, false
+}
+
+// schedule admits the queued searches that fit and returns the position
+// changes of the searches left in the queue. Searches held back by a budget
+// are checked again once the budget window has moved on. It must be called
+// with the scheduler locked.
+func (s *Scheduler) schedule() []*queueNotification {
+	now := s.now()
+	queued := make([]*SearchTicket, 0)
+	blocked := false
+	overBudget := false
+	for _, ticket := range s.queue {
+		if !blocked {
+			global, user, budget := s.fits(ticket, now)
+			if global && user {
+				delete(s.queued, ticket.ID)
+				s.start(ticket)
+				continue
+			}
+			// searches of lower priority wait for the shared capacity
+			blocked = !global
+			overBudget = overBudget || budget
+		}
+		queued = append(queued, ticket)
+	}
+	s.queue = queued
+
+	if overBudget && s.timer == nil {
+		s.timer = time.AfterFunc(s.recheck, s.reschedule)
+	}
+
+	notifications := make([]*queueNotification, 0)
+	for i, ticket := range s.queue {
+		if ticket.position != i+1 {
+			ticket.position = i + 1
+			if ticket.listener != nil {
+				notifications = append(notifications, &queueNotification{
+					listener: ticket.listener,
+					queueID:  ticket.ID,
+					position: ticket.position,
+				})
+			}
+		}
+	}
+	return notifications
+}
+
+func (s *Scheduler) reschedule() {
+	s.mu.Lock()
+	s.timer = nil
+	notifications := s.schedule()
+	s.mu.Unlock()
+
+	notify(notifications)
+}
+
+func notify(notifications []*queueNotification) {
+	for _, n := range notifications {
+		n.listener(n.queueID, n.position)
+	}
+}
+
+// Wait blocks until the search is admitted. It returns an error if the
+// search is cancelled or the done channel is closed first, in which case the
+// ticket is released.
+func (t *SearchTicket) Wait(done <-chan struct{}) error {
+	select {
+	case <-t.admitted:
+		return nil
+	case <-t.cancelled:
+		return errors.New("search cancelled while queued")
+	case <-done:
+		t.Release()
+		return errors.New("search cancelled while queued")
+	}
+}
+
+// Release ends the usage of an admitted search, or removes a queued search
+// from the queue. Releasing a ticket more than once has no effect.
+func (t *SearchTicket) Release() {
+	s := t.scheduler
+	s.mu.Lock()
+	if t.released {
+		s.mu.Unlock()
+		return
+	}
+
+	if t.usage != nil {
+		t.released = true
+		t.usage.end = s.now()
+		s.running--
+	} else {
+		s.remove(t)
+	}
+	notifications := s.schedule()
+	s.mu.Unlock()
+
+	notify(notifications)
+}
@@ -0,0 +1,149 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func isAdmitted(ticket *SearchTicket) bool {
+	select {
+	case <-ticket.admitted:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestSchedulerConcurrencyAndPriority(t *testing.T) {
+	scheduler := NewScheduler(&SchedulerConfig{MaxConcurrent: 1, MaxPriority: 1})
+
+	running, err := scheduler.Enqueue(&SolutionRequest{}, "a", nil)
+	assert.NoError(t, err)
+	assert.True(t, isAdmitted(running))
+
+	positions := make(map[string][]int)
+	enqueue := func(name string, priority int) *SearchTicket {
+		ticket, err := scheduler.Enqueue(&SolutionRequest{Priority: priority}, "a", func(queueID string, position int) {
+			positions[name] = append(positions[name], position)
+		})
+		assert.NoError(t, err)
+		return ticket
+	}
+	low := enqueue("low", 0)
+	high := enqueue("high", 1)
+	assert.False(t, isAdmitted(low))
+	assert.False(t, isAdmitted(high))
+
+	// the higher priority search jumps ahead in the queue
+	assert.Equal(t, []int{1, 2}, positions["low"])
+	assert.Equal(t, []int{1}, positions["high"])
+
+	// priorities above the max are not allowed
+	_, err = scheduler.Enqueue(&SolutionRequest{Priority: 2}, "a", nil)
+	assert.Error(t, err)
+
+	running.Release()
+	assert.True(t, isAdmitted(high))
+	assert.False(t, isAdmitted(low))
+	assert.Equal(t, []int{1, 2, 1}, positions["low"])
+
+	// releasing twice does not free another slot
+	running.Release()
+	assert.False(t, isAdmitted(low))
+
+	high.Release()
+	assert.True(t, isAdmitted(low))
+	low.Release()
+}
+
+func TestSchedulerBudgets(t *testing.T) {
+	start := time.Now()
+	clock := start
+	scheduler := NewScheduler(&SchedulerConfig{
+		UserBudget:   10 * time.Minute,
+		GlobalBudget: 25 * time.Minute,
+		BudgetWindow: time.Hour,
+	})
+	scheduler.now = func() time.Time { return clock }
+	scheduler.recheck = time.Hour
+
+	first, err := scheduler.Enqueue(&SolutionRequest{}, "a", nil)
+	assert.NoError(t, err)
+	assert.True(t, isAdmitted(first))
+
+	// the user spent its budget but does not hold back other users
+	clock = start.Add(10 * time.Minute)
+	second, err := scheduler.Enqueue(&SolutionRequest{}, "a", nil)
+	assert.NoError(t, err)
+	other, err := scheduler.Enqueue(&SolutionRequest{}, "b", nil)
+	assert.NoError(t, err)
+	assert.False(t, isAdmitted(second))
+	assert.True(t, isAdmitted(other))
+	assert.NotNil(t, scheduler.timer)
+
+	// the global budget is spent
+	clock = start.Add(20 * time.Minute)
+	third, err := scheduler.Enqueue(&SolutionRequest{}, "c", nil)
+	assert.NoError(t, err)
+	first.Release()
+	other.Release()
+	assert.False(t, isAdmitted(second))
+	assert.False(t, isAdmitted(third))
+
+	// the budgets recover as the time consumed leaves the window
+	clock = start.Add(75 * time.Minute)
+	scheduler.reschedule()
+	assert.True(t, isAdmitted(second))
+	assert.True(t, isAdmitted(third))
+	second.Release()
+	third.Release()
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	scheduler := NewScheduler(&SchedulerConfig{MaxConcurrent: 1})
+
+	running, err := scheduler.Enqueue(&SolutionRequest{}, "a", nil)
+	assert.NoError(t, err)
+
+	queueID := ""
+	queued, err := scheduler.Enqueue(&SolutionRequest{}, "a", func(id string, position int) {
+		queueID = id
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, queued.ID, queueID)
+
+	// a queued search is stopped by its queue ID
+	assert.True(t, scheduler.Cancel(queueID))
+	assert.Error(t, queued.Wait(nil))
+	assert.False(t, scheduler.Cancel(queueID))
+	assert.False(t, scheduler.Cancel(running.ID))
+	assert.Len(t, scheduler.queue, 0)
+
+	// a queued search cancelled by its client leaves the queue
+	abandoned, err := scheduler.Enqueue(&SolutionRequest{}, "a", nil)
+	assert.NoError(t, err)
+	done := make(chan struct{})
+	close(done)
+	assert.Error(t, abandoned.Wait(done))
+	assert.Len(t, scheduler.queue, 0)
+
+	running.Release()
+	assert.Equal(t, 0, scheduler.running)
+}
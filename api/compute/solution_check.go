@@ -0,0 +1,180 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+const (
+	// CheckRangeBlowUp flags numerical predictions far outside of the target range.
+	CheckRangeBlowUp = "rangeBlowUp"
+	// CheckConstantPredictions flags solutions predicting a single value.
+	CheckConstantPredictions = "constantPredictions"
+	// CheckWorseThanBaseline flags solutions doing worse than a naive baseline.
+	CheckWorseThanBaseline = "worseThanBaseline"
+	// CheckMissingPredictions flags solutions that did not predict every row.
+	CheckMissingPredictions = "missingPredictions"
+
+	defaultRangeStdDevs = 10.0
+)
+
+// SolutionCheck examines the predictions of a solution, returning why the
+// solution is suspect or an empty string if it passes.
+type SolutionCheck func(stats *api.ResultQualityStats) string
+
+var (
+	checksMu       = &sync.RWMutex{}
+	solutionChecks = map[string]SolutionCheck{
+		CheckRangeBlowUp:         checkRangeBlowUp,
+		CheckConstantPredictions: checkConstantPredictions,
+		CheckWorseThanBaseline:   checkWorseThanBaseline,
+		CheckMissingPredictions:  checkMissingPredictions,
+	}
+	enabledChecks = []string{
+		CheckRangeBlowUp,
+		CheckConstantPredictions,
+		CheckWorseThanBaseline,
+		CheckMissingPredictions,
+	}
+	rangeStdDevs = defaultRangeStdDevs
+)
+
+// RegisterSolutionCheck adds a named check, or replaces the check with the
+// same name, and enables it.
+func RegisterSolutionCheck(name string, check SolutionCheck) {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	if _, ok := solutionChecks[name]; !ok {
+		enabledChecks = append(enabledChecks, name)
+	}
+	solutionChecks[name] = check
+}
+
+// SetSolutionChecks sets the named checks run against solution results.
+func SetSolutionChecks(names []string) error {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	enabled := make([]string, 0)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := solutionChecks[name]; !ok {
+			return errors.Errorf("unknown solution check `%s`", name)
+		}
+		enabled = append(enabled, name)
+	}
+	enabledChecks = enabled
+	return nil
+}
+
+// SetRangeCheckStdDevs sets how many standard deviations from the target
+// mean predictions can be before the range check fails.
+func SetRangeCheckStdDevs(stdDevs float64) {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	rangeStdDevs = stdDevs
+}
+
+// runSolutionChecks runs the enabled checks, returning the failed ones.
+func runSolutionChecks(stats *api.ResultQualityStats) []*api.SolutionFlag {
+	checksMu.RLock()
+	defer checksMu.RUnlock()
+	flags := make([]*api.SolutionFlag, 0)
+	for _, name := range enabledChecks {
+		reason := solutionChecks[name](stats)
+		if reason != "" {
+			flags = append(flags, &api.SolutionFlag{
+				Check:  name,
+				Reason: reason,
+			})
+		}
+	}
+	return flags
+}
+
+func checkRangeBlowUp(stats *api.ResultQualityStats) string {
+	if !stats.Numerical || stats.NumPredicted == 0 {
+		return ""
+	}
+	limit := rangeStdDevs * stats.TargetStdDev
+	if math.Abs(stats.PredictedMin-stats.TargetMean) > limit || math.Abs(stats.PredictedMax-stats.TargetMean) > limit {
+		return fmt.Sprintf("predictions range from %g to %g, more than %g standard deviations from the target mean of %g",
+			stats.PredictedMin, stats.PredictedMax, rangeStdDevs, stats.TargetMean)
+	}
+	return ""
+}
+
+func checkConstantPredictions(stats *api.ResultQualityStats) string {
+	if stats.NumPredicted > 1 && stats.NumDistinct == 1 {
+		return fmt.Sprintf("all %d predictions are the same value", stats.NumPredicted)
+	}
+	return ""
+}
+
+func checkWorseThanBaseline(stats *api.ResultQualityStats) string {
+	if stats.NumPredicted == 0 || stats.Error <= stats.BaselineError {
+		return ""
+	}
+	if stats.Numerical {
+		return fmt.Sprintf("mean absolute error of %g is worse than the %g from predicting the target mean", stats.Error, stats.BaselineError)
+	}
+	return fmt.Sprintf("error rate of %.3f is worse than the %.3f from predicting the most frequent class", stats.Error, stats.BaselineError)
+}
+
+func checkMissingPredictions(stats *api.ResultQualityStats) string {
+	if stats.ExpectedRows > 0 && stats.NumPredicted < stats.ExpectedRows {
+		return fmt.Sprintf("%d of %d rows have no prediction", stats.ExpectedRows-stats.NumPredicted, stats.ExpectedRows)
+	}
+	return ""
+}
+
+// checkSolution runs the quality checks against the persisted results of each
+// target of a solution and stores the failed checks. Checks are informative
+// so failures to run them are logged rather than failing the solution.
+func checkSolution(solutionStorage api.SolutionStorage, dataStorage api.DataStorage, dataset string, solutionID string, resultURI string, targets []string, expectedRows int) {
+	for _, target := range targets {
+		stats, err := dataStorage.FetchResultQualityStats(dataset, model.NormalizeDatasetID(dataset), resultURI, target)
+		if err != nil {
+			log.Warnf("unable to check target %s of solution %s: %v", target, solutionID, err)
+			continue
+		}
+		stats.ExpectedRows = expectedRows
+
+		for _, flag := range runSolutionChecks(stats) {
+			// name the target the check failed for when there are several
+			if len(targets) > 1 {
+				flag.Reason = fmt.Sprintf("%s: %s", target, flag.Reason)
+			}
+			log.Infof("solution %s failed check %s: %s", solutionID, flag.Check, flag.Reason)
+			err = solutionStorage.PersistSolutionFlag(solutionID, flag.Check, flag.Reason)
+			if err != nil {
+				log.Warnf("unable to persist check %s of solution %s: %v", flag.Check, solutionID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func flaggedChecks(flags []*api.SolutionFlag) []string {
+	checks := make([]string, 0)
+	for _, flag := range flags {
+		checks = append(checks, flag.Check)
+	}
+	return checks
+}
+
+func TestRunSolutionChecksPass(t *testing.T) {
+	stats := &api.ResultQualityStats{
+		Numerical:     true,
+		NumRows:       100,
+		NumPredicted:  100,
+		NumDistinct:   80,
+		ExpectedRows:  100,
+		PredictedMin:  2,
+		PredictedMax:  18,
+		TargetMean:    10,
+		TargetStdDev:  4,
+		Error:         1.5,
+		BaselineError: 3.2,
+	}
+	assert.Empty(t, runSolutionChecks(stats))
+}
+
+func TestRunSolutionChecksNumerical(t *testing.T) {
+	stats := &api.ResultQualityStats{
+		Numerical:     true,
+		NumRows:       90,
+		NumPredicted:  90,
+		NumDistinct:   1,
+		ExpectedRows:  100,
+		PredictedMin:  500,
+		PredictedMax:  500,
+		TargetMean:    10,
+		TargetStdDev:  4,
+		Error:         490,
+		BaselineError: 3.2,
+	}
+	flags := runSolutionChecks(stats)
+	assert.Equal(t, []string{CheckRangeBlowUp, CheckConstantPredictions, CheckWorseThanBaseline, CheckMissingPredictions}, flaggedChecks(flags))
+	assert.Equal(t, "10 of 100 rows have no prediction", flags[3].Reason)
+}
+
+func TestRunSolutionChecksCategorical(t *testing.T) {
+	stats := &api.ResultQualityStats{
+		NumRows:       50,
+		NumPredicted:  50,
+		NumDistinct:   3,
+		Error:         0.6,
+		BaselineError: 0.4,
+	}
+	flags := runSolutionChecks(stats)
+	assert.Equal(t, []string{CheckWorseThanBaseline}, flaggedChecks(flags))
+	assert.Equal(t, "error rate of 0.600 is worse than the 0.400 from predicting the most frequent class", flags[0].Reason)
+}
+
+func TestSetSolutionChecks(t *testing.T) {
+	defer SetSolutionChecks([]string{CheckRangeBlowUp, CheckConstantPredictions, CheckWorseThanBaseline, CheckMissingPredictions})
+
+	err := SetSolutionChecks([]string{"unknown"})
+	assert.Error(t, err)
+
+	err = SetSolutionChecks([]string{" constantPredictions", ""})
+	assert.NoError(t, err)
+
+	stats := &api.ResultQualityStats{
+		NumPredicted:  10,
+		NumDistinct:   1,
+		ExpectedRows:  20,
+		Error:         1,
+		BaselineError: 0,
+	}
+	assert.Equal(t, []string{CheckConstantPredictions}, flaggedChecks(runSolutionChecks(stats)))
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/uncharted-distil/distil-compute/pipeline"
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
 	"github.com/uncharted-distil/distil-compute/primitive/compute/description"
+	log "github.com/unchartedsoftware/plog"
 
 	"github.com/uncharted-distil/distil/api/env"
 	api "github.com/uncharted-distil/distil/api/model"
@@ -93,18 +94,38 @@ type SolutionRequest struct {
 	Dataset          string            `json:"dataset"`
 	Index            string            `json:"index"`
 	TargetFeature    string            `json:"target"`
+	TargetFeatures   []string          `json:"targets"`
 	Task             string            `json:"task"`
 	SubTask          string            `json:"subTask"`
 	MaxSolutions     int32             `json:"maxSolutions"`
 	MaxTime          int64             `json:"maxTime"`
 	Filters          *api.FilterParams `json:"filters"`
 	Metrics          []string          `json:"metrics"`
+	Split            *SplitSpec        `json:"split"`
+	Folds            int               `json:"crossValidationFolds"`
+	Forecast         *ForecastSpec     `json:"forecast"`
+	Priority         int               `json:"priority"`
 	mu               *sync.Mutex
 	wg               *sync.WaitGroup
 	requestChannel   chan SolutionStatus
 	solutionChannels []chan SolutionStatus
 	listener         SolutionStatusListener
 	finished         chan error
+	foldURIs         []*foldURI
+	skipSolutions    map[string]bool
+}
+
+// foldURI holds the dataset URIs of a cross validation fold.
+type foldURI struct {
+	Index int    `json:"index"`
+	Train string `json:"train"`
+	Test  string `json:"test"`
+}
+
+// metricScore is a single score returned by the TA2 system.
+type metricScore struct {
+	metric string
+	score  float64
 }
 
 // NewSolutionRequest instantiates a new SolutionRequest.
@@ -119,17 +140,29 @@ func NewSolutionRequest(data []byte) (*SolutionRequest, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	// the first of multiple targets is the primary target, the only one used
+	// to split and stratify the data, score the baselines and the cross
+	// validation folds, and pick defaults. Every target is searched for,
+	// predicted and checked.
+	if len(req.TargetFeatures) == 0 && req.TargetFeature != "" {
+		req.TargetFeatures = []string{req.TargetFeature}
+	}
+	if len(req.TargetFeatures) > 0 {
+		req.TargetFeature = req.TargetFeatures[0]
+	}
 	return req, nil
 }
 
 // SolutionStatus represents a solution status.
 type SolutionStatus struct {
-	Progress   string    `json:"progress"`
-	RequestID  string    `json:"requestId"`
-	SolutionID string    `json:"solutionId"`
-	ResultID   string    `json:"resultId"`
-	Error      error     `json:"error"`
-	Timestamp  time.Time `json:"timestamp"`
+	Progress      string    `json:"progress"`
+	RequestID     string    `json:"requestId"`
+	SolutionID    string    `json:"solutionId"`
+	ResultID      string    `json:"resultId"`
+	QueuePosition int       `json:"queuePosition"`
+	Error         error     `json:"error"`
+	Timestamp     time.Time `json:"timestamp"`
 }
 
 // SolutionStatusListener executes on a new solution status.
@@ -176,13 +209,36 @@ func (s *SolutionRequest) Listen(listener SolutionStatusListener) error {
 	return <-s.finished
 }
 
-func (s *SolutionRequest) createSearchSolutionsRequest(columnIndex int, preprocessing *pipeline.PipelineDescription,
+func (s *SolutionRequest) createSearchSolutionsRequest(columnIndices []int, preprocessing *pipeline.PipelineDescription,
 	datasetURI string, userAgent string) (*pipeline.SearchSolutionsRequest, error) {
-	return createSearchSolutionsRequest(columnIndex, preprocessing, datasetURI, userAgent, s.TargetFeature, s.Dataset, s.Metrics, s.Task, s.SubTask, s.MaxTime)
+	targets := createProblemTargets(s.TargetFeatures, columnIndices)
+	return createSearchSolutionsRequest(targets, preprocessing, datasetURI, userAgent, s.Dataset, ta2Metrics(s.Metrics), s.Task, s.SubTask, s.MaxTime)
 }
 
-func createSearchSolutionsRequest(columnIndex int, preprocessing *pipeline.PipelineDescription,
-	datasetURI string, userAgent string, targetFeature string, dataset string, metrics []string, task string, subTask string, maxTime int64) (*pipeline.SearchSolutionsRequest, error) {
+func (s *SolutionRequest) isTargetFeature(name string) bool {
+	for _, targetFeature := range s.TargetFeatures {
+		if targetFeature == name {
+			return true
+		}
+	}
+	return false
+}
+
+// createProblemTargets creates the problem targets of the target features,
+// found at the matching column indices of the dataset.
+func createProblemTargets(targetFeatures []string, columnIndices []int) []*pipeline.ProblemTarget {
+	targets := make([]*pipeline.ProblemTarget, 0)
+	for i, targetFeature := range targetFeatures {
+		for _, target := range compute.ConvertTargetFeaturesTA3ToTA2(targetFeature, columnIndices[i]) {
+			target.TargetIndex = int32(len(targets))
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+func createSearchSolutionsRequest(targets []*pipeline.ProblemTarget, preprocessing *pipeline.PipelineDescription,
+	datasetURI string, userAgent string, dataset string, metrics []string, task string, subTask string, maxTime int64) (*pipeline.SearchSolutionsRequest, error) {
 
 	return &pipeline.SearchSolutionsRequest{
 		Problem: &pipeline.ProblemDescription{
@@ -194,7 +250,7 @@ func createSearchSolutionsRequest(columnIndex int, preprocessing *pipeline.Pipel
 			Inputs: []*pipeline.ProblemInput{
 				{
 					DatasetId: compute.ConvertDatasetTA3ToTA2(dataset),
-					Targets:   compute.ConvertTargetFeaturesTA3ToTA2(targetFeature, columnIndex),
+					Targets:   targets,
 				},
 			},
 		},
@@ -239,7 +295,7 @@ func (s *SolutionRequest) createPreprocessingPipeline(featureVariables []*model.
 	return preprocessingPipeline, nil
 }
 
-func (s *SolutionRequest) createProduceSolutionRequest(datasetURI string, fittedSolutionID string) *pipeline.ProduceSolutionRequest {
+func createProduceSolutionRequest(datasetURI string, fittedSolutionID string) *pipeline.ProduceSolutionRequest {
 	return &pipeline.ProduceSolutionRequest{
 		FittedSolutionId: fittedSolutionID,
 		Inputs: []*pipeline.Value{
@@ -325,7 +381,7 @@ func (s *SolutionRequest) persistRequestStatus(statusChan chan SolutionStatus, s
 	return nil
 }
 
-func (s *SolutionRequest) persistSolutionResults(statusChan chan SolutionStatus, client *compute.Client, solutionStorage api.SolutionStorage, dataStorage api.DataStorage, searchID string, dataset string, solutionID string, fittedSolutionID string, resultID string, resultURI string) {
+func (s *SolutionRequest) persistSolutionResults(statusChan chan SolutionStatus, client *compute.Client, solutionStorage api.SolutionStorage, dataStorage api.DataStorage, searchID string, dataset string, solutionID string, fittedSolutionID string, resultID string, resultURI string, expectedRows int) {
 	// persist the completed state
 	err := solutionStorage.PersistSolution(searchID, solutionID, SolutionCompletedStatus, time.Now())
 	if err != nil {
@@ -341,12 +397,14 @@ func (s *SolutionRequest) persistSolutionResults(statusChan chan SolutionStatus,
 		return
 	}
 	// persist results
-	err = dataStorage.PersistResult(dataset, model.NormalizeDatasetID(dataset), resultURI, s.TargetFeature)
+	err = dataStorage.PersistResult(dataset, model.NormalizeDatasetID(dataset), resultURI, s.TargetFeatures)
 	if err != nil {
 		// notify of error
 		s.persistSolutionError(statusChan, solutionStorage, searchID, solutionID, err)
 		return
 	}
+	// flag suspect results
+	checkSolution(solutionStorage, dataStorage, dataset, solutionID, resultURI, s.TargetFeatures, expectedRows)
 	// HACK: we shouldnt need these
 	time.Sleep(time.Second)
 	// notify client of update
@@ -359,35 +417,159 @@ func (s *SolutionRequest) persistSolutionResults(statusChan chan SolutionStatus,
 	}
 }
 
-func (s *SolutionRequest) dispatchSolution(statusChan chan SolutionStatus, client *compute.Client, solutionStorage api.SolutionStorage, dataStorage api.DataStorage, searchID string, solutionID string, dataset string, datasetURITrain string, datasetURITest string) {
-
-	// score solution
-	solutionScoreResponses, err := client.GenerateSolutionScores(context.Background(), solutionID, datasetURITest, s.Metrics)
+func (s *SolutionRequest) generateScores(client *compute.Client, solutionID string, datasetURI string) ([]*metricScore, error) {
+	metrics := ta2Metrics(s.Metrics)
+	if len(metrics) == 0 {
+		return []*metricScore{}, nil
+	}
+	solutionScoreResponses, err := client.GenerateSolutionScores(context.Background(), solutionID, datasetURI, metrics)
 	if err != nil {
-		s.persistSolutionError(statusChan, solutionStorage, searchID, solutionID, err)
-		return
+		return nil, err
 	}
 
-	// persist the scores
+	scores := make([]*metricScore, 0)
 	for _, response := range solutionScoreResponses {
-		// only persist scores from COMPLETED responses
+		// only use scores from COMPLETED responses
 		if response.Progress.State == pipeline.ProgressState_COMPLETED {
 			for _, score := range response.Scores {
 				metric := ""
 				if score.GetMetric() == nil {
-					metric = compute.ConvertMetricsFromTA3ToTA2(s.Metrics)[0].GetMetric().String()
+					metric = compute.ConvertMetricsFromTA3ToTA2(metrics)[0].GetMetric().String()
 				} else {
 					metric = score.Metric.Metric.String()
 				}
-				err := solutionStorage.PersistSolutionScore(solutionID, metric, score.Value.GetRaw().GetDouble())
-				if err != nil {
-					s.persistSolutionError(statusChan, solutionStorage, searchID, solutionID, err)
-					return
-				}
+				scores = append(scores, &metricScore{
+					metric: metric,
+					score:  score.Value.GetRaw().GetDouble(),
+				})
 			}
 		}
 	}
 
+	return scores, nil
+}
+
+// crossValidate fits the solution on the train set of each fold and scores
+// its predictions of the fold test set, persisting the fold scores along with
+// their mean and standard deviation. Only the first target is scored and the
+// metrics that cannot be computed from the predictions are skipped.
+func (s *SolutionRequest) crossValidate(client *compute.Client, solutionStorage api.SolutionStorage, solutionID string) error {
+	if len(s.foldURIs) == 0 {
+		return nil
+	}
+
+	foldScores := make(map[string][]float64)
+	for _, fold := range s.foldURIs {
+		actual, predicted, err := s.predictFold(client, solutionID, fold)
+		if err != nil {
+			return errors.Wrapf(err, "unable to predict fold %d", fold.Index)
+		}
+
+		for _, metric := range s.Metrics {
+			score, err := scorePredictions(metric, actual, predicted)
+			if err != nil {
+				log.Infof("unable to score fold %d of solution %s: %v", fold.Index, solutionID, err)
+				continue
+			}
+			metric = convertMetricToTA2(metric)
+			err = solutionStorage.PersistSolutionFoldScore(solutionID, metric, api.ScoreTypeFold, fold.Index, score)
+			if err != nil {
+				return err
+			}
+			foldScores[metric] = append(foldScores[metric], score)
+		}
+	}
+
+	for _, aggregate := range aggregateFoldScores(foldScores) {
+		err := solutionStorage.PersistSolutionFoldScore(solutionID, aggregate.Metric, api.ScoreTypeFoldMean, 0, aggregate.Mean)
+		if err != nil {
+			return err
+		}
+		err = solutionStorage.PersistSolutionFoldScore(solutionID, aggregate.Metric, api.ScoreTypeFoldStd, 0, aggregate.Std)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// predictFold fits the solution on the train set of a fold and returns the
+// actual and predicted target values of the fold test set.
+func (s *SolutionRequest) predictFold(client *compute.Client, solutionID string, fold *foldURI) ([]string, []string, error) {
+	if fold.Train == "" {
+		return nil, nil, errors.New("fold has no train dataset")
+	}
+
+	fitResults, err := client.GenerateSolutionFit(context.Background(), solutionID, []string{fold.Train})
+	if err != nil {
+		return nil, nil, err
+	}
+	fittedSolutionID := getFittedSolutionID(fitResults)
+	if fittedSolutionID == "" {
+		return nil, nil, errors.Errorf("no fitted solution ID for solution `%s`", solutionID)
+	}
+
+	predictionResponses, err := client.GeneratePredictions(context.Background(), createProduceSolutionRequest(fold.Test, fittedSolutionID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	testHeader, testRows, err := readDatasetRows(strings.Replace(fold.Test, "file://", "", 1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, response := range predictionResponses {
+		if response.Progress.State != pipeline.ProgressState_COMPLETED {
+			continue
+		}
+		resultURI, err := getProducedResultURI(response)
+		if err != nil {
+			return nil, nil, err
+		}
+		return readResultValues(resultURI, testHeader, testRows, s.TargetFeature)
+	}
+
+	return nil, nil, errors.New("no predictions produced")
+}
+
+// getFittedSolutionID returns the fitted solution ID of the completed fit
+// result.
+func getFittedSolutionID(fitResults []*pipeline.GetFitSolutionResultsResponse) string {
+	for _, result := range fitResults {
+		if result.GetFittedSolutionId() != "" {
+			return result.GetFittedSolutionId()
+		}
+	}
+	return ""
+}
+
+func (s *SolutionRequest) dispatchSolution(statusChan chan SolutionStatus, client *compute.Client, solutionStorage api.SolutionStorage, dataStorage api.DataStorage, searchID string, solutionID string, dataset string, datasetURITrain string, datasetURITest string) {
+
+	// score solution
+	scores, err := s.generateScores(client, solutionID, datasetURITest)
+	if err != nil {
+		s.persistSolutionError(statusChan, solutionStorage, searchID, solutionID, err)
+		return
+	}
+
+	// persist the scores
+	for _, score := range scores {
+		err := solutionStorage.PersistSolutionScore(solutionID, score.metric, score.score)
+		if err != nil {
+			s.persistSolutionError(statusChan, solutionStorage, searchID, solutionID, err)
+			return
+		}
+	}
+
+	// cross validate the solution if requested
+	err = s.crossValidate(client, solutionStorage, solutionID)
+	if err != nil {
+		s.persistSolutionError(statusChan, solutionStorage, searchID, solutionID, err)
+		return
+	}
+
 	// fit solution
 	var fitResults []*pipeline.GetFitSolutionResultsResponse
 	fitResults, err = client.GenerateSolutionFit(context.Background(), solutionID, []string{datasetURITrain})
@@ -397,13 +579,7 @@ func (s *SolutionRequest) dispatchSolution(statusChan chan SolutionStatus, clien
 	}
 
 	// find the completed result and get the fitted solution ID out
-	var fittedSolutionID string
-	for _, result := range fitResults {
-		if result.GetFittedSolutionId() != "" {
-			fittedSolutionID = result.GetFittedSolutionId()
-			break
-		}
-	}
+	fittedSolutionID := getFittedSolutionID(fitResults)
 	if fittedSolutionID == "" {
 		s.persistSolutionError(statusChan, solutionStorage, searchID, solutionID, errors.Errorf("no fitted solution ID for solution `%s`", solutionID))
 	}
@@ -412,7 +588,7 @@ func (s *SolutionRequest) dispatchSolution(statusChan chan SolutionStatus, clien
 	s.persistSolutionStatus(statusChan, solutionStorage, searchID, solutionID, SolutionRunningStatus)
 
 	// generate predictions
-	produceSolutionRequest := s.createProduceSolutionRequest(datasetURITest, fittedSolutionID)
+	produceSolutionRequest := createProduceSolutionRequest(datasetURITest, fittedSolutionID)
 
 	// generate predictions
 	predictionResponses, err := client.GeneratePredictions(context.Background(), produceSolutionRequest)
@@ -421,6 +597,13 @@ func (s *SolutionRequest) dispatchSolution(statusChan chan SolutionStatus, clien
 		return
 	}
 
+	// the number of rows that should be predicted
+	testHeader, testRows, err := readDatasetRows(strings.Replace(datasetURITest, "file://", "", 1))
+	if err != nil {
+		log.Warnf("unable to count test rows of solution %s: %v", solutionID, err)
+	}
+	expectedRows := len(testRows)
+
 	for _, response := range predictionResponses {
 
 		if response.Progress.State != pipeline.ProgressState_COMPLETED {
@@ -428,34 +611,45 @@ func (s *SolutionRequest) dispatchSolution(statusChan chan SolutionStatus, clien
 			continue
 		}
 
-		output, ok := response.ExposedOutputs[defaultExposedOutputKey]
-		if !ok {
-			err := errors.Errorf("output is missing from response")
+		resultURI, err := getProducedResultURI(response)
+		if err != nil {
 			s.persistSolutionError(statusChan, solutionStorage, searchID, solutionID, err)
 			return
 		}
+		resultID := getResultID(resultURI)
 
-		csvURI, ok := output.Value.(*pipeline.Value_CsvUri)
-		if !ok {
-			err := errors.Errorf("output is not of correct format")
-			s.persistSolutionError(statusChan, solutionStorage, searchID, solutionID, err)
-			return
-		}
+		// score the metrics the TA2 system does not support
+		s.persistLocalScores(solutionStorage, solutionID, resultURI, testHeader, testRows)
 
-		// remove the protocol portion if it exists. The returned value is either a
-		// csv file or a directory.
-		resultURI := csvURI.CsvUri
-		resultURI = strings.Replace(resultURI, "file://", "", 1)
+		// persist results
+		s.persistSolutionResults(statusChan, client, solutionStorage, dataStorage, searchID, dataset, solutionID, fittedSolutionID, resultID, resultURI, expectedRows)
+	}
+}
 
-		// get the result UUID. NOTE: Doing sha1 for now.
-		hasher := sha1.New()
-		hasher.Write([]byte(resultURI))
-		bs := hasher.Sum(nil)
-		resultID := fmt.Sprintf("%x", bs)
+// getProducedResultURI returns the path of the predictions output by a
+// completed produce response.
+func getProducedResultURI(response *pipeline.GetProduceSolutionResultsResponse) (string, error) {
+	output, ok := response.ExposedOutputs[defaultExposedOutputKey]
+	if !ok {
+		return "", errors.Errorf("output is missing from response")
+	}
 
-		// persist results
-		s.persistSolutionResults(statusChan, client, solutionStorage, dataStorage, searchID, dataset, solutionID, fittedSolutionID, resultID, resultURI)
+	csvURI, ok := output.Value.(*pipeline.Value_CsvUri)
+	if !ok {
+		return "", errors.Errorf("output is not of correct format")
 	}
+
+	// remove the protocol portion if it exists. The returned value is either a
+	// csv file or a directory.
+	return strings.Replace(csvURI.CsvUri, "file://", "", 1), nil
+}
+
+// getResultID returns the result UUID of a result. NOTE: Doing sha1 for now.
+func getResultID(resultURI string) string {
+	hasher := sha1.New()
+	hasher.Write([]byte(resultURI))
+	bs := hasher.Sum(nil)
+	return fmt.Sprintf("%x", bs)
 }
 
 func (s *SolutionRequest) dispatchRequest(client *compute.Client, solutionStorage api.SolutionStorage, dataStorage api.DataStorage, searchID string, dataset string, datasetURITrain string, datasetURITest string) {
@@ -467,8 +661,16 @@ func (s *SolutionRequest) dispatchRequest(client *compute.Client, solutionStorag
 		return
 	}
 
+	// score the baselines the solutions are compared against
+	go s.persistBaselines(solutionStorage, searchID,
+		strings.Replace(datasetURITrain, "file://", "", 1), strings.Replace(datasetURITest, "file://", "", 1))
+
 	// search for solutions, this wont return until the search finishes or it times out
 	err = client.SearchSolutions(context.Background(), searchID, func(solution *pipeline.GetSearchSolutionsResultsResponse) {
+		// solutions handled before a restart are not dispatched again
+		if s.skipSolutions[solution.SolutionId] {
+			return
+		}
 		// create a new status channel for the solution
 		c := newStatusChannel()
 		// add the solution to the request
@@ -491,13 +693,43 @@ func (s *SolutionRequest) dispatchRequest(client *compute.Client, solutionStorag
 	// wait until all are complete and the search has finished / timed out
 	s.waitOnSolutions()
 
+	// the request no longer needs to be resumed
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	}
+	err = solutionStorage.PersistRequestState(searchID, "", reason)
+	if err != nil {
+		log.Warnf("unable to clear resume state of request %s: %v", searchID, err)
+	}
+
 	// end search
 	s.finished <- client.EndSearch(context.Background(), searchID)
 }
 
+func datasetURI(schemaFile string) (string, error) {
+	absPath, err := filepath.Abs(schemaFile)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("file://%s", absPath), nil
+}
+
 // PersistAndDispatch persists the solution request and dispatches it.
 func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionStorage api.SolutionStorage, metaStorage api.MetadataStorage, dataStorage api.DataStorage) error {
 
+	// forecasts are tested on the latest time steps of the series
+	if s.Task == TaskTypeForecasting {
+		if s.Folds > 1 {
+			return errors.New("cross validation is not supported for forecasting")
+		}
+		split, err := s.Forecast.split(s.Split)
+		if err != nil {
+			return err
+		}
+		s.Split = split
+	}
+
 	// NOTE: D3M index field is needed in the persisted data.
 	s.Filters.Variables = append(s.Filters.Variables, model.D3MIndexFieldName)
 	// fetch the full set of variables associated with the dataset
@@ -518,14 +750,17 @@ func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionSto
 	// preprocessing step will mark them for removal by ta2
 	allVarFilters := *s.Filters
 	allVarFilters.Variables = []string{}
-	var targetVariable *model.Variable
+	targetVariables := make(map[string]*model.Variable)
 	for _, variable := range dataVariables {
 		// Exclude cluster/feature generated columns
 		allVarFilters.Variables = append(allVarFilters.Variables, variable.Name)
-		if variable.Name == s.TargetFeature {
-			targetVariable = variable
+		if s.isTargetFeature(variable.Name) {
+			targetVariables[variable.Name] = variable
 		}
 	}
+	if len(s.TargetFeatures) == 0 {
+		return errors.New("no target feature specified")
+	}
 
 	// fetch the queried dataset
 	dataset, err := api.FetchDataset(s.Dataset, true, true, &allVarFilters, metaStorage, dataStorage)
@@ -533,28 +768,56 @@ func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionSto
 		return err
 	}
 
-	columnIndex := getColumnIndex(targetVariable, dataset.Filters.Variables)
+	columnIndices := make([]int, len(s.TargetFeatures))
+	for i, targetFeature := range s.TargetFeatures {
+		targetVariable, ok := targetVariables[targetFeature]
+		if !ok {
+			return errors.Errorf("unable to find target variable '%s'", targetFeature)
+		}
+		columnIndices[i] = getColumnIndex(targetVariable, dataset.Filters.Variables)
+	}
 
 	// add dataset name to path
 	datasetInputDir := env.ResolvePath(dataset.Metadata.Source, dataset.Metadata.Folder)
 
 	// perist the datasets and get URI
-	datasetPathTrain, datasetPathTest, err := PersistOriginalData(s.Dataset, compute.D3MDataSchema, datasetInputDir, datasetDir)
+	datasetPathTrain, datasetPathTest, err := PersistOriginalData(s.Dataset, compute.D3MDataSchema, datasetInputDir, datasetDir, s.Split, s.TargetFeature)
 	if err != nil {
 		return err
 	}
 
 	// make sure the path is absolute and contains the URI prefix
-	datasetPathTrain, err = filepath.Abs(datasetPathTrain)
+	datasetURITrain, err := datasetURI(datasetPathTrain)
 	if err != nil {
 		return err
 	}
-	datasetPathTrain = fmt.Sprintf("file://%s", datasetPathTrain)
-	datasetPathTest, err = filepath.Abs(datasetPathTest)
+	datasetURITest, err := datasetURI(datasetPathTest)
 	if err != nil {
 		return err
 	}
-	datasetPathTest = fmt.Sprintf("file://%s", datasetPathTest)
+
+	// generate the cross validation folds from the train split
+	if s.Folds > 1 {
+		folds, err := PersistFolds(datasetPathTrain, s.Folds, s.Split, s.TargetFeature)
+		if err != nil {
+			return err
+		}
+		for _, fold := range folds {
+			foldTrain, err := datasetURI(fold.TrainSchemaFile)
+			if err != nil {
+				return err
+			}
+			foldTest, err := datasetURI(fold.TestSchemaFile)
+			if err != nil {
+				return err
+			}
+			s.foldURIs = append(s.foldURIs, &foldURI{
+				Index: fold.Index,
+				Train: foldTrain,
+				Test:  foldTest,
+			})
+		}
+	}
 
 	// generate the pre-processing pipeline to enforce feature selection and semantic type changes
 	var preprocessing *pipeline.PipelineDescription
@@ -566,7 +829,7 @@ func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionSto
 	}
 
 	// create search solutions request
-	searchRequest, err := s.createSearchSolutionsRequest(columnIndex, preprocessing, datasetPathTrain, client.UserAgent)
+	searchRequest, err := s.createSearchSolutionsRequest(columnIndices, preprocessing, datasetURITrain, client.UserAgent)
 	if err != nil {
 		return err
 	}
@@ -583,22 +846,21 @@ func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionSto
 		return err
 	}
 
-	// store the request features
-	for _, v := range s.Filters.Variables {
-		var typ string
-		// ignore the index field
-		if v == model.D3MIndexFieldName {
-			continue
+	// store the target features first so the request keeps their order
+	for _, v := range s.TargetFeatures {
+		err = solutionStorage.PersistRequestFeature(requestID, v, model.FeatureTypeTarget)
+		if err != nil {
+			return err
 		}
+	}
 
-		if v == s.TargetFeature {
-			// store target feature
-			typ = model.FeatureTypeTarget
-		} else {
-			// store training feature
-			typ = model.FeatureTypeTrain
+	// store the training features
+	for _, v := range s.Filters.Variables {
+		// ignore the index field and the targets
+		if v == model.D3MIndexFieldName || s.isTargetFeature(v) {
+			continue
 		}
-		err = solutionStorage.PersistRequestFeature(requestID, v, typ)
+		err = solutionStorage.PersistRequestFeature(requestID, v, model.FeatureTypeTrain)
 		if err != nil {
 			return err
 		}
@@ -610,8 +872,27 @@ func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionSto
 		return err
 	}
 
+	// store the split so exported problems describe the data the solutions
+	// were trained on
+	if s.Split != nil {
+		split, err := json.Marshal(s.Split)
+		if err != nil {
+			return errors.Wrap(err, "unable to marshal request split")
+		}
+		err = solutionStorage.PersistRequestSplit(requestID, string(split))
+		if err != nil {
+			return err
+		}
+	}
+
+	// store what is needed to resume the request after a restart
+	err = s.persistResumeState(solutionStorage, requestID, dataset.Metadata.ID, datasetURITrain, datasetURITest)
+	if err != nil {
+		return err
+	}
+
 	// dispatch search request
-	go s.dispatchRequest(client, solutionStorage, dataStorage, requestID, dataset.Metadata.ID, datasetPathTrain, datasetPathTest)
+	go s.dispatchRequest(client, solutionStorage, dataStorage, requestID, dataset.Metadata.ID, datasetURITrain, datasetURITest)
 
 	return nil
 }
@@ -650,7 +931,8 @@ func CreateSearchSolutionRequest(allFeatures []*model.Variable,
 	metrics := DefaultMetrics(targetVariable.Type)
 
 	// create search solutions request
-	searchRequest, err := createSearchSolutionsRequest(columnIndex, preprocessingPipeline, sourceURI, userAgent, target, dataset, metrics, task, taskSubType, 600)
+	targets := createProblemTargets([]string{target}, []int{columnIndex})
+	searchRequest, err := createSearchSolutionsRequest(targets, preprocessingPipeline, sourceURI, userAgent, dataset, metrics, task, taskSubType, 600)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create search solution request")
 	}
@@ -0,0 +1,342 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/hashstructure"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SplitMethodHoldOut splits the rows at random into a single train and
+	// test set.
+	SplitMethodHoldOut = "holdOut"
+	// SplitMethodChronological trains on the earliest rows and tests on the
+	// latest ones.
+	SplitMethodChronological = "chronological"
+)
+
+var (
+	splitTimeLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+		"2006/01/02",
+		"01/02/2006",
+		"2006",
+	}
+)
+
+// SplitSpec describes how a dataset is split into train and test rows. Rows
+// are assigned at random using the seed so a split can be reproduced. When
+// stratified, each target value keeps the same train / test proportion. When
+// a time column is set, the earliest rows are used for training instead, and
+// a horizon holds out the rows of that many of the latest time values. A
+// series column splits every series on its own time values.
+type SplitSpec struct {
+	TrainRatio   float64 `json:"trainRatio"`
+	Seed         int64   `json:"seed"`
+	Stratify     bool    `json:"stratify"`
+	TimeColumn   string  `json:"timeColumn"`
+	SeriesColumn string  `json:"seriesColumn"`
+	Horizon      int     `json:"horizon"`
+}
+
+// NewDefaultSplitSpec returns the split used when a request does not specify
+// one.
+func NewDefaultSplitSpec() *SplitSpec {
+	return &SplitSpec{
+		TrainRatio: trainTestSplitThreshold,
+	}
+}
+
+// ParseSplitSpec parses a split encoded as JSON. Nil is returned when no split
+// is encoded, for the default split to be used.
+func ParseSplitSpec(data []byte) (*SplitSpec, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	split := &SplitSpec{}
+	err := json.Unmarshal(data, split)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse split")
+	}
+	return split, nil
+}
+
+// normalize fills in the defaults of unset fields and validates the spec.
+func (s *SplitSpec) normalize() (*SplitSpec, error) {
+	if s == nil {
+		return NewDefaultSplitSpec(), nil
+	}
+
+	normalized := *s
+	if normalized.TrainRatio == 0 {
+		normalized.TrainRatio = trainTestSplitThreshold
+	}
+	if normalized.TrainRatio <= 0 || normalized.TrainRatio >= 1 {
+		return nil, errors.Errorf("train ratio %v must be between 0 and 1", normalized.TrainRatio)
+	}
+	if normalized.Stratify && normalized.TimeColumn != "" {
+		return nil, errors.New("a split cannot be both stratified and chronological")
+	}
+	if normalized.Horizon < 0 {
+		return nil, errors.Errorf("horizon %d must not be negative", normalized.Horizon)
+	}
+	if normalized.Horizon > 0 && normalized.TimeColumn == "" {
+		return nil, errors.New("a horizon requires a time column")
+	}
+	if normalized.SeriesColumn != "" && normalized.TimeColumn == "" {
+		return nil, errors.New("a series column requires a time column")
+	}
+
+	return &normalized, nil
+}
+
+// hash identifies the split for a given target so different splits of the
+// same dataset are stored separately.
+func (s *SplitSpec) hash(target string) (uint64, error) {
+	if !s.Stratify {
+		target = ""
+	}
+	hash, err := hashstructure.Hash([]interface{}{*s, target}, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to generate hashcode for split")
+	}
+	return hash, nil
+}
+
+// DataSplits returns the problem representation of the split.
+func (s *SplitSpec) DataSplits() *ProblemPersistDataSplits {
+	split, err := s.normalize()
+	if err != nil {
+		split = NewDefaultSplitSpec()
+	}
+
+	method := SplitMethodHoldOut
+	if split.TimeColumn != "" {
+		method = SplitMethodChronological
+	}
+
+	return &ProblemPersistDataSplits{
+		Method:       method,
+		TestSize:     1 - split.TrainRatio,
+		Stratified:   split.Stratify,
+		NumRepeats:   0,
+		RandomSeed:   int(split.Seed),
+		TimeColumn:   split.TimeColumn,
+		SeriesColumn: split.SeriesColumn,
+		Horizon:      split.Horizon,
+	}
+}
+
+// assign flags the rows to use for training, leaving the rest for testing.
+func (s *SplitSpec) assign(header []string, rows [][]string, target string) ([]bool, error) {
+	if s.TimeColumn != "" {
+		colIndex, err := getSplitColumnIndex(header, s.TimeColumn)
+		if err != nil {
+			return nil, err
+		}
+		times, err := parseSplitTimes(rows, colIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		seriesIndex := -1
+		if s.SeriesColumn != "" {
+			seriesIndex, err = getSplitColumnIndex(header, s.SeriesColumn)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		train := make([]bool, len(rows))
+		for _, series := range groupSeries(rows, seriesIndex) {
+			if s.Horizon > 0 {
+				err = assignHorizon(times, series, s.Horizon, train)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				assignChronological(times, series, s.TrainRatio, train)
+			}
+		}
+		return train, nil
+	}
+
+	rng := rand.New(rand.NewSource(s.Seed))
+
+	if s.Stratify {
+		colIndex, err := getSplitColumnIndex(header, target)
+		if err != nil {
+			return nil, err
+		}
+
+		// split each target value separately, in the order first seen so the
+		// assignment is stable for a given seed
+		strata := make(map[string][]int)
+		values := make([]string, 0)
+		for i, row := range rows {
+			value := row[colIndex]
+			if strata[value] == nil {
+				values = append(values, value)
+			}
+			strata[value] = append(strata[value], i)
+		}
+
+		train := make([]bool, len(rows))
+		for _, value := range values {
+			assignRandom(rng, strata[value], s.TrainRatio, train)
+		}
+		return train, nil
+	}
+
+	indices := make([]int, len(rows))
+	for i := range indices {
+		indices[i] = i
+	}
+	train := make([]bool, len(rows))
+	assignRandom(rng, indices, s.TrainRatio, train)
+
+	return train, nil
+}
+
+func assignRandom(rng *rand.Rand, indices []int, ratio float64, train []bool) {
+	shuffled := make([]int, len(indices))
+	copy(shuffled, indices)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	for _, index := range shuffled[:trainCount(len(shuffled), ratio)] {
+		train[index] = true
+	}
+}
+
+// groupSeries returns the row indices of every series, in the order first
+// seen, all rows being a single series when there is no series column.
+func groupSeries(rows [][]string, seriesIndex int) [][]int {
+	indices := make(map[string]int)
+	series := make([][]int, 0)
+	for i, row := range rows {
+		value := ""
+		if seriesIndex >= 0 {
+			value = row[seriesIndex]
+		}
+		index, ok := indices[value]
+		if !ok {
+			index = len(series)
+			indices[value] = index
+			series = append(series, make([]int, 0))
+		}
+		series[index] = append(series[index], i)
+	}
+	return series
+}
+
+// assignChronological trains on the earliest rows of the series.
+func assignChronological(times []float64, series []int, ratio float64, train []bool) {
+	order := make([]int, len(series))
+	copy(order, series)
+	sort.SliceStable(order, func(i, j int) bool {
+		return times[order[i]] < times[order[j]]
+	})
+
+	for _, index := range order[:trainCount(len(order), ratio)] {
+		train[index] = true
+	}
+}
+
+// assignHorizon trains on the rows of the series before its latest horizon
+// time values, which are held out.
+func assignHorizon(times []float64, series []int, horizon int, train []bool) error {
+	distinct := make([]float64, 0)
+	seen := make(map[float64]bool)
+	for _, index := range series {
+		t := times[index]
+		if !seen[t] {
+			seen[t] = true
+			distinct = append(distinct, t)
+		}
+	}
+	if horizon >= len(distinct) {
+		return errors.Errorf("horizon %d leaves no time values to train on out of %d", horizon, len(distinct))
+	}
+	sort.Float64s(distinct)
+	cutoff := distinct[len(distinct)-horizon]
+
+	for _, index := range series {
+		train[index] = times[index] < cutoff
+	}
+
+	return nil
+}
+
+func parseSplitTimes(rows [][]string, colIndex int) ([]float64, error) {
+	times := make([]float64, len(rows))
+	for i, row := range rows {
+		t, err := parseSplitTime(row[colIndex])
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse time of row %d", i)
+		}
+		times[i] = t
+	}
+	return times, nil
+}
+
+// trainCount returns the number of rows to train on, keeping at least one
+// row for testing whenever there is more than one row.
+func trainCount(count int, ratio float64) int {
+	n := int(math.Round(float64(count) * ratio))
+	if n >= count && count > 1 {
+		n = count - 1
+	}
+	return n
+}
+
+func parseSplitTime(value string) (float64, error) {
+	for _, layout := range splitTimeLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return float64(t.UnixNano()), nil
+		}
+	}
+
+	// fall back to numeric timestamps
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.Errorf("unrecognized time value `%s`", value)
+	}
+	return f, nil
+}
+
+func getSplitColumnIndex(header []string, name string) (int, error) {
+	for i, col := range header {
+		if col == name {
+			return i, nil
+		}
+	}
+	return -1, errors.Errorf("split column `%s` not found", name)
+}
@@ -0,0 +1,171 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func splitTestRows(count int) [][]string {
+	rows := make([][]string, count)
+	for i := range rows {
+		label := "common"
+		if i%10 == 0 {
+			label = "rare"
+		}
+		rows[i] = []string{fmt.Sprintf("%d", i), label, fmt.Sprintf("2019-01-%02d", count-i)}
+	}
+	return rows
+}
+
+func countTrain(train []bool) int {
+	count := 0
+	for _, t := range train {
+		if t {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSplitIsReproducible(t *testing.T) {
+	header := []string{"d3mIndex", "label", "date"}
+	rows := splitTestRows(30)
+
+	split, err := (&SplitSpec{TrainRatio: 0.8, Seed: 42}).normalize()
+	assert.NoError(t, err)
+
+	first, err := split.assign(header, rows, "label")
+	assert.NoError(t, err)
+	second, err := split.assign(header, rows, "label")
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 24, countTrain(first))
+}
+
+func TestSplitStratified(t *testing.T) {
+	header := []string{"d3mIndex", "label", "date"}
+	rows := splitTestRows(30)
+
+	split, err := (&SplitSpec{TrainRatio: 0.5, Seed: 7, Stratify: true}).normalize()
+	assert.NoError(t, err)
+
+	train, err := split.assign(header, rows, "label")
+	assert.NoError(t, err)
+
+	rare := 0
+	for i, row := range rows {
+		if train[i] && row[1] == "rare" {
+			rare++
+		}
+	}
+	assert.Equal(t, 2, rare)
+	assert.Equal(t, 16, countTrain(train))
+
+	_, err = split.assign(header, rows, "missing")
+	assert.Error(t, err)
+}
+
+func TestSplitChronological(t *testing.T) {
+	header := []string{"d3mIndex", "label", "date"}
+	rows := splitTestRows(10)
+
+	split, err := (&SplitSpec{TrainRatio: 0.7, TimeColumn: "date"}).normalize()
+	assert.NoError(t, err)
+
+	// dates decrease with the row index so the last rows are the earliest
+	train, err := split.assign(header, rows, "label")
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, false, false, true, true, true, true, true, true, true}, train)
+	assert.Equal(t, SplitMethodChronological, split.DataSplits().Method)
+}
+
+func TestSplitHorizon(t *testing.T) {
+	header := []string{"d3mIndex", "series", "date"}
+	rows := [][]string{
+		{"0", "a", "2019-01-01"},
+		{"1", "b", "2019-01-01"},
+		{"2", "a", "2019-01-02"},
+		{"3", "b", "2019-01-02"},
+		{"4", "a", "2019-01-03"},
+		{"5", "b", "2019-01-03"},
+	}
+
+	split, err := (&ForecastSpec{TimeColumn: "date", Horizon: 1}).split(nil)
+	assert.NoError(t, err)
+
+	// the latest date is held out for both series
+	train, err := split.assign(header, rows, "series")
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, true, true, false, false}, train)
+
+	_, err = (&SplitSpec{TimeColumn: "date", Horizon: 3}).assign(header, rows, "series")
+	assert.Error(t, err)
+
+	_, err = (&ForecastSpec{Horizon: 1}).split(nil)
+	assert.Error(t, err)
+}
+
+func TestSplitHorizonSeries(t *testing.T) {
+	header := []string{"d3mIndex", "series", "date"}
+	rows := [][]string{
+		{"0", "a", "2019-01-01"},
+		{"1", "a", "2019-01-02"},
+		{"2", "a", "2019-01-03"},
+		{"3", "b", "2019-01-05"},
+		{"4", "b", "2019-01-06"},
+		{"5", "b", "2019-01-07"},
+	}
+
+	// the latest date of each series is held out
+	split, err := (&ForecastSpec{TimeColumn: "date", SeriesColumn: "series", Horizon: 1}).split(nil)
+	assert.NoError(t, err)
+	train, err := split.assign(header, rows, "series")
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false, true, true, false}, train)
+
+	// without the series, only the latest date overall is held out
+	train, err = (&SplitSpec{TimeColumn: "date", Horizon: 1}).assign(header, rows, "series")
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, true, true, true, false}, train)
+
+	// the ratio applies to each series too
+	train, err = (&SplitSpec{TrainRatio: 0.6, TimeColumn: "date", SeriesColumn: "series"}).assign(header, rows, "series")
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false, true, true, false}, train)
+
+	_, err = (&SplitSpec{SeriesColumn: "series"}).normalize()
+	assert.Error(t, err)
+}
+
+func TestSplitSpecNormalize(t *testing.T) {
+	split, err := (*SplitSpec)(nil).normalize()
+	assert.NoError(t, err)
+	assert.Equal(t, trainTestSplitThreshold, split.TrainRatio)
+
+	_, err = (&SplitSpec{TrainRatio: 1.5}).normalize()
+	assert.Error(t, err)
+
+	_, err = (&SplitSpec{Stratify: true, TimeColumn: "date"}).normalize()
+	assert.Error(t, err)
+
+	_, err = (&SplitSpec{Horizon: 2}).normalize()
+	assert.Error(t, err)
+}
@@ -37,7 +37,11 @@ func NewStopSolutionSearchRequest(data []byte) (*StopSolutionSearchRequest, erro
 	return req, nil
 }
 
-// Dispatch dispatches the stop search request.
+// Dispatch dispatches the stop search request. Searches still queued are
+// identified by their queue ID and removed from the queue.
 func (s *StopSolutionSearchRequest) Dispatch(client *compute.Client) error {
+	if GetSearchScheduler().Cancel(s.RequestID) {
+		return nil
+	}
 	return client.StopSearch(context.Background(), s.RequestID)
 }
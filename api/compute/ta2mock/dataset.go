@@ -0,0 +1,117 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package ta2mock
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+const (
+	// FeatureName is the feature of the datasets written by WriteDataset.
+	FeatureName = "alpha"
+	// TargetName is the target of the datasets written by WriteDataset.
+	TargetName = "target"
+
+	datasetDoc = `{
+	"about": {
+		"datasetID": "%s",
+		"datasetName": "%s",
+		"datasetSchemaVersion": "3.0",
+		"redacted": false,
+		"datasetVersion": "1.0"
+	},
+	"dataResources": [{
+		"resID": "learningData",
+		"resPath": "tables/learningData.csv",
+		"resType": "table",
+		"resFormat": ["text/csv"],
+		"isCollection": false,
+		"columns": [
+			{"colIndex": 0, "colName": "d3mIndex", "colType": "integer", "role": ["index"]},
+			{"colIndex": 1, "colName": "alpha", "colType": "real", "role": ["attribute"]},
+			{"colIndex": 2, "colName": "target", "colType": "real", "role": ["suggestedTarget"]}
+		]
+	}]
+}`
+)
+
+// WriteDataset writes a D3M regression dataset of the given number of rows
+// under the folder, the target being a linear function of the feature. The
+// returned metadata references the dataset by its absolute path, so the
+// dataset resolves as is as long as no tmp path is configured.
+func WriteDataset(folder string, id string, rows int) (*api.Dataset, error) {
+	datasetFolder, err := filepath.Abs(path.Join(folder, id))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to resolve dataset folder")
+	}
+
+	err = os.MkdirAll(path.Join(datasetFolder, "tables"), os.ModePerm)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create dataset folder")
+	}
+
+	err = ioutil.WriteFile(path.Join(datasetFolder, "datasetDoc.json"), []byte(fmt.Sprintf(datasetDoc, id, id)), 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to write dataset schema")
+	}
+
+	lines := []string{fmt.Sprintf("%s,%s,%s", model.D3MIndexFieldName, FeatureName, TargetName)}
+	for i := 0; i < rows; i++ {
+		lines = append(lines, fmt.Sprintf("%d,%d.0,%d.0", i, i, 2*i+1))
+	}
+	err = ioutil.WriteFile(path.Join(datasetFolder, "tables", "learningData.csv"), []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to write dataset data")
+	}
+
+	return &api.Dataset{
+		ID:          id,
+		Name:        id,
+		StorageName: model.NormalizeDatasetID(id),
+		Folder:      datasetFolder,
+		NumRows:     int64(rows),
+		Variables: []*model.Variable{
+			{
+				Name:        model.D3MIndexFieldName,
+				DisplayName: model.D3MIndexFieldName,
+				Type:        model.IntegerType,
+				Index:       0,
+			},
+			{
+				Name:        FeatureName,
+				DisplayName: FeatureName,
+				Type:        model.FloatType,
+				Index:       1,
+			},
+			{
+				Name:        TargetName,
+				DisplayName: TargetName,
+				Type:        model.FloatType,
+				Index:       2,
+			},
+		},
+	}, nil
+}
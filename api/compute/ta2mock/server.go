@@ -0,0 +1,589 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package ta2mock provides an in-process stand in for a TA2 system along with
+// in memory storages, allowing the solution search flow to be tested without
+// any external service.
+package ta2mock
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/pipeline"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/uncharted-distil/distil-ingest/metadata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// UserAgent is the user agent reported by the mock server.
+	UserAgent = "distil-ta2-mock"
+
+	defaultSolutions = 2
+	exposedOutputKey = "outputs.0"
+)
+
+// Config controls the canned responses of the mock server.
+type Config struct {
+	// Solutions is the number of solutions found by each search.
+	Solutions int
+	// FailedSolutions is the number of solutions, counted from the last one
+	// of each search, that fail to be scored.
+	FailedSolutions int
+	// OutputDir is where produced results are written. A temporary folder is
+	// used when empty.
+	OutputDir string
+}
+
+type search struct {
+	id        string
+	request   *pipeline.SearchSolutionsRequest
+	solutions []string
+	ended     bool
+}
+
+type solution struct {
+	id     string
+	index  int
+	search *search
+}
+
+// Server is a TA2 core service returning deterministic solutions, scores
+// and results. Solution i of a search scores 1 / (i + 1) on every requested
+// metric and its predictions are the target values of the produced dataset.
+// Searches without a problem, such as the ones executing a fully specified
+// pipeline, produce a copy of their input data. Calls not supported by the
+// mock return an unimplemented error.
+type Server struct {
+	config    *Config
+	server    *grpc.Server
+	outputDir string
+	removeDir bool
+	mu        *sync.Mutex
+	counter   int
+	searches  map[string]*search
+	solutions map[string]*solution
+	fitted    map[string]*solution
+	requests  map[string]interface{}
+	fits      []string
+}
+
+// NewServer instantiates a new mock TA2 server.
+func NewServer(config *Config) *Server {
+	if config == nil {
+		config = &Config{}
+	}
+	if config.Solutions == 0 {
+		config.Solutions = defaultSolutions
+	}
+	return &Server{
+		config:    config,
+		mu:        &sync.Mutex{},
+		searches:  make(map[string]*search),
+		solutions: make(map[string]*solution),
+		fitted:    make(map[string]*solution),
+		requests:  make(map[string]interface{}),
+	}
+}
+
+// Start serves the mock on a local port and returns its endpoint.
+func (s *Server) Start() (string, error) {
+	s.outputDir = s.config.OutputDir
+	if s.outputDir == "" {
+		dir, err := ioutil.TempDir("", "ta2mock")
+		if err != nil {
+			return "", errors.Wrap(err, "unable to create output folder")
+		}
+		s.outputDir = dir
+		s.removeDir = true
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", errors.Wrap(err, "unable to listen")
+	}
+
+	s.server = grpc.NewServer()
+	pipeline.RegisterCoreServer(s.server, s)
+	go s.server.Serve(listener)
+
+	return listener.Addr().String(), nil
+}
+
+// Stop shuts the mock down and removes the temporary results.
+func (s *Server) Stop() {
+	if s.server != nil {
+		s.server.Stop()
+	}
+	if s.removeDir {
+		os.RemoveAll(s.outputDir)
+	}
+}
+
+// FitDatasets returns the dataset URIs solutions were fitted on, in order.
+func (s *Server) FitDatasets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.fits...)
+}
+
+// EndedSearches returns the ids of the searches that were ended.
+func (s *Server) EndedSearches() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ended := make([]string, 0)
+	for id, search := range s.searches {
+		if search.ended {
+			ended = append(ended, id)
+		}
+	}
+	return ended
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.counter++
+	return fmt.Sprintf("%s-%d", prefix, s.counter)
+}
+
+func (s *Server) addRequest(request interface{}) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID("request")
+	s.requests[id] = request
+	return id
+}
+
+func (s *Server) getRequest(id string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request, ok := s.requests[id]
+	if !ok {
+		return nil, errors.Errorf("unknown request `%s`", id)
+	}
+	return request, nil
+}
+
+func (s *Server) getSolution(id string) (*solution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	solution, ok := s.solutions[id]
+	if !ok {
+		return nil, errors.Errorf("unknown solution `%s`", id)
+	}
+	return solution, nil
+}
+
+func (s *Server) getFittedSolution(id string) (*solution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	solution, ok := s.fitted[id]
+	if !ok {
+		return nil, errors.Errorf("unknown fitted solution `%s`", id)
+	}
+	return solution, nil
+}
+
+func progress(state pipeline.ProgressState) *pipeline.Progress {
+	return &pipeline.Progress{
+		State: state,
+	}
+}
+
+// Hello reports the mock identity.
+func (s *Server) Hello(ctx context.Context, req *pipeline.HelloRequest) (*pipeline.HelloResponse, error) {
+	return &pipeline.HelloResponse{
+		UserAgent: UserAgent,
+		Version:   compute.GetAPIVersion(),
+		AllowedValueTypes: []pipeline.ValueType{
+			pipeline.ValueType_RAW,
+			pipeline.ValueType_DATASET_URI,
+			pipeline.ValueType_CSV_URI,
+		},
+	}, nil
+}
+
+// SearchSolutions starts a search finding the configured number of solutions.
+func (s *Server) SearchSolutions(ctx context.Context, req *pipeline.SearchSolutionsRequest) (*pipeline.SearchSolutionsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search := &search{
+		id:      s.nextID("search"),
+		request: req,
+	}
+	for i := 0; i < s.config.Solutions; i++ {
+		solution := &solution{
+			id:     fmt.Sprintf("%s-solution-%d", search.id, i),
+			index:  i,
+			search: search,
+		}
+		s.solutions[solution.id] = solution
+		search.solutions = append(search.solutions, solution.id)
+	}
+	s.searches[search.id] = search
+
+	return &pipeline.SearchSolutionsResponse{
+		SearchId: search.id,
+	}, nil
+}
+
+// GetSearchSolutionsResults streams the solutions of a search.
+func (s *Server) GetSearchSolutionsResults(req *pipeline.GetSearchSolutionsResultsRequest, stream pipeline.Core_GetSearchSolutionsResultsServer) error {
+	s.mu.Lock()
+	search, ok := s.searches[req.SearchId]
+	s.mu.Unlock()
+	if !ok {
+		return errors.Errorf("unknown search `%s`", req.SearchId)
+	}
+
+	for i, solutionID := range search.solutions {
+		err := stream.Send(&pipeline.GetSearchSolutionsResultsResponse{
+			Progress:      progress(pipeline.ProgressState_RUNNING),
+			DoneTicks:     float32(i + 1),
+			AllTicks:      float32(len(search.solutions)),
+			SolutionId:    solutionID,
+			InternalScore: scoreSolution(i),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return stream.Send(&pipeline.GetSearchSolutionsResultsResponse{
+		Progress:  progress(pipeline.ProgressState_COMPLETED),
+		DoneTicks: float32(len(search.solutions)),
+		AllTicks:  float32(len(search.solutions)),
+	})
+}
+
+// StopSearchSolutions stops a search.
+func (s *Server) StopSearchSolutions(ctx context.Context, req *pipeline.StopSearchSolutionsRequest) (*pipeline.StopSearchSolutionsResponse, error) {
+	return &pipeline.StopSearchSolutionsResponse{}, nil
+}
+
+// EndSearchSolutions ends a search.
+func (s *Server) EndSearchSolutions(ctx context.Context, req *pipeline.EndSearchSolutionsRequest) (*pipeline.EndSearchSolutionsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.searches[req.SearchId]
+	if !ok {
+		return nil, errors.Errorf("unknown search `%s`", req.SearchId)
+	}
+	search.ended = true
+
+	return &pipeline.EndSearchSolutionsResponse{}, nil
+}
+
+// DescribeSolution returns the template of the search of a solution.
+func (s *Server) DescribeSolution(ctx context.Context, req *pipeline.DescribeSolutionRequest) (*pipeline.DescribeSolutionResponse, error) {
+	solution, err := s.getSolution(req.SolutionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pipeline.DescribeSolutionResponse{
+		Pipeline: solution.search.request.Template,
+	}, nil
+}
+
+// ScoreSolution starts scoring a solution.
+func (s *Server) ScoreSolution(ctx context.Context, req *pipeline.ScoreSolutionRequest) (*pipeline.ScoreSolutionResponse, error) {
+	solution, err := s.getSolution(req.SolutionId)
+	if err != nil {
+		return nil, err
+	}
+	if solution.index >= s.config.Solutions-s.config.FailedSolutions {
+		return nil, errors.Errorf("solution `%s` failed to score", solution.id)
+	}
+
+	return &pipeline.ScoreSolutionResponse{
+		RequestId: s.addRequest(req),
+	}, nil
+}
+
+// GetScoreSolutionResults streams the scores of a solution.
+func (s *Server) GetScoreSolutionResults(req *pipeline.GetScoreSolutionResultsRequest, stream pipeline.Core_GetScoreSolutionResultsServer) error {
+	request, err := s.getRequest(req.RequestId)
+	if err != nil {
+		return err
+	}
+	scoreRequest, ok := request.(*pipeline.ScoreSolutionRequest)
+	if !ok {
+		return errors.Errorf("request `%s` is not a score request", req.RequestId)
+	}
+	solution, err := s.getSolution(scoreRequest.SolutionId)
+	if err != nil {
+		return err
+	}
+
+	scores := make([]*pipeline.Score, 0)
+	for _, metric := range scoreRequest.PerformanceMetrics {
+		scores = append(scores, &pipeline.Score{
+			Metric: metric,
+			Value: &pipeline.Value{
+				Value: &pipeline.Value_Raw{
+					Raw: &pipeline.ValueRaw{
+						Raw: &pipeline.ValueRaw_Double{
+							Double: scoreSolution(solution.index),
+						},
+					},
+				},
+			},
+		})
+	}
+
+	err = stream.Send(&pipeline.GetScoreSolutionResultsResponse{
+		Progress: progress(pipeline.ProgressState_RUNNING),
+	})
+	if err != nil {
+		return err
+	}
+	return stream.Send(&pipeline.GetScoreSolutionResultsResponse{
+		Progress: progress(pipeline.ProgressState_COMPLETED),
+		Scores:   scores,
+	})
+}
+
+// FitSolution starts fitting a solution.
+func (s *Server) FitSolution(ctx context.Context, req *pipeline.FitSolutionRequest) (*pipeline.FitSolutionResponse, error) {
+	_, err := s.getSolution(req.SolutionId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	for _, input := range req.Inputs {
+		s.fits = append(s.fits, input.GetDatasetUri())
+	}
+	s.mu.Unlock()
+
+	return &pipeline.FitSolutionResponse{
+		RequestId: s.addRequest(req),
+	}, nil
+}
+
+// GetFitSolutionResults streams the fitted solution of a fit request.
+func (s *Server) GetFitSolutionResults(req *pipeline.GetFitSolutionResultsRequest, stream pipeline.Core_GetFitSolutionResultsServer) error {
+	request, err := s.getRequest(req.RequestId)
+	if err != nil {
+		return err
+	}
+	fitRequest, ok := request.(*pipeline.FitSolutionRequest)
+	if !ok {
+		return errors.Errorf("request `%s` is not a fit request", req.RequestId)
+	}
+	solution, err := s.getSolution(fitRequest.SolutionId)
+	if err != nil {
+		return err
+	}
+
+	fittedSolutionID := fmt.Sprintf("fitted-%s", solution.id)
+	s.mu.Lock()
+	s.fitted[fittedSolutionID] = solution
+	s.mu.Unlock()
+
+	err = stream.Send(&pipeline.GetFitSolutionResultsResponse{
+		Progress: progress(pipeline.ProgressState_RUNNING),
+	})
+	if err != nil {
+		return err
+	}
+	return stream.Send(&pipeline.GetFitSolutionResultsResponse{
+		Progress:         progress(pipeline.ProgressState_COMPLETED),
+		FittedSolutionId: fittedSolutionID,
+	})
+}
+
+// ProduceSolution starts producing predictions with a fitted solution.
+func (s *Server) ProduceSolution(ctx context.Context, req *pipeline.ProduceSolutionRequest) (*pipeline.ProduceSolutionResponse, error) {
+	_, err := s.getFittedSolution(req.FittedSolutionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pipeline.ProduceSolutionResponse{
+		RequestId: s.addRequest(req),
+	}, nil
+}
+
+// GetProduceSolutionResults writes the produced results and streams their
+// location.
+func (s *Server) GetProduceSolutionResults(req *pipeline.GetProduceSolutionResultsRequest, stream pipeline.Core_GetProduceSolutionResultsServer) error {
+	request, err := s.getRequest(req.RequestId)
+	if err != nil {
+		return err
+	}
+	produceRequest, ok := request.(*pipeline.ProduceSolutionRequest)
+	if !ok {
+		return errors.Errorf("request `%s` is not a produce request", req.RequestId)
+	}
+	solution, err := s.getFittedSolution(produceRequest.FittedSolutionId)
+	if err != nil {
+		return err
+	}
+	if len(produceRequest.Inputs) == 0 {
+		return errors.New("produce request has no input")
+	}
+
+	resultFile := path.Join(s.outputDir, fmt.Sprintf("%s.csv", req.RequestId))
+	err = s.writeResult(resultFile, solution, produceRequest.Inputs[0].GetDatasetUri())
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&pipeline.GetProduceSolutionResultsResponse{
+		Progress: progress(pipeline.ProgressState_RUNNING),
+	})
+	if err != nil {
+		return err
+	}
+	return stream.Send(&pipeline.GetProduceSolutionResultsResponse{
+		Progress: progress(pipeline.ProgressState_COMPLETED),
+		ExposedOutputs: map[string]*pipeline.Value{
+			exposedOutputKey: {
+				Value: &pipeline.Value_CsvUri{
+					CsvUri: fmt.Sprintf("file://%s", resultFile),
+				},
+			},
+		},
+	})
+}
+
+// SolutionExport is not supported by the mock.
+func (s *Server) SolutionExport(ctx context.Context, req *pipeline.SolutionExportRequest) (*pipeline.SolutionExportResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "solution export not supported by the mock")
+}
+
+// ListPrimitives is not supported by the mock.
+func (s *Server) ListPrimitives(ctx context.Context, req *pipeline.ListPrimitivesRequest) (*pipeline.ListPrimitivesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "primitive listing not supported by the mock")
+}
+
+func (s *Server) writeResult(resultFile string, solution *solution, datasetURI string) error {
+	header, rows, err := readDataset(strings.Replace(datasetURI, "file://", "", 1))
+	if err != nil {
+		return err
+	}
+
+	// searches for a problem predict the targets, others echo their input
+	targets := getTargetNames(solution.search.request)
+	if len(targets) > 0 {
+		indexCol, err := getColumnIndex(header, "d3mIndex")
+		if err != nil {
+			return err
+		}
+		cols := []int{indexCol}
+		for _, target := range targets {
+			targetCol, err := getColumnIndex(header, target)
+			if err != nil {
+				return err
+			}
+			cols = append(cols, targetCol)
+		}
+
+		predictions := make([][]string, len(rows))
+		for i, row := range rows {
+			predictions[i] = make([]string, len(cols))
+			for j, col := range cols {
+				predictions[i][j] = row[col]
+			}
+		}
+		header = append([]string{"d3mIndex"}, targets...)
+		rows = predictions
+	}
+
+	file, err := os.Create(resultFile)
+	if err != nil {
+		return errors.Wrap(err, "unable to create result file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(header)
+	if err != nil {
+		return errors.Wrap(err, "unable to write result header")
+	}
+	err = writer.WriteAll(rows)
+	if err != nil {
+		return errors.Wrap(err, "unable to write results")
+	}
+
+	return nil
+}
+
+func getTargetNames(req *pipeline.SearchSolutionsRequest) []string {
+	targets := make([]string, 0)
+	if req.Problem == nil || len(req.Problem.Inputs) == 0 {
+		return targets
+	}
+	for _, target := range req.Problem.Inputs[0].Targets {
+		targets = append(targets, target.ColumnName)
+	}
+	return targets
+}
+
+func getColumnIndex(header []string, name string) (int, error) {
+	for i, col := range header {
+		if col == name {
+			return i, nil
+		}
+	}
+	return -1, errors.Errorf("column `%s` not found", name)
+}
+
+func readDataset(schemaFile string) ([]string, [][]string, error) {
+	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	mainDR := meta.GetMainDataResource()
+
+	file, err := os.Open(path.Join(path.Dir(schemaFile), mainDR.ResPath))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to open dataset")
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to read dataset")
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("dataset has no header")
+	}
+
+	return records[0], records[1:], nil
+}
+
+func scoreSolution(index int) float64 {
+	return 1 / float64(index+1)
+}
@@ -0,0 +1,494 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package ta2mock
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// The storages below keep only what the solution search flow writes and
+// reads. Calls outside of that flow panic.
+
+// MetadataStorage serves the metadata of a fixed set of datasets.
+type MetadataStorage struct {
+	api.MetadataStorage
+
+	datasets []*api.Dataset
+}
+
+// NewMetadataStorage returns a metadata storage serving the datasets.
+func NewMetadataStorage(datasets ...*api.Dataset) *MetadataStorage {
+	return &MetadataStorage{
+		datasets: datasets,
+	}
+}
+
+// FetchDatasets returns all datasets.
+func (s *MetadataStorage) FetchDatasets(includeIndex bool, includeMeta bool) ([]*api.Dataset, error) {
+	return s.datasets, nil
+}
+
+// FetchDataset returns a dataset.
+func (s *MetadataStorage) FetchDataset(dataset string, includeIndex bool, includeMeta bool) (*api.Dataset, error) {
+	for _, ds := range s.datasets {
+		if ds.ID == dataset {
+			return ds, nil
+		}
+	}
+	return nil, errors.Errorf("dataset `%s` not found", dataset)
+}
+
+// DeleteDataset removes a dataset.
+func (s *MetadataStorage) DeleteDataset(dataset string) error {
+	datasets := make([]*api.Dataset, 0)
+	for _, ds := range s.datasets {
+		if ds.ID != dataset {
+			datasets = append(datasets, ds)
+		}
+	}
+	s.datasets = datasets
+	return nil
+}
+
+// FetchVariables returns the variables of a dataset.
+func (s *MetadataStorage) FetchVariables(dataset string, includeIndex bool, includeMeta bool) ([]*model.Variable, error) {
+	ds, err := s.FetchDataset(dataset, includeIndex, includeMeta)
+	if err != nil {
+		return nil, err
+	}
+	return ds.Variables, nil
+}
+
+// FetchVariable returns a variable of a dataset.
+func (s *MetadataStorage) FetchVariable(dataset string, varName string) (*model.Variable, error) {
+	variables, err := s.FetchVariables(dataset, true, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range variables {
+		if v.Name == varName {
+			return v, nil
+		}
+	}
+	return nil, errors.Errorf("variable `%s` not found in dataset `%s`", varName, dataset)
+}
+
+// DataStorage records the results persisted by the solutions.
+type DataStorage struct {
+	api.DataStorage
+
+	mu      *sync.Mutex
+	results []string
+}
+
+// NewDataStorage returns an empty data storage.
+func NewDataStorage() *DataStorage {
+	return &DataStorage{
+		mu: &sync.Mutex{},
+	}
+}
+
+// FetchData returns no rows, the solution search only needs the dataset
+// metadata.
+func (s *DataStorage) FetchData(dataset string, storageName string, filterParams *api.FilterParams, invert bool) (*api.FilteredData, error) {
+	return &api.FilteredData{
+		Columns: []api.Column{},
+		Values:  [][]interface{}{},
+	}, nil
+}
+
+// PersistResult records the result.
+func (s *DataStorage) PersistResult(dataset string, storageName string, resultURI string, targets []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, resultURI)
+	return nil
+}
+
+// FetchResultQualityStats is not supported, solution checks are skipped.
+func (s *DataStorage) FetchResultQualityStats(dataset string, storageName string, resultURI string, target string) (*api.ResultQualityStats, error) {
+	return nil, errors.New("result quality stats are not available in memory")
+}
+
+// FetchDatasetTables returns no tables, datasets are not stored in memory.
+func (s *DataStorage) FetchDatasetTables(storageName string) ([]string, error) {
+	return []string{}, nil
+}
+
+// DeleteDataset has nothing to remove.
+func (s *DataStorage) DeleteDataset(storageName string) error {
+	return nil
+}
+
+// Results returns the uris of the persisted results.
+func (s *DataStorage) Results() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.results...)
+}
+
+type solutionRecord struct {
+	requestID string
+	progress  []string
+	scores    []*api.SolutionScore
+	flags     []*api.SolutionFlag
+	result    *api.SolutionResult
+}
+
+// SolutionStorage keeps requests and solutions in memory.
+type SolutionStorage struct {
+	api.SolutionStorage
+
+	mu        *sync.Mutex
+	requests  map[string]*api.Request
+	states    map[string]*api.RequestState
+	solutions map[string]*solutionRecord
+	baselines map[string][]*api.BaselineScore
+}
+
+// NewSolutionStorage returns an empty solution storage.
+func NewSolutionStorage() *SolutionStorage {
+	return &SolutionStorage{
+		mu:        &sync.Mutex{},
+		requests:  make(map[string]*api.Request),
+		states:    make(map[string]*api.RequestState),
+		solutions: make(map[string]*solutionRecord),
+		baselines: make(map[string][]*api.BaselineScore),
+	}
+}
+
+func (s *SolutionStorage) getRequest(requestID string) *api.Request {
+	req, ok := s.requests[requestID]
+	if !ok {
+		req = &api.Request{
+			RequestID: requestID,
+		}
+		s.requests[requestID] = req
+	}
+	return req
+}
+
+func (s *SolutionStorage) getSolution(solutionID string) *solutionRecord {
+	sol, ok := s.solutions[solutionID]
+	if !ok {
+		sol = &solutionRecord{}
+		s.solutions[solutionID] = sol
+	}
+	return sol
+}
+
+// PersistRequest records the progress of a request.
+func (s *SolutionStorage) PersistRequest(requestID string, dataset string, progress string, createdTime time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req := s.getRequest(requestID)
+	req.Dataset = dataset
+	req.Progress = progress
+	req.CreatedTime = createdTime
+	return nil
+}
+
+// UpdateRequest records the progress of a request.
+func (s *SolutionStorage) UpdateRequest(requestID string, progress string, updatedTime time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req := s.getRequest(requestID)
+	req.Progress = progress
+	req.LastUpdatedTime = updatedTime
+	return nil
+}
+
+// PersistRequestFeature records a feature of a request.
+func (s *SolutionStorage) PersistRequestFeature(requestID string, featureName string, featureType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req := s.getRequest(requestID)
+	req.Features = append(req.Features, &api.Feature{
+		RequestID:   requestID,
+		FeatureName: featureName,
+		FeatureType: featureType,
+	})
+	return nil
+}
+
+// PersistRequestFilters records the filters of a request.
+func (s *SolutionStorage) PersistRequestFilters(requestID string, filters *api.FilterParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.getRequest(requestID).Filters = filters
+	return nil
+}
+
+// PersistRequestSplit records the split of a request.
+func (s *SolutionStorage) PersistRequestSplit(requestID string, split string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.getRequest(requestID).Split = []byte(split)
+	return nil
+}
+
+// PersistRequestState records the resume state of a request.
+func (s *SolutionStorage) PersistRequestState(requestID string, resumeState string, errorReason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[requestID] = &api.RequestState{
+		RequestID:   requestID,
+		ResumeState: resumeState,
+		ErrorReason: errorReason,
+	}
+	return nil
+}
+
+// FetchRequestState returns the resume state of a request.
+func (s *SolutionStorage) FetchRequestState(requestID string) (*api.RequestState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.states[requestID], nil
+}
+
+// FetchResumableRequests returns the requests whose progress is one of the
+// listed ones.
+func (s *SolutionStorage) FetchResumableRequests(progress []string) ([]*api.Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]*api.Request, 0)
+	for _, req := range s.requests {
+		for _, p := range progress {
+			if req.Progress == p {
+				fetched := *req
+				requests = append(requests, &fetched)
+				break
+			}
+		}
+	}
+	return requests, nil
+}
+
+// FetchRequest returns a request along with its solutions.
+func (s *SolutionStorage) FetchRequest(requestID string) (*api.Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, ok := s.requests[requestID]
+	if !ok {
+		return nil, errors.Errorf("request `%s` not found", requestID)
+	}
+
+	fetched := *req
+	fetched.Solutions = make([]*api.Solution, 0)
+	for _, solutionID := range s.solutionIDs(requestID) {
+		fetched.Solutions = append(fetched.Solutions, s.buildSolution(solutionID))
+	}
+	return &fetched, nil
+}
+
+// FetchRequestBySolutionID returns the request of a solution.
+func (s *SolutionStorage) FetchRequestBySolutionID(solutionID string) (*api.Request, error) {
+	s.mu.Lock()
+	sol, ok := s.solutions[solutionID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, errors.Errorf("solution `%s` not found", solutionID)
+	}
+
+	return s.FetchRequest(sol.requestID)
+}
+
+// PersistSolution records the progress of a solution.
+func (s *SolutionStorage) PersistSolution(requestID string, solutionID string, progress string, createdTime time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sol := s.getSolution(solutionID)
+	sol.requestID = requestID
+	sol.progress = append(sol.progress, progress)
+	return nil
+}
+
+// PersistSolutionResult records the result of a solution.
+func (s *SolutionStorage) PersistSolutionResult(solutionID string, fittedSolutionID, resultUUID string, resultURI string, progress string, createdTime time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.getSolution(solutionID).result = &api.SolutionResult{
+		SolutionID:       solutionID,
+		FittedSolutionID: fittedSolutionID,
+		ResultUUID:       resultUUID,
+		ResultURI:        resultURI,
+		Progress:         progress,
+		CreatedTime:      createdTime,
+	}
+	return nil
+}
+
+// PersistSolutionScore records a held out score of a solution.
+func (s *SolutionStorage) PersistSolutionScore(solutionID string, metric string, score float64) error {
+	return s.PersistSolutionFoldScore(solutionID, metric, api.ScoreTypeHoldOut, 0, score)
+}
+
+// PersistSolutionFoldScore records a score of a solution.
+func (s *SolutionStorage) PersistSolutionFoldScore(solutionID string, metric string, scoreType string, fold int, score float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sol := s.getSolution(solutionID)
+	sol.scores = append(sol.scores, &api.SolutionScore{
+		SolutionID: solutionID,
+		Metric:     metric,
+		Score:      score,
+		Type:       scoreType,
+		Fold:       fold,
+	})
+	return nil
+}
+
+// PersistSolutionFlag records a failed quality check of a solution.
+func (s *SolutionStorage) PersistSolutionFlag(solutionID string, check string, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sol := s.getSolution(solutionID)
+	sol.flags = append(sol.flags, &api.SolutionFlag{
+		Check:  check,
+		Reason: reason,
+	})
+	return nil
+}
+
+// FetchSolution returns a solution.
+func (s *SolutionStorage) FetchSolution(solutionID string) (*api.Solution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.solutions[solutionID]; !ok {
+		return nil, errors.Errorf("solution `%s` not found", solutionID)
+	}
+	return s.buildSolution(solutionID), nil
+}
+
+// FetchSolutionIDsByProgress returns the solutions of a request whose latest
+// progress is one of the listed ones.
+func (s *SolutionStorage) FetchSolutionIDsByProgress(requestID string, progress []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	solutionIDs := make([]string, 0)
+	for _, solutionID := range s.solutionIDs(requestID) {
+		latest := s.buildSolution(solutionID).Progress
+		for _, p := range progress {
+			if latest == p {
+				solutionIDs = append(solutionIDs, solutionID)
+				break
+			}
+		}
+	}
+	return solutionIDs, nil
+}
+
+// PersistBaselineScore records a baseline score of a request.
+func (s *SolutionStorage) PersistBaselineScore(requestID string, baseline string, metric string, score float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.baselines[requestID] = append(s.baselines[requestID], &api.BaselineScore{
+		RequestID: requestID,
+		Baseline:  baseline,
+		Metric:    metric,
+		Score:     score,
+	})
+	return nil
+}
+
+// FetchBaselineScores returns the baseline scores of a request.
+func (s *SolutionStorage) FetchBaselineScores(requestID string) ([]*api.BaselineScore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*api.BaselineScore{}, s.baselines[requestID]...), nil
+}
+
+// FetchRequestIDsByDataset returns the ids of the requests made against the
+// dataset.
+func (s *SolutionStorage) FetchRequestIDsByDataset(dataset string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requestIDs := make([]string, 0)
+	for requestID, req := range s.requests {
+		if req.Dataset == dataset {
+			requestIDs = append(requestIDs, requestID)
+		}
+	}
+	sort.Strings(requestIDs)
+	return requestIDs, nil
+}
+
+// DeleteRequest removes a request along with its solutions.
+func (s *SolutionStorage) DeleteRequest(requestID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, solutionID := range s.solutionIDs(requestID) {
+		delete(s.solutions, solutionID)
+	}
+	delete(s.requests, requestID)
+	delete(s.states, requestID)
+	delete(s.baselines, requestID)
+	return nil
+}
+
+func (s *SolutionStorage) solutionIDs(requestID string) []string {
+	solutionIDs := make([]string, 0)
+	for solutionID, sol := range s.solutions {
+		if sol.requestID == requestID {
+			solutionIDs = append(solutionIDs, solutionID)
+		}
+	}
+	sort.Strings(solutionIDs)
+	return solutionIDs
+}
+
+func (s *SolutionStorage) buildSolution(solutionID string) *api.Solution {
+	sol := s.solutions[solutionID]
+	progress := ""
+	if len(sol.progress) > 0 {
+		progress = sol.progress[len(sol.progress)-1]
+	}
+	return &api.Solution{
+		RequestID:  sol.requestID,
+		SolutionID: solutionID,
+		Progress:   progress,
+		Result:     sol.result,
+		Scores:     append([]*api.SolutionScore{}, sol.scores...),
+		Flags:      append([]*api.SolutionFlag{}, sol.flags...),
+		IsBad:      len(sol.flags) > 0,
+	}
+}
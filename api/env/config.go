@@ -36,6 +36,12 @@ type Config struct {
 	SolutionComputePullTimeout         int     `env:"SOLUTION_COMPUTE_PULL_TIMEOUT" envDefault:"60"`
 	SolutionComputePullMax             int     `env:"SOLUTION_COMPUTE_PULL_MAX" envDefault:"10"`
 	SolutionSearchMaxTime              int     `env:"SOLUTION_SEARCH_MAX_TIME" envDefault:"10"`
+	SolutionSearchMaxConcurrent        int     `env:"SOLUTION_SEARCH_MAX_CONCURRENT" envDefault:"4"`
+	SolutionSearchMaxPriority          int     `env:"SOLUTION_SEARCH_MAX_PRIORITY" envDefault:"0"`
+	SolutionSearchUserBudget           int     `env:"SOLUTION_SEARCH_USER_BUDGET" envDefault:"0"`
+	SolutionSearchGlobalBudget         int     `env:"SOLUTION_SEARCH_GLOBAL_BUDGET" envDefault:"0"`
+	SolutionSearchBudgetWindow         int     `env:"SOLUTION_SEARCH_BUDGET_WINDOW" envDefault:"60"`
+	SolutionSearchUserHeader           string  `env:"SOLUTION_SEARCH_USER_HEADER" envDefault:""`
 	AugmentedSubFolder                 string  `env:"AUGMENTED_SUBFOLDER" envDefault:"augmented"`
 	D3MInputDir                        string  `env:"D3MINPUTDIR" envDefault:""`
 	D3MInputDirRoot                    string  `env:"D3MINPUTDIR_ROOT" envDefault:"datasets"`
@@ -79,14 +85,23 @@ type Config struct {
 	ElasticDatasetPrefix               string  `env:"ES_DATASET_PREFIX" envDefault:"d_"`
 	InitialDataset                     string  `env:"INITIAL_DATASET" envDefault:""`
 	ESDatasetsIndex                    string  `env:"ES_DATASETS_INDEX" envDefault:"datasets"`
+	MetadataStorage                    string  `env:"METADATA_STORAGE" envDefault:"elastic"`
 	UserProblemPath                    string  `env:"USER_PROBLEM_PATH" envDefault:"/outputs/problems"`
 	SkipIngest                         bool    `env:"SKIP_INGEST" envDefault:"false"`
+	MaxUploadSize                      int64   `env:"MAX_UPLOAD_SIZE" envDefault:"10737418240"`
+	MaxArchiveSize                     int64   `env:"MAX_ARCHIVE_SIZE" envDefault:"21474836480"`
+	MaxArchiveEntries                  int     `env:"MAX_ARCHIVE_ENTRIES" envDefault:"100000"`
 	IngestHardFail                     bool    `env:"INGEST_HARD_FAIL" envDefault:"false"`
+	IngestResumeEnabled                bool    `env:"INGEST_RESUME_ENABLED" envDefault:"true"`
+	IngestRestageFrom                  string  `env:"INGEST_RESTAGE_FROM" envDefault:""`
 	ServiceRetryCount                  int     `env:"SERVICE_RETRY_COUNT" envDefault:"10"`
 	VerboseError                       bool    `env:"VERBOSE_ERROR" envDefault:"false"`
 	IsTask1                            bool    `env:"TASK1" envDefault:"false"`
 	IsTask2                            bool    `env:"TASK2" envDefault:"false"`
 	SkipPreprocessing                  bool    `env:"SKIP_PREPROCESSING" envDefault:"false"`
+	EvaluationMode                     bool    `env:"EVALUATION_MODE" envDefault:"false"`
+	SolutionChecks                     string  `env:"SOLUTION_CHECKS" envDefault:"rangeBlowUp,constantPredictions,worseThanBaseline,missingPredictions"`
+	SolutionCheckStdDevs               float64 `env:"SOLUTION_CHECK_STD_DEVS" envDefault:"10"`
 }
 
 // LoadConfig loads the config from the environment if necessary and returns a
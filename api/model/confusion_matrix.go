@@ -0,0 +1,117 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"sort"
+)
+
+// ConfusionMatrix represents the counts of the predicted classes of a result
+// against the actual classes, along with a per class report. Counts are
+// indexed by actual class and then by predicted class.
+type ConfusionMatrix struct {
+	Key        string         `json:"key"`
+	Label      string         `json:"label"`
+	SolutionID string         `json:"solutionId"`
+	Classes    []string       `json:"classes"`
+	Counts     [][]int64      `json:"counts"`
+	Report     []*ClassReport `json:"report"`
+	Accuracy   float64        `json:"accuracy"`
+	Total      int64          `json:"total"`
+}
+
+// ClassReport represents the classification metrics of a single class.
+type ClassReport struct {
+	Class     string  `json:"class"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Support   int64   `json:"support"`
+}
+
+// NewConfusionMatrix builds a confusion matrix from the counts of each
+// actual and predicted class pair. Classes are sorted, and metrics that are
+// undefined for a class are reported as 0.
+func NewConfusionMatrix(counts map[string]map[string]int64) *ConfusionMatrix {
+	classSet := make(map[string]bool)
+	for actual, predictedCounts := range counts {
+		classSet[actual] = true
+		for predicted := range predictedCounts {
+			classSet[predicted] = true
+		}
+	}
+	classes := make([]string, 0, len(classSet))
+	for class := range classSet {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	matrix := make([][]int64, len(classes))
+	predictedTotals := make([]int64, len(classes))
+	actualTotals := make([]int64, len(classes))
+	total := int64(0)
+	correct := int64(0)
+	for i, actual := range classes {
+		matrix[i] = make([]int64, len(classes))
+		for j, predicted := range classes {
+			count := counts[actual][predicted]
+			matrix[i][j] = count
+			actualTotals[i] += count
+			predictedTotals[j] += count
+			total += count
+			if i == j {
+				correct += count
+			}
+		}
+	}
+
+	report := make([]*ClassReport, len(classes))
+	for i, class := range classes {
+		truePositives := float64(matrix[i][i])
+		precision := 0.0
+		if predictedTotals[i] > 0 {
+			precision = truePositives / float64(predictedTotals[i])
+		}
+		recall := 0.0
+		if actualTotals[i] > 0 {
+			recall = truePositives / float64(actualTotals[i])
+		}
+		f1 := 0.0
+		if precision+recall > 0 {
+			f1 = 2 * precision * recall / (precision + recall)
+		}
+		report[i] = &ClassReport{
+			Class:     class,
+			Precision: precision,
+			Recall:    recall,
+			F1:        f1,
+			Support:   actualTotals[i],
+		}
+	}
+
+	accuracy := 0.0
+	if total > 0 {
+		accuracy = float64(correct) / float64(total)
+	}
+
+	return &ConfusionMatrix{
+		Classes:  classes,
+		Counts:   matrix,
+		Report:   report,
+		Accuracy: accuracy,
+		Total:    total,
+	}
+}
@@ -0,0 +1,61 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfusionMatrix(t *testing.T) {
+	matrix := NewConfusionMatrix(map[string]map[string]int64{
+		"cat": {"cat": 5, "dog": 3},
+		"dog": {"cat": 2, "dog": 3},
+		"eel": {"dog": 2},
+	})
+
+	assert.Equal(t, []string{"cat", "dog", "eel"}, matrix.Classes)
+	assert.Equal(t, [][]int64{{5, 3, 0}, {2, 3, 0}, {0, 2, 0}}, matrix.Counts)
+	assert.Equal(t, int64(15), matrix.Total)
+	assert.InDelta(t, 8.0/15.0, matrix.Accuracy, epsilon)
+
+	cat := matrix.Report[0]
+	assert.Equal(t, "cat", cat.Class)
+	assert.Equal(t, int64(8), cat.Support)
+	assert.InDelta(t, 5.0/7.0, cat.Precision, epsilon)
+	assert.InDelta(t, 5.0/8.0, cat.Recall, epsilon)
+	assert.InDelta(t, 2.0/3.0, cat.F1, epsilon)
+
+	dog := matrix.Report[1]
+	assert.InDelta(t, 3.0/8.0, dog.Precision, epsilon)
+	assert.InDelta(t, 3.0/5.0, dog.Recall, epsilon)
+
+	// never predicted, so precision and f1 are undefined
+	eel := matrix.Report[2]
+	assert.Equal(t, int64(2), eel.Support)
+	assert.Equal(t, zero, eel.Precision)
+	assert.Equal(t, zero, eel.Recall)
+	assert.Equal(t, zero, eel.F1)
+}
+
+func TestNewConfusionMatrixEmpty(t *testing.T) {
+	matrix := NewConfusionMatrix(map[string]map[string]int64{})
+
+	assert.Empty(t, matrix.Classes)
+	assert.Equal(t, int64(0), matrix.Total)
+	assert.Equal(t, zero, matrix.Accuracy)
+}
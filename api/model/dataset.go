@@ -40,6 +40,9 @@ type Dataset struct {
 	NumBytes    int64                  `json:"numBytes"`
 	Provenance  string                 `json:"provenance"`
 	Source      metadata.DatasetSource `json:"source"`
+	Version     int                    `json:"version"`
+	Parent      string                 `json:"parent"`
+	Root        string                 `json:"root"`
 }
 
 // QueriedDataset wraps dataset querying components into a single entity.
@@ -90,3 +93,33 @@ func (d *Dataset) GetD3MIndexVariable() *model.Variable {
 
 	return nil
 }
+
+// GetRoot returns the id of the first version of the dataset.
+func (d *Dataset) GetRoot() string {
+	if d.Root != "" {
+		return d.Root
+	}
+	return d.ID
+}
+
+// FilterLatestVersions returns only the latest version of each dataset.
+func FilterLatestVersions(datasets []*Dataset) []*Dataset {
+	latest := make(map[string]*Dataset)
+	order := make([]string, 0)
+	for _, dataset := range datasets {
+		root := dataset.GetRoot()
+		existing, ok := latest[root]
+		if !ok {
+			order = append(order, root)
+		}
+		if !ok || dataset.Version > existing.Version {
+			latest[root] = dataset
+		}
+	}
+
+	filtered := make([]*Dataset, len(order))
+	for i, root := range order {
+		filtered[i] = latest[root]
+	}
+	return filtered
+}
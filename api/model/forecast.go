@@ -0,0 +1,75 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+// Forecast represents the forecasted series of a result along with the
+// actual series they are plotted against.
+type Forecast struct {
+	Key        string            `json:"key"`
+	SolutionID string            `json:"solutionId"`
+	Series     []*ForecastSeries `json:"series"`
+}
+
+// ForecastSeries represents the actual and forecasted values of a single
+// time series. Points are [time, value] pairs ordered by time.
+type ForecastSeries struct {
+	SeriesID string           `json:"seriesId"`
+	Actual   []*ForecastPoint `json:"actual"`
+	Forecast []*ForecastPoint `json:"forecast"`
+}
+
+// ForecastPoint represents the value of a series at a time.
+type ForecastPoint struct {
+	Time  string  `json:"time"`
+	Value float64 `json:"value"`
+}
+
+// ForecastRow represents a dataset row of a forecast, the predicted value
+// being nil for the rows that were not forecasted.
+type ForecastRow struct {
+	SeriesID  string
+	Time      string
+	Actual    *float64
+	Predicted *float64
+}
+
+// NewForecast groups the time ordered rows of a forecast by series, keeping
+// the series in the order they are first seen.
+func NewForecast(rows []*ForecastRow) *Forecast {
+	forecast := &Forecast{
+		Series: make([]*ForecastSeries, 0),
+	}
+	series := make(map[string]*ForecastSeries)
+	for _, row := range rows {
+		s, ok := series[row.SeriesID]
+		if !ok {
+			s = &ForecastSeries{
+				SeriesID: row.SeriesID,
+				Actual:   make([]*ForecastPoint, 0),
+				Forecast: make([]*ForecastPoint, 0),
+			}
+			series[row.SeriesID] = s
+			forecast.Series = append(forecast.Series, s)
+		}
+		if row.Actual != nil {
+			s.Actual = append(s.Actual, &ForecastPoint{Time: row.Time, Value: *row.Actual})
+		}
+		if row.Predicted != nil {
+			s.Forecast = append(s.Forecast, &ForecastPoint{Time: row.Time, Value: *row.Predicted})
+		}
+	}
+	return forecast
+}
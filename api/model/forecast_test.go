@@ -0,0 +1,44 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewForecast(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	forecast := NewForecast([]*ForecastRow{
+		{SeriesID: "b", Time: "1", Actual: value(10)},
+		{SeriesID: "b", Time: "2", Actual: value(12), Predicted: value(11)},
+		{SeriesID: "a", Time: "1", Actual: value(1)},
+		{SeriesID: "a", Time: "2", Predicted: value(2)},
+	})
+
+	assert.Len(t, forecast.Series, 2)
+
+	b := forecast.Series[0]
+	assert.Equal(t, "b", b.SeriesID)
+	assert.Equal(t, []*ForecastPoint{{Time: "1", Value: 10}, {Time: "2", Value: 12}}, b.Actual)
+	assert.Equal(t, []*ForecastPoint{{Time: "2", Value: 11}}, b.Forecast)
+
+	a := forecast.Series[1]
+	assert.Equal(t, "a", a.SeriesID)
+	assert.Equal(t, []*ForecastPoint{{Time: "1", Value: 1}}, a.Actual)
+	assert.Equal(t, []*ForecastPoint{{Time: "2", Value: 2}}, a.Forecast)
+}
@@ -16,6 +16,7 @@
 package model
 
 import (
+	"encoding/json"
 	"regexp"
 	"strings"
 	"time"
@@ -23,30 +24,60 @@ import (
 	"github.com/uncharted-distil/distil-compute/model"
 )
 
+const (
+	// ScoreTypeHoldOut is a score against the held out test data.
+	ScoreTypeHoldOut = "holdOut"
+	// ScoreTypeFold is a score against a single cross validation fold.
+	ScoreTypeFold = "fold"
+	// ScoreTypeFoldMean is the mean of the cross validation fold scores.
+	ScoreTypeFoldMean = "foldMean"
+	// ScoreTypeFoldStd is the standard deviation of the cross validation fold
+	// scores.
+	ScoreTypeFoldStd = "foldStd"
+
+	// MetricMeanAbsolutePercentageError is the mean of the absolute errors
+	// relative to the actual values. TA2 systems do not support it so it is
+	// scored from the persisted predictions, and stored under the same name
+	// it is requested by.
+	MetricMeanAbsolutePercentageError = "meanAbsolutePercentageError"
+)
+
 var (
-	suffixReg = regexp.MustCompile(`:\S+:error|:\S+:predicted$`)
+	suffixReg = regexp.MustCompile(`:\S+:error|:\S+:predicted$|:\S+:output$`)
 )
 
 // Request represents the request metadata.
 type Request struct {
-	RequestID       string        `json:"requestId"`
-	Dataset         string        `json:"dataset"`
-	Progress        string        `json:"progress"`
-	CreatedTime     time.Time     `json:"timestamp"`
-	LastUpdatedTime time.Time     `json:"lastUpdatedTime"`
-	Features        []*Feature    `json:"features"`
-	Filters         *FilterParams `json:"filters"`
-	Solutions       []*Solution   `json:"solutions"`
+	RequestID       string          `json:"requestId"`
+	Dataset         string          `json:"dataset"`
+	Progress        string          `json:"progress"`
+	CreatedTime     time.Time       `json:"timestamp"`
+	LastUpdatedTime time.Time       `json:"lastUpdatedTime"`
+	Features        []*Feature      `json:"features"`
+	Filters         *FilterParams   `json:"filters"`
+	Split           json.RawMessage `json:"split,omitempty"`
+	Solutions       []*Solution     `json:"solutions"`
 }
 
-// TargetFeature returns the target feature out of the feature set.
+// TargetFeature returns the target feature out of the feature set. Requests
+// with multiple targets return the first one.
 func (r *Request) TargetFeature() string {
+	targets := r.TargetFeatures()
+	if len(targets) == 0 {
+		return ""
+	}
+	return targets[0]
+}
+
+// TargetFeatures returns all the target features out of the feature set.
+func (r *Request) TargetFeatures() []string {
+	targets := make([]string, 0)
 	for _, f := range r.Features {
 		if f.FeatureType == model.FeatureTypeTarget {
-			return f.FeatureName
+			targets = append(targets, f.FeatureName)
 		}
 	}
-	return ""
+	return targets
 }
 
 // Feature represents a request feature metadata.
@@ -64,9 +95,35 @@ type Solution struct {
 	CreatedTime time.Time        `json:"timestamp"`
 	Result      *SolutionResult  `json:"result"`
 	Scores      []*SolutionScore `json:"scores"`
+	Flags       []*SolutionFlag  `json:"flags"`
 	IsBad       bool             `json:"isBad"`
 }
 
+// SolutionFlag represents a failed solution quality check.
+type SolutionFlag struct {
+	Check  string `json:"check"`
+	Reason string `json:"reason"`
+}
+
+// ResultQualityStats summarizes the predictions of a result against the
+// target values. Errors are mean absolute errors for numerical targets and
+// error rates for categorical targets, the baseline predicting the target
+// mean or the most frequent class. ExpectedRows is the number of rows that
+// should have been predicted and is set by the caller.
+type ResultQualityStats struct {
+	Numerical     bool
+	NumRows       int
+	NumPredicted  int
+	NumDistinct   int
+	ExpectedRows  int
+	PredictedMin  float64
+	PredictedMax  float64
+	TargetMean    float64
+	TargetStdDev  float64
+	Error         float64
+	BaselineError float64
+}
+
 // SolutionResult represents the solution result metadata.
 type SolutionResult struct {
 	FittedSolutionID string    `json:"fittedSolutionId"`
@@ -79,13 +136,47 @@ type SolutionResult struct {
 	CreatedTime      time.Time `json:"timestamp"`
 }
 
-// SolutionScore represents the result score data.
+// RequestState holds what is needed to resume a request after a restart,
+// and why it failed if it could not be.
+type RequestState struct {
+	RequestID   string `json:"requestId"`
+	ResumeState string `json:"-"`
+	ErrorReason string `json:"errorReason"`
+}
+
+// Prediction represents the predictions of a fitted solution on a dataset
+// other than the one it was trained on.
+type Prediction struct {
+	ResultUUID       string    `json:"resultId"`
+	SolutionID       string    `json:"solutionId"`
+	FittedSolutionID string    `json:"fittedSolutionId"`
+	Dataset          string    `json:"dataset"`
+	ResultURI        string    `json:"resultUri"`
+	Progress         string    `json:"progress"`
+	CreatedTime      time.Time `json:"timestamp"`
+}
+
+// SolutionScore represents the result score data. Cross validation scores
+// are stored per fold along with their mean and standard deviation.
 type SolutionScore struct {
 	SolutionID     string  `json:"solutionId"`
 	Metric         string  `json:"metric"`
 	Label          string  `json:"label"`
 	Score          float64 `json:"value"`
 	SortMultiplier float64 `json:"sortMultiplier"`
+	Type           string  `json:"type"`
+	Fold           int     `json:"fold"`
+}
+
+// BaselineScore represents the score of a trivial baseline model computed
+// on the train / test split of a request.
+type BaselineScore struct {
+	RequestID      string  `json:"requestId"`
+	Baseline       string  `json:"baseline"`
+	Metric         string  `json:"metric"`
+	Label          string  `json:"label"`
+	Score          float64 `json:"value"`
+	SortMultiplier float64 `json:"sortMultiplier"`
 }
 
 // GetPredictedKey returns a solutions predicted col key.
@@ -98,6 +189,12 @@ func GetErrorKey(target string, solutionID string) string {
 	return target + ":" + solutionID + ":error"
 }
 
+// GetOutputKey returns a solutions additional output col key, such as a
+// class probability or a confidence.
+func GetOutputKey(output string, solutionID string) string {
+	return output + ":" + solutionID + ":output"
+}
+
 // IsPredictedKey returns true if the key matches a predicted key.
 func IsPredictedKey(key string) bool {
 	return strings.HasSuffix(key, ":predicted")
@@ -108,6 +205,11 @@ func IsErrorKey(key string) bool {
 	return strings.HasSuffix(key, ":error")
 }
 
+// IsOutputKey returns true if the key matches an additional output key.
+func IsOutputKey(key string) bool {
+	return strings.HasSuffix(key, ":output")
+}
+
 // IsResultKey returns true if the key matches an predicted or error key.
 func IsResultKey(key string) bool {
 	return IsPredictedKey(key) || IsErrorKey(key)
@@ -31,21 +31,27 @@ type DataStorage interface {
 	FetchData(dataset string, storageName string, filterParams *FilterParams, invert bool) (*FilteredData, error)
 	FetchSummary(dataset string, storageName string, varName string, filterParams *FilterParams) (*Histogram, error)
 	FetchSummaryByResult(dataset string, storageName string, varName string, resultURI string, filterParams *FilterParams, extrema *Extrema) (*Histogram, error)
-	PersistResult(dataset string, storageName string, resultURI string, target string) error
+	PersistResult(dataset string, storageName string, resultURI string, targets []string) error
 	FetchResults(dataset string, storageName string, resultURI string, solutionID string, filterParams *FilterParams) (*FilteredData, error)
-	FetchPredictedSummary(dataset string, storageName string, resultURI string, filterParams *FilterParams, extrema *Extrema) (*Histogram, error)
-	FetchResultsExtremaByURI(dataset string, storageName string, resultURI string) (*Extrema, error)
-	FetchCorrectnessSummary(dataset string, storageName string, resultURI string, filterParams *FilterParams) (*Histogram, error)
-	FetchResidualsSummary(dataset string, storageName string, resultURI string, filterParams *FilterParams, extrema *Extrema) (*Histogram, error)
-	FetchResidualsExtremaByURI(dataset string, storageName string, resultURI string) (*Extrema, error)
+	FetchPredictedSummary(dataset string, storageName string, resultURI string, target string, filterParams *FilterParams, extrema *Extrema) (*Histogram, error)
+	FetchResultsExtremaByURI(dataset string, storageName string, resultURI string, target string) (*Extrema, error)
+	FetchCorrectnessSummary(dataset string, storageName string, resultURI string, target string, filterParams *FilterParams) (*Histogram, error)
+	FetchConfusionMatrix(dataset string, storageName string, resultURI string, target string, filterParams *FilterParams) (*ConfusionMatrix, error)
+	FetchForecast(dataset string, storageName string, resultURI string, target string, timeColumn string, seriesColumn string) (*Forecast, error)
+	FetchResultQualityStats(dataset string, storageName string, resultURI string, target string) (*ResultQualityStats, error)
+	FetchResultOutputSummary(dataset string, storageName string, resultURI string, output string, filterParams *FilterParams, extrema *Extrema) (*Histogram, error)
+	FetchResidualsSummary(dataset string, storageName string, resultURI string, target string, filterParams *FilterParams, extrema *Extrema) (*Histogram, error)
+	FetchResidualsExtremaByURI(dataset string, storageName string, resultURI string, target string) (*Extrema, error)
 	FetchExtremaByURI(dataset string, storageName string, resultURI string, variable string) (*Extrema, error)
 
 	// Dataset manipulation
 	SetDataType(dataset string, storageName string, varName string, varType string) error
-	AddVariable(dataset string, storageName string, varName string, varType string) error
+	AddVariable(dataset string, storageName string, varName string, varType string, varRole string) error
 	DeleteVariable(dataset string, storageName string, varName string) error
 	UpdateVariable(storageName string, varName string, d3mIndex string, value string) error
 	UpdateVariableBatch(storageName string, varName string, updates map[string]string) error
+	FetchDatasetTables(storageName string) ([]string, error)
+	DeleteDataset(storageName string) error
 }
 
 // SolutionStorageCtor represents a client constructor to instantiate a
@@ -58,9 +64,15 @@ type SolutionStorage interface {
 	PersistRequest(requestID string, dataset string, progress string, createdTime time.Time) error
 	PersistRequestFeature(requestID string, featureName string, featureType string) error
 	PersistRequestFilters(requestID string, filters *FilterParams) error
+	PersistRequestSplit(requestID string, split string) error
 	PersistSolution(requestID string, solutionID string, progress string, createdTime time.Time) error
 	PersistSolutionResult(solutionID string, fittedSolutionID, resultUUID string, resultURI string, progress string, createdTime time.Time) error
 	PersistSolutionScore(solutionID string, metric string, score float64) error
+	PersistSolutionFoldScore(solutionID string, metric string, scoreType string, fold int, score float64) error
+	PersistRequestState(requestID string, resumeState string, errorReason string) error
+	PersistSolutionFlag(solutionID string, check string, reason string) error
+	PersistBaselineScore(requestID string, baseline string, metric string, score float64) error
+	PersistPrediction(resultUUID string, solutionID string, fittedSolutionID string, dataset string, resultURI string, progress string, createdTime time.Time) error
 	UpdateRequest(requestID string, progress string, updatedTime time.Time) error
 	FetchRequest(requestID string) (*Request, error)
 	FetchRequestBySolutionID(requestID string) (*Request, error)
@@ -71,6 +83,14 @@ type SolutionStorage interface {
 	FetchSolutionResultByUUID(resultUUID string) (*SolutionResult, error)
 	FetchSolutionResult(solutionID string) (*SolutionResult, error)
 	FetchSolutionScores(solutionID string) ([]*SolutionScore, error)
+	FetchPrediction(resultUUID string) (*Prediction, error)
+	FetchRequestState(requestID string) (*RequestState, error)
+	FetchSolutionFlags(solutionID string) ([]*SolutionFlag, error)
+	FetchBaselineScores(requestID string) ([]*BaselineScore, error)
+	FetchResumableRequests(progress []string) ([]*Request, error)
+	FetchSolutionIDsByProgress(requestID string, progress []string) ([]string, error)
+	FetchRequestIDsByDataset(dataset string) ([]string, error)
+	DeleteRequest(requestID string) error
 }
 
 // MetadataStorageCtor represents a client constructor to instantiate a
@@ -94,4 +114,5 @@ type MetadataStorage interface {
 	SetDataType(dataset string, varName string, varType string) error
 	AddVariable(dataset string, varName string, varType string, varDistilRole string) error
 	DeleteVariable(dataset string, varName string) error
+	DeleteDataset(dataset string) error
 }
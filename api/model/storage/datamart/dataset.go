@@ -88,6 +88,11 @@ func (s *Storage) DeleteVariable(dataset string, varName string) error {
 	return errors.Errorf("Not supported")
 }
 
+// DeleteDataset is not supported by the datamart.
+func (s *Storage) DeleteDataset(dataset string) error {
+	return errors.Errorf("Not supported")
+}
+
 func (s *Storage) searchREST(searchText string) ([]*api.Dataset, error) {
 	terms := strings.Fields(searchText)
 
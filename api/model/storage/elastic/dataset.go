@@ -103,6 +103,19 @@ func (s *Storage) parseDatasets(res *elastic.SearchResult, includeIndex bool, in
 		if !ok {
 			source = string(metadata.Seed)
 		}
+		// extract the version lineage
+		version, ok := json.Int(src, "datasetVersion")
+		if !ok {
+			version = 1
+		}
+		parent, ok := json.String(src, "parentDataset")
+		if !ok {
+			parent = ""
+		}
+		root, ok := json.String(src, "rootDataset")
+		if !ok {
+			root = id
+		}
 
 		// write everythign out to result struct
 		datasets = append(datasets, &api.Dataset{
@@ -118,6 +131,9 @@ func (s *Storage) parseDatasets(res *elastic.SearchResult, includeIndex bool, in
 			Variables:   variables,
 			Provenance:  Provenance,
 			Source:      metadata.DatasetSource(source),
+			Version:     version,
+			Parent:      parent,
+			Root:        root,
 		})
 	}
 	return datasets, nil
@@ -154,6 +170,9 @@ func (s *Storage) FetchDataset(datasetName string, includeIndex bool, includeMet
 	if err != nil {
 		return nil, err
 	}
+	if len(datasets) == 0 {
+		return nil, errors.Errorf("dataset %s not found", datasetName)
+	}
 	return datasets[0], nil
 }
 
@@ -199,12 +218,14 @@ func (s *Storage) updateVariables(dataset string, variables []*model.Variable) e
 		model.Variables: serialized,
 	}
 
-	// push the document into the metadata index
+	// push the document into the metadata index, refreshing so the next
+	// read sees the change
 	_, err := s.client.Update().
 		Index(s.index).
 		Type(metadataType).
 		Id(dataset).
 		Doc(source).
+		Refresh("true").
 		Do(context.Background())
 	if err != nil {
 		return errors.Wrapf(err, "failed to add document to index `%s`", s.index)
@@ -271,3 +292,18 @@ func (s *Storage) DeleteVariable(dataset string, varName string) error {
 
 	return s.updateVariables(dataset, vars)
 }
+
+// DeleteDataset removes the dataset metadata document from the index.
+func (s *Storage) DeleteDataset(dataset string) error {
+	_, err := s.client.Delete().
+		Index(s.index).
+		Type(metadataType).
+		Id(dataset).
+		Refresh("true").
+		Do(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "unable to delete dataset %s metadata", dataset)
+	}
+
+	return nil
+}
@@ -80,6 +80,11 @@ func (s *Storage) DeleteVariable(dataset string, varName string) error {
 	return errors.Errorf("Not supported")
 }
 
+// DeleteDataset is not supported, the file storage is read only.
+func (s *Storage) DeleteDataset(dataset string) error {
+	return errors.Errorf("Not supported")
+}
+
 func (s *Storage) parseDatasets(raw []*model.Metadata) ([]*api.Dataset, error) {
 	datasets := make([]*api.Dataset, 0)
 
@@ -0,0 +1,62 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// PersistBaselineScore persists the score of a baseline model of a request to
+// Postgres.
+func (s *Storage) PersistBaselineScore(requestID string, baseline string, metric string, score float64) error {
+	sql := fmt.Sprintf("INSERT INTO %s (request_id, baseline, metric, score) VALUES ($1, $2, $3, $4);", baselineScoreTableName)
+
+	_, err := s.client.Exec(sql, requestID, baseline, metric, score)
+
+	return err
+}
+
+// FetchBaselineScores pulls the baseline model scores of a request from
+// Postgres.
+func (s *Storage) FetchBaselineScores(requestID string) ([]*api.BaselineScore, error) {
+	sql := fmt.Sprintf("SELECT request_id, baseline, metric, score FROM %s WHERE request_id = $1 ORDER BY baseline, metric;", baselineScoreTableName)
+
+	rows, err := s.client.Query(sql, requestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull baseline scores from Postgres")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	scores := make([]*api.BaselineScore, 0)
+	for rows.Next() {
+		score := &api.BaselineScore{}
+		err = rows.Scan(&score.RequestID, &score.Baseline, &score.Metric, &score.Score)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse baseline score from Postgres")
+		}
+		score.Label = getMetricLabel(score.Metric)
+		score.SortMultiplier = getMetricScoreMultiplier(score.Metric)
+		scores = append(scores, score)
+	}
+
+	return scores, nil
+}
@@ -85,8 +85,8 @@ func (f *CategoricalField) fetchHistogram(filterParams *api.FilterParams) (*api.
 	}
 
 	// Get count by category.
-	query := fmt.Sprintf("SELECT \"%s\", COUNT(*) AS count FROM %s %s GROUP BY \"%s\" ORDER BY count desc, \"%s\" LIMIT %d;",
-		f.Variable.Name, fromClause, where, f.Variable.Name, f.Variable.Name, catResultLimit)
+	query := fmt.Sprintf("SELECT %s, COUNT(*) AS count FROM %s %s GROUP BY %s ORDER BY count desc, %s LIMIT %d;",
+		quoteIdentifier(f.Variable.Name), fromClause, where, quoteIdentifier(f.Variable.Name), quoteIdentifier(f.Variable.Name), catResultLimit)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -104,7 +104,7 @@ func (f *CategoricalField) fetchHistogramByResult(resultURI string, filterParams
 	fromClause := f.getFromClause(false)
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, filterParams)
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, "", filterParams)
 	if err != nil {
 		return nil, err
 	}
@@ -118,14 +118,14 @@ func (f *CategoricalField) fetchHistogramByResult(resultURI string, filterParams
 
 	// Get count by category.
 	query := fmt.Sprintf(
-		`SELECT data."%s", COUNT(*) AS count
-		 FROM %s data INNER JOIN %s result ON data."%s" = result.index
+		`SELECT data.%s, COUNT(*) AS count
+		 FROM %s data INNER JOIN %s result ON data.%s = result.index
 		 WHERE result.result_id = $%d %s
-		 GROUP BY "%s"
-		 ORDER BY count desc, "%s" LIMIT %d;`,
-		f.Variable.Name, fromClause, f.Storage.getResultTable(f.StorageName),
-		model.D3MIndexFieldName, len(params), where, f.Variable.Name,
-		f.Variable.Name, catResultLimit)
+		 GROUP BY %s
+		 ORDER BY count desc, %s LIMIT %d;`,
+		quoteIdentifier(f.Variable.Name), fromClause, quoteIdentifier(f.Storage.getResultTable(f.StorageName)),
+		quoteIdentifier(model.D3MIndexFieldName), len(params), where, quoteIdentifier(f.Variable.Name),
+		quoteIdentifier(f.Variable.Name), catResultLimit)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -189,21 +189,21 @@ func (f *CategoricalField) FetchPredictedSummaryData(resultURI string, datasetRe
 	targetName := f.Variable.Name
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, filterParams)
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, targetName, filterParams)
 	if err != nil {
 		return nil, err
 	}
 
-	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d AND result.target = $%d ", len(params)+1, len(params)+2))
-	params = append(params, resultURI, targetName)
+	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d ", len(params)+1))
+	params = append(params, resultURI)
 
 	query := fmt.Sprintf(
 		`SELECT result.value, COUNT(*) AS count
-		 FROM %s AS result INNER JOIN %s AS data ON result.index = data."%s"
+		 FROM %s AS result INNER JOIN %s AS data ON result.index = data.%s
 		 WHERE %s
 		 GROUP BY result.value
 		 ORDER BY count desc;`,
-		datasetResult, f.StorageName, model.D3MIndexFieldName, strings.Join(wheres, " AND "))
+		quoteIdentifier(datasetResult), quoteIdentifier(f.StorageName), quoteIdentifier(model.D3MIndexFieldName), strings.Join(wheres, " AND "))
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -216,11 +216,11 @@ func (f *CategoricalField) FetchPredictedSummaryData(resultURI string, datasetRe
 }
 
 func (f *CategoricalField) getFromClause(alias bool) string {
-	fromClause := f.StorageName
+	fromClause := quoteIdentifier(f.StorageName)
 	if f.subSelect != nil {
 		fromClause = f.subSelect()
 		if alias {
-			fromClause = fmt.Sprintf("%s as %s", fromClause, f.StorageName)
+			fromClause = fmt.Sprintf("%s as %s", fromClause, quoteIdentifier(f.StorageName))
 		}
 	}
 
@@ -0,0 +1,40 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// FetchConfusionMatrix fetches the confusion matrix and per class report of a
+// set of categorical predictions.
+func (s *Storage) FetchConfusionMatrix(dataset string, storageName string, resultURI string, target string, filterParams *api.FilterParams) (*api.ConfusionMatrix, error) {
+	variable, counts, err := s.fetchResultTargetCounts(dataset, storageName, resultURI, target, filterParams)
+	if err != nil {
+		return nil, err
+	}
+	if !model.IsCategorical(variable.Type) {
+		return nil, errors.Errorf("confusion matrix requires a categorical target but `%s` is %s", variable.Name, variable.Type)
+	}
+
+	matrix := api.NewConfusionMatrix(counts)
+	matrix.Key = variable.Name
+	matrix.Label = variable.DisplayName
+
+	return matrix, nil
+}
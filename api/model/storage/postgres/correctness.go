@@ -20,74 +20,82 @@ import (
 	"math"
 	"strings"
 
-	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
 )
 
 // FetchCorrectnessSummary fetches a histogram of the residuals associated with a set of numerical predictions.
-func (s *Storage) FetchCorrectnessSummary(dataset string, storageName string, resultURI string, filterParams *api.FilterParams) (*api.Histogram, error) {
-	storageNameResult := s.getResultTable(storageName)
-	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+func (s *Storage) FetchCorrectnessSummary(dataset string, storageName string, resultURI string, target string, filterParams *api.FilterParams) (*api.Histogram, error) {
+	variable, counts, err := s.fetchResultTargetCounts(dataset, storageName, resultURI, target, filterParams)
 	if err != nil {
 		return nil, err
 	}
 
+	return s.parseHistogram(counts, variable)
+}
+
+// fetchResultTargetCounts counts the filtered rows of a result by actual
+// target value and predicted value.
+func (s *Storage) fetchResultTargetCounts(dataset string, storageName string, resultURI string, target string, filterParams *api.FilterParams) (*model.Variable, map[string]map[string]int64, error) {
+	storageNameResult := s.getResultTable(storageName)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	variable, err := s.getResultTargetVariable(dataset, targetName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// get filter where / params
-	wheres, params, err := s.buildResultQueryFilters(storageName, resultURI, filterParams)
+	wheres, params, err := s.buildResultQueryFilters(storageName, resultURI, targetName, filterParams)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d AND result.target = $%d ", len(params)+1, len(params)+2))
-	params = append(params, resultURI, targetName)
+	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d ", len(params)+1))
+	params = append(params, resultURI)
 
 	query := fmt.Sprintf(
-		`SELECT data."%s", result.value, COUNT(*) AS count
-		 FROM %s AS result INNER JOIN %s AS data ON result.index = data."%s"
+		`SELECT data.%s, result.value, COUNT(*) AS count
+		 FROM %s AS result INNER JOIN %s AS data ON result.index = data.%s
 		 WHERE %s
-		 GROUP BY result.value, data."%s"
+		 GROUP BY result.value, data.%s
 		 ORDER BY count desc;`,
-		targetName, storageNameResult, storageName, model.D3MIndexFieldName, strings.Join(wheres, " AND "), targetName)
+		quoteIdentifier(targetName), quoteIdentifier(storageNameResult), quoteIdentifier(storageName), quoteIdentifier(model.D3MIndexFieldName), strings.Join(wheres, " AND "), quoteIdentifier(targetName))
 
 	// execute the postgres query
 	res, err := s.client.Query(query, params...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to fetch histograms for result summaries from postgres")
+		return nil, nil, errors.Wrap(err, "failed to fetch histograms for result summaries from postgres")
 	}
 	defer res.Close()
 
-	return s.parseHistogram(res, variable)
-}
-
-func (s *Storage) parseHistogram(rows *pgx.Rows, variable *model.Variable) (*api.Histogram, error) {
-
 	termsAggName := api.TermsAggPrefix + variable.Name
 
 	// extract the counts
 	countMap := map[string]map[string]int64{}
-	if rows != nil {
-		for rows.Next() {
-			var predictedTerm string
-			var targetTerm string
-			var bucketCount int64
-			err := rows.Scan(&targetTerm, &predictedTerm, &bucketCount)
-			if err != nil {
-				return nil, errors.Wrap(err, fmt.Sprintf("no %s histogram aggregation found", termsAggName))
-			}
-			if len(countMap[predictedTerm]) == 0 {
-				countMap[predictedTerm] = map[string]int64{}
-			}
-			countMap[predictedTerm][targetTerm] = bucketCount
+	for res.Next() {
+		var predictedTerm string
+		var targetTerm string
+		var bucketCount int64
+		err := res.Scan(&targetTerm, &predictedTerm, &bucketCount)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, fmt.Sprintf("no %s histogram aggregation found", termsAggName))
 		}
+		if len(countMap[targetTerm]) == 0 {
+			countMap[targetTerm] = map[string]int64{}
+		}
+		countMap[targetTerm][predictedTerm] = bucketCount
 	}
 
+	return variable, countMap, nil
+}
+
+func (s *Storage) parseHistogram(countMap map[string]map[string]int64, variable *model.Variable) (*api.Histogram, error) {
+
 	correctBucket := &api.Bucket{
 		Key: "Correct",
 	}
@@ -95,8 +103,8 @@ func (s *Storage) parseHistogram(rows *pgx.Rows, variable *model.Variable) (*api
 		Key: "Incorrect",
 	}
 
-	for predictedKey, targetCounts := range countMap {
-		for targetKey, count := range targetCounts {
+	for targetKey, predictedCounts := range countMap {
+		for predictedKey, count := range predictedCounts {
 			if predictedKey == targetKey {
 				correctBucket.Count += count
 			} else {
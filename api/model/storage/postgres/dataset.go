@@ -19,8 +19,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
+	log "github.com/unchartedsoftware/plog"
 )
 
 const (
@@ -28,14 +30,26 @@ const (
 )
 
 func (s *Storage) getViewField(name string, displayName string, typ string, defaultValue interface{}) string {
-	return fmt.Sprintf("COALESCE(CAST(\"%s\" AS %s), %v) AS \"%s\"",
-		name, typ, defaultValue, displayName)
+	return fmt.Sprintf("COALESCE(CAST(%s AS %s), %v) AS %s",
+		quoteIdentifier(name), typ, defaultValue, quoteIdentifier(displayName))
 }
 
-func (s *Storage) getDatabaseFields(tableName string) ([]string, error) {
+func (s *Storage) getColumnType(tableName string, columnName string) (string, error) {
+	sql := "SELECT data_type FROM information_schema.columns WHERE table_schema = 'public' AND table_name = $1 AND column_name = $2;"
+
+	var dataType string
+	err := s.client.QueryRow(sql, tableName, columnName).Scan(&dataType)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to fetch type of column %s from postgres", columnName)
+	}
+
+	return dataType, nil
+}
+
+func (s *Storage) getDatabaseFields(tx *pgx.Tx, tableName string) ([]string, error) {
 	sql := fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema = 'public' AND table_name = $1;")
 
-	res, err := s.client.Query(sql, tableName)
+	res, err := tx.Query(sql, tableName)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to fetch database column names from postgres")
 	}
@@ -78,60 +92,128 @@ func (s *Storage) getExistingFields(dataset string) (map[string]*model.Variable,
 	return fields, nil
 }
 
-func (s *Storage) createView(storageName string, fields map[string]*model.Variable) error {
+// datasetChange is a schema change of a dataset along with the update of
+// its metadata. The metadata is not stored within the transaction, so revert
+// undoes the update should the transaction fail to commit afterwards.
+type datasetChange struct {
+	view     func(tx *pgx.Tx) error
+	metadata func() error
+	revert   func() error
+}
+
+// withDatasetLock runs a schema change of the dataset in a single transaction
+// holding an advisory lock on the dataset. Concurrent changes to the same
+// dataset are serialized and a failed change leaves the existing view intact.
+// The metadata is updated once the view changed, still holding the lock, and
+// the view change is rolled back if the metadata update fails.
+func (s *Storage) withDatasetLock(storageName string, change *datasetChange) error {
+	tx, err := s.client.Begin()
+	if err != nil {
+		return errors.Wrap(err, "Unable to start transaction")
+	}
+	// rolling back a committed transaction is a no-op
+	defer tx.Rollback()
+
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1));", storageName)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to lock dataset %s", storageName)
+	}
+
+	err = change.view(tx)
+	if err != nil {
+		return err
+	}
+
+	err = change.metadata()
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		if change.revert != nil {
+			errRevert := change.revert()
+			if errRevert != nil {
+				log.Errorf("unable to revert the metadata of dataset %s: %v", storageName, errRevert)
+			}
+		}
+		return errors.Wrap(err, "Unable to commit transaction")
+	}
+
+	return nil
+}
+
+func (s *Storage) createView(tx *pgx.Tx, storageName string, fields map[string]*model.Variable) error {
 	// CREATE OR REPLACE VIEW requires the same column names and order (with additions at the end being allowed).
-	sql := "CREATE VIEW %s_tmp AS SELECT %s FROM %s_base;"
+	sql := "CREATE VIEW %s AS SELECT %s FROM %s;"
 
 	// Build the select statement of the query.
 	fieldList := make([]string, 0)
 	for _, v := range fields {
 		fieldList = append(fieldList, s.getViewField(v.Name, v.OriginalVariable, model.MapD3MTypeToPostgresType(v.Type), model.DefaultPostgresValueFromD3MType(v.Type)))
 	}
-	sql = fmt.Sprintf(sql, storageName, strings.Join(fieldList, ","), storageName)
+	sql = fmt.Sprintf(sql, quoteIdentifier(storageName+"_tmp"), strings.Join(fieldList, ","), baseTable(storageName))
 
 	// Create the temporary view with the new column type.
-	_, err := s.client.Exec(sql)
+	_, err := tx.Exec(sql)
 	if err != nil {
 		return errors.Wrap(err, "Unable to create new temp view")
 	}
 
 	// Drop the existing view.
-	_, err = s.client.Exec(fmt.Sprintf("DROP VIEW %s;", storageName))
+	_, err = tx.Exec(fmt.Sprintf("DROP VIEW %s;", quoteIdentifier(storageName)))
 	if err != nil {
 		return errors.Wrap(err, "Unable to drop existing view")
 	}
 
 	// Rename the temporary view to the actual view name.
-	_, err = s.client.Exec(fmt.Sprintf("ALTER VIEW %s_tmp RENAME TO %s;", storageName, storageName))
+	_, err = tx.Exec(fmt.Sprintf("ALTER VIEW %s RENAME TO %s;", quoteIdentifier(storageName+"_tmp"), quoteIdentifier(storageName)))
 
 	return err
 }
 
-// SetDataType updates the data type of the specified variable.
-// Multiple simultaneous calls to the function can result in discarded changes.
+// SetDataType updates the data type of the specified variable in both the
+// view and the metadata. The change is made while holding the dataset lock so
+// concurrent type changes are never discarded.
 func (s *Storage) SetDataType(dataset string, storageName string, varName string, varType string) error {
-	// get all existing fields to rebuild the view.
-	fields, err := s.getExistingFields(dataset)
-	if err != nil {
-		return errors.Wrap(err, "Unable to read existing fields")
-	}
+	previousType := ""
+	return s.withDatasetLock(storageName, &datasetChange{
+		view: func(tx *pgx.Tx) error {
+			// get all existing fields to rebuild the view.
+			fields, err := s.getExistingFields(dataset)
+			if err != nil {
+				return errors.Wrap(err, "Unable to read existing fields")
+			}
 
-	// update field type in lookup.
-	if fields[varName] == nil {
-		return fmt.Errorf("field '%s' not found in existing fields", varName)
-	}
-	fields[varName].Type = varType
+			// update field type in lookup.
+			if fields[varName] == nil {
+				return fmt.Errorf("field '%s' not found in existing fields", varName)
+			}
+			previousType = fields[varName].Type
+			fields[varName].Type = varType
 
-	// create view based on field lookup.
-	err = s.createView(storageName, fields)
-	if err != nil {
-		return errors.Wrap(err, "Unable to create the new view")
-	}
+			// create view based on field lookup.
+			err = s.createView(tx, storageName, fields)
+			if err != nil {
+				return errors.Wrap(err, "Unable to create the new view")
+			}
 
-	return nil
+			return nil
+		},
+		metadata: func() error {
+			err := s.metadata.SetDataType(dataset, varName, varType)
+			if err != nil {
+				return errors.Wrap(err, "Unable to update the data type in metadata")
+			}
+			return nil
+		},
+		revert: func() error {
+			return s.metadata.SetDataType(dataset, varName, previousType)
+		},
+	})
 }
 
-func (s *Storage) createViewFromMetadataFields(storageName string, fields map[string]*model.Variable) error {
+func (s *Storage) createViewFromMetadataFields(tx *pgx.Tx, storageName string, fields map[string]*model.Variable) error {
 	dbFields := make(map[string]*model.Variable)
 
 	// map the types to db types.
@@ -143,7 +225,7 @@ func (s *Storage) createViewFromMetadataFields(storageName string, fields map[st
 		}
 	}
 
-	err := s.createView(storageName, dbFields)
+	err := s.createView(tx, storageName, dbFields)
 	if err != nil {
 		return errors.Wrap(err, "Unable to create the new view")
 	}
@@ -151,96 +233,145 @@ func (s *Storage) createViewFromMetadataFields(storageName string, fields map[st
 	return nil
 }
 
-// AddVariable adds a new variable to the dataset.
-func (s *Storage) AddVariable(dataset string, storageName string, varName string, varType string) error {
-	// check to make sure the column doesnt exist already
-	dbFields, err := s.getDatabaseFields(fmt.Sprintf("%s_base", storageName))
+// AddVariable adds a new variable to the dataset, in both the database and
+// the metadata.
+func (s *Storage) AddVariable(dataset string, storageName string, varName string, varType string, varRole string) error {
+	err := validateIdentifier(varName)
 	if err != nil {
-		return errors.Wrap(err, "unable to read database fields")
+		return err
 	}
 
-	found := false
-	for _, v := range dbFields {
-		if v == varName {
-			found = true
-			break
-		}
-	}
-	if found {
-		return errors.Errorf("dataset %s already has variable '%s' in postgres", storageName, varName)
-	}
+	inMetadata := false
+	return s.withDatasetLock(storageName, &datasetChange{
+		view: func(tx *pgx.Tx) error {
+			// check to make sure the column doesnt exist already
+			dbFields, err := s.getDatabaseFields(tx, fmt.Sprintf("%s_base", storageName))
+			if err != nil {
+				return errors.Wrap(err, "unable to read database fields")
+			}
 
-	// add the empty column
-	sql := fmt.Sprintf("ALTER TABLE %s_base ADD COLUMN \"%s\" TEXT;", storageName, varName)
-	_, err = s.client.Exec(sql)
-	if err != nil {
-		return errors.Wrap(err, "Unable to add new column to database table")
-	}
+			found := false
+			for _, v := range dbFields {
+				if v == varName {
+					found = true
+					break
+				}
+			}
+			if found {
+				return errors.Errorf("dataset %s already has variable '%s' in postgres", storageName, varName)
+			}
 
-	// recreate the view with the new column
-	fields, err := s.getExistingFields(dataset)
-	if err != nil {
-		return errors.Wrap(err, "Unable to read existing fields")
-	}
+			// add the empty column
+			sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT;", baseTable(storageName), quoteIdentifier(varName))
+			_, err = tx.Exec(sql)
+			if err != nil {
+				return errors.Wrap(err, "Unable to add new column to database table")
+			}
 
-	if fields[varName] == nil {
-		// need to add the field to the view
-		fields[varName] = &model.Variable{
-			Name:             varName,
-			OriginalVariable: varName,
-			Type:             varType,
-		}
-	}
+			// recreate the view with the new column
+			fields, err := s.getExistingFields(dataset)
+			if err != nil {
+				return errors.Wrap(err, "Unable to read existing fields")
+			}
 
-	err = s.createViewFromMetadataFields(storageName, fields)
-	if err != nil {
-		return errors.Wrap(err, "Unable to create the new view")
-	}
+			inMetadata = fields[varName] != nil
+			if !inMetadata {
+				// need to add the field to the view
+				fields[varName] = &model.Variable{
+					Name:             varName,
+					OriginalVariable: varName,
+					Type:             varType,
+				}
+			}
 
-	return nil
+			err = s.createViewFromMetadataFields(tx, storageName, fields)
+			if err != nil {
+				return errors.Wrap(err, "Unable to create the new view")
+			}
+
+			return nil
+		},
+		metadata: func() error {
+			if inMetadata {
+				return nil
+			}
+			err := s.metadata.AddVariable(dataset, varName, varType, varRole)
+			if err != nil {
+				return errors.Wrap(err, "Unable to add the variable to metadata")
+			}
+			return nil
+		},
+		revert: func() error {
+			if inMetadata {
+				return nil
+			}
+			return s.metadata.DeleteVariable(dataset, varName)
+		},
+	})
 }
 
-// DeleteVariable flags a variable as deleted.
+// DeleteVariable removes a variable from the view and flags it as deleted in
+// the metadata. Flagging the variable can not be reverted, but a view still
+// holding it is harmless and rebuilt by retrying the deletion.
 func (s *Storage) DeleteVariable(dataset string, storageName string, varName string) error {
-	// check if the variable is in the view
-	dbFields, err := s.getDatabaseFields(storageName)
-	if err != nil {
-		return errors.Wrap(err, "unable to read database fields")
-	}
-
 	found := false
-	for _, v := range dbFields {
-		if v == varName {
-			found = true
-			break
-		}
-	}
-	if !found {
-		return nil
-	}
+	return s.withDatasetLock(storageName, &datasetChange{
+		view: func(tx *pgx.Tx) error {
+			// check if the variable is in the view
+			dbFields, err := s.getDatabaseFields(tx, storageName)
+			if err != nil {
+				return errors.Wrap(err, "unable to read database fields")
+			}
 
-	// recreate the view without the field if it is in it
-	fields, err := s.getExistingFields(dataset)
-	if err != nil {
-		return errors.Wrap(err, "Unable to read existing fields")
-	}
+			for _, v := range dbFields {
+				if v == varName {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil
+			}
 
-	if fields[varName] != nil {
-		delete(fields, varName)
-	}
+			// recreate the view without the field if it is in it
+			fields, err := s.getExistingFields(dataset)
+			if err != nil {
+				return errors.Wrap(err, "Unable to read existing fields")
+			}
 
-	err = s.createViewFromMetadataFields(storageName, fields)
-	if err != nil {
-		return errors.Wrap(err, "Unable to create the new view")
-	}
+			if fields[varName] != nil {
+				delete(fields, varName)
+			}
 
-	return nil
+			err = s.createViewFromMetadataFields(tx, storageName, fields)
+			if err != nil {
+				return errors.Wrap(err, "Unable to create the new view")
+			}
+
+			return nil
+		},
+		metadata: func() error {
+			if !found {
+				return nil
+			}
+			err := s.metadata.DeleteVariable(dataset, varName)
+			if err != nil {
+				return errors.Wrap(err, "Unable to delete the variable from metadata")
+			}
+			return nil
+		},
+	})
 }
 
 // UpdateVariable updates the value of a variable stored in the database.
 func (s *Storage) UpdateVariable(storageName string, varName string, d3mIndex string, value string) error {
-	sql := fmt.Sprintf("UPDATE %s_base SET \"%s\" = $1 WHERE \"%s\" = $2", storageName, varName, model.D3MIndexFieldName)
-	_, err := s.client.Exec(sql, value, d3mIndex)
+	err := validateIdentifier(varName)
+	if err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2", baseTable(storageName), quoteIdentifier(varName), quoteIdentifier(model.D3MIndexFieldName))
+	_, err = s.client.Exec(sql, value, d3mIndex)
 	if err != nil {
 		return errors.Wrap(err, "Unable to update value stored in the database")
 	}
@@ -249,48 +380,94 @@ func (s *Storage) UpdateVariable(storageName string, varName string, d3mIndex st
 }
 
 // UpdateVariableBatch batches updates for a variable to increase performance.
+// Each batch is sent as a single update joined against the unnested index and
+// value arrays.
 func (s *Storage) UpdateVariableBatch(storageName string, varName string, updates map[string]string) error {
-	// A couple of approaches are possible:
-	// 1. Batch the updates in a string and send many updates at once to diminish network time.
-	// 2. Batch insert the updates to a temp table, send an update command where a join
-	//		between the original table and the temp table is done to get the new values
-	//		and then delete the temp table.
+	err := validateIdentifier(varName)
+	if err != nil {
+		return err
+	}
+
+	// the index values are cast to the column type so the column index is used
+	indexType, err := s.getColumnType(fmt.Sprintf("%s_base", storageName), model.D3MIndexName)
+	if err != nil {
+		return err
+	}
+	sql := fmt.Sprintf("UPDATE %s AS base SET %s = updates.value FROM unnest($1::text[], $2::text[]) AS updates(index, value) "+
+		"WHERE base.%s = CAST(updates.index AS %s);", baseTable(storageName), quoteIdentifier(varName), quoteIdentifier(model.D3MIndexName), indexType)
 
 	// loop through the updates, building batches to minimize overhead
-	db := s.client.GetUpdateClient()
-	dataSQL := ""
-	count := 0
-	params := make([]interface{}, 0)
+	indices := make([]string, 0, maxBatchSize)
+	values := make([]string, 0, maxBatchSize)
 	for index, value := range updates {
-		dataSQL = fmt.Sprintf("%s UPDATE %s.%s.%s_base SET \"%s\" = ? WHERE \"%s\" = ?;",
-			dataSQL, "distil", "public", storageName, varName, model.D3MIndexName)
-		params = append(params, value)
-		params = append(params, index)
-		count = count + 1
+		indices = append(indices, index)
+		values = append(values, value)
 
-		if count > maxBatchSize {
+		if len(indices) == maxBatchSize {
 			// submit the batch
-			_, err := db.Exec(dataSQL, params...)
+			_, err = s.client.Exec(sql, indices, values)
 			if err != nil {
 				return errors.Wrap(err, "unable to update batch")
 			}
 
 			// reset the batch
-			dataSQL = ""
-			count = 0
-			params = make([]interface{}, 0)
+			indices = indices[:0]
+			values = values[:0]
 		}
 	}
 
 	// submit remaining rows
-	if count > 0 {
-		_, err := db.Exec(dataSQL, params...)
+	if len(indices) > 0 {
+		_, err = s.client.Exec(sql, indices, values)
 		if err != nil {
 			return errors.Wrap(err, "unable to update batch")
 		}
 	}
 
-	db.Close()
+	return nil
+}
+
+func datasetTableNames(storageName string) []string {
+	return []string{
+		storageName,
+		fmt.Sprintf("%s_base", storageName),
+		fmt.Sprintf("%s_result", storageName),
+		fmt.Sprintf("%s_result_output", storageName),
+	}
+}
+
+// FetchDatasetTables returns the existing views and tables storing the dataset.
+func (s *Storage) FetchDatasetTables(storageName string) ([]string, error) {
+	tables := make([]string, 0)
+	for _, name := range datasetTableNames(storageName) {
+		var exists bool
+		err := s.client.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_class WHERE relname = $1);", name).Scan(&exists)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to check if %s exists", name)
+		}
+		if exists {
+			tables = append(tables, name)
+		}
+	}
+
+	return tables, nil
+}
+
+// DeleteDataset drops the dataset view along with the base and result tables.
+func (s *Storage) DeleteDataset(storageName string) error {
+	names := datasetTableNames(storageName)
+
+	_, err := s.client.Exec(fmt.Sprintf("DROP VIEW IF EXISTS %s;", quoteIdentifier(names[0])))
+	if err != nil {
+		return errors.Wrap(err, "Unable to drop the dataset view")
+	}
+
+	for _, name := range names[1:] {
+		_, err = s.client.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s;", quoteIdentifier(name)))
+		if err != nil {
+			return errors.Wrapf(err, "Unable to drop the %s table", name)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,139 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// failingMetadataStorage fails every metadata update.
+type failingMetadataStorage struct {
+	api.MetadataStorage
+}
+
+func (f *failingMetadataStorage) SetDataType(dataset string, varName string, varType string) error {
+	return errors.New("metadata unavailable")
+}
+
+func (f *failingMetadataStorage) AddVariable(dataset string, varName string, varType string, varDistilRole string) error {
+	return errors.New("metadata unavailable")
+}
+
+// newTestDataStorage creates the base table and view of a dataset holding
+// text variables, as ingested, returning a function removing them.
+func newTestDataStorage(t *testing.T, dataset string, varNames ...string) (*Storage, func()) {
+	metadata := newTestMetadataStorage(t)
+	storage := &Storage{
+		client:   metadata.client,
+		metadata: metadata,
+	}
+
+	variables := []*model.Variable{
+		{
+			Name:             model.D3MIndexFieldName,
+			OriginalVariable: model.D3MIndexFieldName,
+			Index:            0,
+			Type:             model.IntegerType,
+			Role:             []string{model.VarRoleIndex},
+		},
+	}
+	columns := []string{fmt.Sprintf("%s TEXT", quoteIdentifier(model.D3MIndexFieldName))}
+	for i, varName := range varNames {
+		variables = append(variables, &model.Variable{
+			Name:             varName,
+			OriginalVariable: varName,
+			Index:            i + 1,
+			Type:             model.StringType,
+		})
+		columns = append(columns, fmt.Sprintf("%s TEXT", quoteIdentifier(varName)))
+	}
+
+	_, err := storage.client.Exec(fmt.Sprintf("CREATE TABLE %s (%s);", baseTable(dataset), strings.Join(columns, ", ")))
+	assert.NoError(t, err)
+	_, err = storage.client.Exec(fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s;", quoteIdentifier(dataset), baseTable(dataset)))
+	assert.NoError(t, err)
+	err = metadata.IngestDataset(&api.Dataset{
+		ID:          dataset,
+		Name:        dataset,
+		StorageName: dataset,
+		Variables:   variables,
+	})
+	assert.NoError(t, err)
+
+	return storage, func() {
+		storage.client.Exec(fmt.Sprintf("DROP VIEW IF EXISTS %s;", quoteIdentifier(dataset)))
+		storage.client.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s;", baseTable(dataset)))
+		metadata.DeleteDataset(dataset)
+	}
+}
+
+func TestSetDataTypeConcurrent(t *testing.T) {
+	dataset := "dataset_test_concurrent"
+	varNames := []string{"alpha", "bravo", "charlie", "delta"}
+	storage, cleanup := newTestDataStorage(t, dataset, varNames...)
+	defer cleanup()
+
+	// each change rebuilds the view from the metadata, so none is lost when
+	// they are serialized
+	wg := &sync.WaitGroup{}
+	for _, varName := range varNames {
+		wg.Add(1)
+		go func(varName string) {
+			defer wg.Done()
+			assert.NoError(t, storage.SetDataType(dataset, dataset, varName, model.IntegerType))
+		}(varName)
+	}
+	wg.Wait()
+
+	for _, varName := range varNames {
+		variable, err := storage.metadata.FetchVariable(dataset, varName)
+		assert.NoError(t, err)
+		assert.Equal(t, model.IntegerType, variable.Type)
+
+		columnType, err := storage.getColumnType(dataset, varName)
+		assert.NoError(t, err)
+		assert.NotEqual(t, "text", columnType)
+	}
+}
+
+func TestDatasetChangeMetadataFailure(t *testing.T) {
+	dataset := "dataset_test_failure"
+	storage, cleanup := newTestDataStorage(t, dataset, "alpha")
+	defer cleanup()
+	storage.metadata = &failingMetadataStorage{storage.metadata}
+
+	// the view keeps its type when the metadata can not be updated
+	err := storage.SetDataType(dataset, dataset, "alpha", model.IntegerType)
+	assert.Error(t, err)
+	columnType, err := storage.getColumnType(dataset, "alpha")
+	assert.NoError(t, err)
+	assert.Equal(t, "text", columnType)
+
+	// the column is not added to the table either
+	err = storage.AddVariable(dataset, dataset, "bravo", model.StringType, model.VarRoleMetadata)
+	assert.Error(t, err)
+	_, err = storage.getColumnType(dataset+"_base", "bravo")
+	assert.Error(t, err)
+}
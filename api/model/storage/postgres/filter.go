@@ -86,7 +86,7 @@ func (s *Storage) formatFilterKey(key string) string {
 	if api.IsResultKey(key) {
 		return "result.value"
 	}
-	return fmt.Sprintf("\"%s\"", key)
+	return quoteIdentifier(key)
 }
 
 func (s *Storage) buildIncludeFilter(wheres []string, params []interface{}, filter *model.Filter) ([]string, []interface{}) {
@@ -108,12 +108,12 @@ func (s *Storage) buildIncludeFilter(wheres []string, params []interface{}, filt
 		split := strings.Split(filter.Key, ":")
 		where := ""
 		if len(split) > 1 {
-			xKey := split[0]
-			yKey := split[1]
+			xKey := quoteIdentifier(split[0])
+			yKey := quoteIdentifier(split[1])
 			where = fmt.Sprintf("cast(%s as double precision) >= $%d AND cast(%s as double precision) <= $%d AND cast(%s as double precision) >= $%d AND cast(%s as double precision) <= $%d", xKey, len(params)+1, xKey, len(params)+2, yKey, len(params)+3, yKey, len(params)+4)
 		} else {
 			// hardcode [lat, lon] format for now
-			where = fmt.Sprintf("%s[2] >= $%d AND %s[2] <= $%d AND %s[1] >= $%d AND %s[1] <= $%d", filter.Key, len(params)+1, filter.Key, len(params)+2, filter.Key, len(params)+3, filter.Key, len(params)+4)
+			where = fmt.Sprintf("%s[2] >= $%d AND %s[2] <= $%d AND %s[1] >= $%d AND %s[1] <= $%d", name, len(params)+1, name, len(params)+2, name, len(params)+3, name, len(params)+4)
 		}
 		wheres = append(wheres, where)
 		params = append(params, filter.Bounds.MinX)
@@ -139,7 +139,7 @@ func (s *Storage) buildIncludeFilter(wheres []string, params []interface{}, filt
 			indices = append(indices, fmt.Sprintf("$%d", offset+i))
 			params = append(params, d3mIndex)
 		}
-		where := fmt.Sprintf("\"%s\" IN (%s)", model.D3MIndexFieldName, strings.Join(indices, ", "))
+		where := fmt.Sprintf("%s IN (%s)", quoteIdentifier(model.D3MIndexFieldName), strings.Join(indices, ", "))
 		wheres = append(wheres, where)
 	case model.FeatureFilter, model.TextFilter:
 		// feature
@@ -171,12 +171,12 @@ func (s *Storage) buildExcludeFilter(wheres []string, params []interface{}, filt
 		split := strings.Split(filter.Key, ":")
 		where := ""
 		if len(split) > 1 {
-			xKey := split[0]
-			yKey := split[1]
+			xKey := quoteIdentifier(split[0])
+			yKey := quoteIdentifier(split[1])
 			where = fmt.Sprintf("(%s < $%d OR %s > $%d) OR (%s < $%d OR %s > $%d)", xKey, len(params)+1, xKey, len(params)+2, yKey, len(params)+3, yKey, len(params)+4)
 		} else {
 			// hardcode [lat, lon] format for now
-			where = fmt.Sprintf("(%s[2] < $%d OR %s[2] > $%d) OR (%s[1] < $%d OR %s[1] > $%d)", filter.Key, len(params)+1, filter.Key, len(params)+2, filter.Key, len(params)+3, filter.Key, len(params)+4)
+			where = fmt.Sprintf("(%s[2] < $%d OR %s[2] > $%d) OR (%s[1] < $%d OR %s[1] > $%d)", name, len(params)+1, name, len(params)+2, name, len(params)+3, name, len(params)+4)
 		}
 		wheres = append(wheres, where)
 		params = append(params, filter.Bounds.MinX)
@@ -202,7 +202,7 @@ func (s *Storage) buildExcludeFilter(wheres []string, params []interface{}, filt
 			indices = append(indices, fmt.Sprintf("$%d", offset+i))
 			params = append(params, d3mIndex)
 		}
-		where := fmt.Sprintf("\"%s\" NOT IN (%s)", model.D3MIndexFieldName, strings.Join(indices, ", "))
+		where := fmt.Sprintf("%s NOT IN (%s)", quoteIdentifier(model.D3MIndexFieldName), strings.Join(indices, ", "))
 		wheres = append(wheres, where)
 	case model.FeatureFilter, model.TextFilter:
 		// feature
@@ -232,14 +232,14 @@ func (s *Storage) buildFilteredQueryField(variables []*model.Variable, filterVar
 	fields := make([]string, 0)
 	indexIncluded := false
 	for _, variable := range api.GetFilterVariables(filterVariables, variables) {
-		fields = append(fields, fmt.Sprintf("\"%s\"", variable.Name))
+		fields = append(fields, quoteIdentifier(variable.Name))
 		if variable.Name == model.D3MIndexFieldName {
 			indexIncluded = true
 		}
 	}
 	// if the index is not already in the field list, then append it
 	if !indexIncluded {
-		fields = append(fields, fmt.Sprintf("\"%s\"", model.D3MIndexFieldName))
+		fields = append(fields, quoteIdentifier(model.D3MIndexFieldName))
 	}
 	return strings.Join(fields, ","), nil
 }
@@ -248,21 +248,14 @@ func (s *Storage) buildFilteredResultQueryField(variables []*model.Variable, tar
 	fields := make([]string, 0)
 	for _, variable := range api.GetFilterVariables(filterVariables, variables) {
 		if strings.Compare(targetVariable.Name, variable.Name) != 0 {
-			fields = append(fields, fmt.Sprintf("\"%s\"", variable.Name))
+			fields = append(fields, quoteIdentifier(variable.Name))
 		}
 	}
-	fields = append(fields, fmt.Sprintf("\"%s\"", model.D3MIndexFieldName))
+	fields = append(fields, quoteIdentifier(model.D3MIndexFieldName))
 	return strings.Join(fields, ","), nil
 }
 
-func (s *Storage) buildCorrectnessResultWhere(wheres []string, params []interface{}, storageName string, resultURI string, resultFilter *model.Filter) ([]string, []interface{}, error) {
-	// get the target variable name
-	storageNameResult := s.getResultTable(storageName)
-	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
-	if err != nil {
-		return nil, nil, err
-	}
-
+func (s *Storage) buildCorrectnessResultWhere(wheres []string, params []interface{}, targetName string, resultFilter *model.Filter) ([]string, []interface{}, error) {
 	// correct/incorrect are well known categories that require the predicted category to be compared
 	// to the target category
 	op := ""
@@ -276,9 +269,9 @@ func (s *Storage) buildCorrectnessResultWhere(wheres []string, params []interfac
 		}
 	}
 	if op == "" {
-		return nil, nil, err
+		return wheres, params, nil
 	}
-	where := fmt.Sprintf("result.value %s data.\"%s\"", op, targetName)
+	where := fmt.Sprintf("result.value %s data.%s", op, quoteIdentifier(targetName))
 	wheres = append(wheres, where)
 	return wheres, params, nil
 }
@@ -302,24 +295,36 @@ func (s *Storage) buildPredictedResultWhere(wheres []string, params []interface{
 	return wheres, params, nil
 }
 
-func (s *Storage) buildResultQueryFilters(storageName string, resultURI string, filterParams *api.FilterParams) ([]string, []interface{}, error) {
+func (s *Storage) buildResultQueryFilters(storageName string, resultURI string, target string, filterParams *api.FilterParams) ([]string, []interface{}, error) {
 	// pull filters generated against the result facet out for special handling
 	filters := s.splitFilters(filterParams)
 
+	// results store a row per target so only the rows of one target are
+	// used, which defaults to the target of the result filters
+	if target == "" {
+		target = filters.resultTarget()
+	}
+	targetName, err := s.getResultTargetName(s.getResultTable(storageName), resultURI, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	filters.dropOtherTargets(targetName)
+
 	// create the filter for the query
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
 	wheres, params = s.buildFilteredQueryWhere(wheres, params, filters.genericFilters)
+	wheres = append(wheres, fmt.Sprintf("result.target = $%d", len(params)+1))
+	params = append(params, targetName)
 
 	// assemble split filters
-	var err error
 	if filters.predictedFilter != nil {
 		wheres, params, err = s.buildPredictedResultWhere(wheres, params, resultURI, filters.predictedFilter)
 		if err != nil {
 			return nil, nil, err
 		}
 	} else if filters.correctnessFilter != nil {
-		wheres, params, err = s.buildCorrectnessResultWhere(wheres, params, storageName, resultURI, filters.correctnessFilter)
+		wheres, params, err = s.buildCorrectnessResultWhere(wheres, params, targetName, filters.correctnessFilter)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -329,6 +334,12 @@ func (s *Storage) buildResultQueryFilters(storageName string, resultURI string,
 			return nil, nil, err
 		}
 	}
+	for _, outputFilter := range filters.outputFilters {
+		wheres, params, err = s.addOutputFilterToWhere(wheres, params, storageName, "result", outputFilter)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 	return wheres, params, nil
 }
 
@@ -337,6 +348,32 @@ type filters struct {
 	predictedFilter   *model.Filter
 	residualFilter    *model.Filter
 	correctnessFilter *model.Filter
+	outputFilters     []*model.Filter
+}
+
+// resultTarget returns the target of the filters on predicted values or
+// errors, or an empty string if there are none.
+func (f *filters) resultTarget() string {
+	for _, filter := range []*model.Filter{f.predictedFilter, f.residualFilter, f.correctnessFilter} {
+		if filter != nil {
+			return api.StripKeySuffix(filter.Key)
+		}
+	}
+	return ""
+}
+
+// dropOtherTargets removes the filters on predicted values or errors of
+// targets other than the one queried.
+func (f *filters) dropOtherTargets(targetName string) {
+	if f.predictedFilter != nil && api.StripKeySuffix(f.predictedFilter.Key) != targetName {
+		f.predictedFilter = nil
+	}
+	if f.residualFilter != nil && api.StripKeySuffix(f.residualFilter.Key) != targetName {
+		f.residualFilter = nil
+	}
+	if f.correctnessFilter != nil && api.StripKeySuffix(f.correctnessFilter.Key) != targetName {
+		f.correctnessFilter = nil
+	}
 }
 
 func (s *Storage) splitFilters(filterParams *api.FilterParams) *filters {
@@ -344,9 +381,12 @@ func (s *Storage) splitFilters(filterParams *api.FilterParams) *filters {
 	var predictedFilter *model.Filter
 	var residualFilter *model.Filter
 	var correctnessFilter *model.Filter
+	var outputFilters []*model.Filter
 	var remaining []*model.Filter
 	for _, filter := range filterParams.Filters {
-		if api.IsPredictedKey(filter.Key) {
+		if api.IsOutputKey(filter.Key) {
+			outputFilters = append(outputFilters, filter)
+		} else if api.IsPredictedKey(filter.Key) {
 			predictedFilter = filter
 		} else if api.IsErrorKey(filter.Key) {
 			if filter.Type == model.NumericalFilter {
@@ -363,17 +403,18 @@ func (s *Storage) splitFilters(filterParams *api.FilterParams) *filters {
 		predictedFilter:   predictedFilter,
 		residualFilter:    residualFilter,
 		correctnessFilter: correctnessFilter,
+		outputFilters:     outputFilters,
 	}
 }
 
 // FetchNumRows pulls the number of rows in the table.
 func (s *Storage) FetchNumRows(storageName string, filters map[string]interface{}) (int, error) {
-	query := fmt.Sprintf("SELECT count(*) FROM %s", storageName)
+	query := fmt.Sprintf("SELECT count(*) FROM %s", quoteIdentifier(storageName))
 	params := make([]interface{}, 0)
 	if filters != nil && len(filters) > 0 {
 		clauses := make([]string, 0)
 		for field, value := range filters {
-			clauses = append(clauses, fmt.Sprintf("%s = $%d", field, len(clauses)+1))
+			clauses = append(clauses, fmt.Sprintf("%s = $%d", quoteIdentifier(field), len(clauses)+1))
 			params = append(params, value)
 		}
 		query = fmt.Sprintf("%s WHERE %s", query, strings.Join(clauses, " AND "))
@@ -416,7 +457,7 @@ func (s *Storage) FetchData(dataset string, storageName string, filterParams *ap
 	}
 
 	// construct a Postgres query that fetches documents from the dataset with the supplied variable filters applied
-	query := fmt.Sprintf("SELECT %s FROM %s", fields, storageName)
+	query := fmt.Sprintf("SELECT %s FROM %s", fields, quoteIdentifier(storageName))
 
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
@@ -441,7 +482,7 @@ func (s *Storage) FetchData(dataset string, storageName string, filterParams *ap
 	}
 
 	// order & limit the filtered data.
-	query = fmt.Sprintf("%s ORDER BY \"%s\"", query, model.D3MIndexFieldName)
+	query = fmt.Sprintf("%s ORDER BY %s", query, quoteIdentifier(model.D3MIndexFieldName))
 	if filterParams.Size > 0 {
 		query = fmt.Sprintf("%s LIMIT %d", query, filterParams.Size)
 	}
@@ -0,0 +1,111 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// FetchForecast fetches the forecasted series of a result along with the
+// actual series of the whole dataset, for each value of the series column.
+// All rows belong to a single series when no series column is specified.
+func (s *Storage) FetchForecast(dataset string, storageName string, resultURI string, target string, timeColumn string, seriesColumn string) (*api.Forecast, error) {
+	storageNameResult := s.getResultTable(storageName)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI, target)
+	if err != nil {
+		return nil, err
+	}
+
+	variable, err := s.getResultTargetVariable(dataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+	if !model.IsNumerical(variable.Type) {
+		return nil, errors.Errorf("variable %s of type %s cannot be forecasted", variable.Name, variable.Type)
+	}
+
+	// the time and series columns need to be variables of the dataset
+	timeVariable, err := s.metadata.FetchVariable(dataset, timeColumn)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get time variable information")
+	}
+	series := "''"
+	if seriesColumn != "" {
+		_, err = s.metadata.FetchVariable(dataset, seriesColumn)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to get series variable information")
+		}
+		series = fmt.Sprintf("CAST(data.%s AS TEXT)", quoteIdentifier(seriesColumn))
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %s, CAST(data.%s AS TEXT), CAST(data.%s AS double precision), CAST(NULLIF(result.value, '') AS double precision)
+		 FROM %s AS data LEFT JOIN %s AS result ON result.index = data.%s AND result.result_id = $1 AND result.target = $2
+		 ORDER BY %s, %s;`,
+		series, quoteIdentifier(timeColumn), quoteIdentifier(targetName),
+		quoteIdentifier(storageName), quoteIdentifier(storageNameResult), quoteIdentifier(model.D3MIndexFieldName),
+		series, getTimeOrderExpr("data", timeVariable))
+
+	res, err := s.client.Query(query, resultURI, targetName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch forecast from postgres")
+	}
+	defer res.Close()
+
+	rows := make([]*api.ForecastRow, 0)
+	for res.Next() {
+		row := &api.ForecastRow{}
+		var seriesID *string
+		var time *string
+		err = res.Scan(&seriesID, &time, &row.Actual, &row.Predicted)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse forecast from postgres")
+		}
+		// rows without a time cannot be plotted
+		if time == nil {
+			continue
+		}
+		if seriesID != nil {
+			row.SeriesID = *seriesID
+		}
+		row.Time = *time
+		rows = append(rows, row)
+	}
+
+	forecast := api.NewForecast(rows)
+	forecast.Key = targetName
+	return forecast, nil
+}
+
+// getTimeOrderExpr returns the expression ordering the rows by time. Date
+// times and numbers are typed in the view. Other time columns are text, which
+// is ordered numerically when the values are numbers, and as text otherwise
+// as ISO formatted dates sort correctly.
+func getTimeOrderExpr(alias string, timeVariable *model.Variable) string {
+	column := fmt.Sprintf("%s.%s", alias, quoteIdentifier(timeVariable.Name))
+	if model.IsDateTime(timeVariable.Type) {
+		return column
+	}
+	if model.IsNumerical(timeVariable.Type) {
+		return fmt.Sprintf("CAST(%s AS double precision)", column)
+	}
+	return fmt.Sprintf("CASE WHEN CAST(%s AS TEXT) ~ '^-?[0-9]+(\\.[0-9]+)?$' THEN CAST(CAST(%s AS TEXT) AS double precision) END, CAST(%s AS TEXT)",
+		column, column, column)
+}
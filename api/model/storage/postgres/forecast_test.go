@@ -0,0 +1,33 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+)
+
+func TestGetTimeOrderExpr(t *testing.T) {
+	assert.Equal(t, `data."date"`, getTimeOrderExpr("data", &model.Variable{Name: "date", Type: model.DateTimeType}))
+	assert.Equal(t, `CAST(data."step" AS double precision)`, getTimeOrderExpr("data", &model.Variable{Name: "step", Type: model.IntegerType}))
+
+	// text is ordered numerically first so "10" comes after "9"
+	expr := getTimeOrderExpr("data", &model.Variable{Name: "year", Type: model.StringType})
+	assert.Contains(t, expr, `THEN CAST(CAST(data."year" AS TEXT) AS double precision) END`)
+	assert.Contains(t, expr, `, CAST(data."year" AS TEXT)`)
+}
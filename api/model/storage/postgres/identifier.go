@@ -0,0 +1,56 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+const (
+	// postgres silently truncates identifiers longer than this many bytes.
+	maxIdentifierLength = 63
+)
+
+// quoteIdentifier quotes a name for use in a query, see
+// postgres.QuoteIdentifier.
+func quoteIdentifier(name string) string {
+	return postgres.QuoteIdentifier(name)
+}
+
+// validateIdentifier checks that a name can be stored as a postgres
+// identifier without being altered.
+func validateIdentifier(name string) error {
+	if name == "" {
+		return errors.New("identifier is empty")
+	}
+	if strings.Contains(name, "\x00") {
+		return errors.Errorf("identifier `%s` contains a null character", name)
+	}
+	if len(name) > maxIdentifierLength {
+		return errors.Errorf("identifier `%s` is longer than %d bytes", name, maxIdentifierLength)
+	}
+	return nil
+}
+
+// baseTable returns the quoted name of the table holding the raw data of
+// the dataset.
+func baseTable(storageName string) string {
+	return quoteIdentifier(storageName + "_base")
+}
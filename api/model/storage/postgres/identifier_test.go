@@ -0,0 +1,73 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, `"d3mIndex"`, quoteIdentifier("d3mIndex"))
+	assert.Equal(t, `"first name"`, quoteIdentifier("first name"))
+	assert.Equal(t, `"select"`, quoteIdentifier("select"))
+	assert.Equal(t, `"a""b"`, quoteIdentifier(`a"b`))
+	assert.Equal(t, `"x"" OR 1=1; --"`, quoteIdentifier(`x" OR 1=1; --`))
+	assert.Equal(t, `"ab"`, quoteIdentifier("a\x00b"))
+	assert.Equal(t, `"ds_base"`, baseTable("ds"))
+}
+
+func TestValidateIdentifier(t *testing.T) {
+	assert.NoError(t, validateIdentifier("first name"))
+	assert.NoError(t, validateIdentifier(`a"b`))
+	assert.Error(t, validateIdentifier(""))
+	assert.Error(t, validateIdentifier("a\x00b"))
+	assert.NoError(t, validateIdentifier(strings.Repeat("a", maxIdentifierLength)))
+	assert.Error(t, validateIdentifier(strings.Repeat("a", maxIdentifierLength+1)))
+}
+
+func TestBuildIncludeFilterQuotesKey(t *testing.T) {
+	s := &Storage{}
+
+	wheres, params := s.buildIncludeFilter([]string{}, []interface{}{}, &model.Filter{
+		Key:        `order "id"`,
+		Type:       model.CategoricalFilter,
+		Categories: []string{"a", "b"},
+	})
+	assert.Equal(t, []string{`"order ""id""" IN ($1, $2)`}, wheres)
+	assert.Equal(t, []interface{}{"a", "b"}, params)
+
+	min := 1.0
+	max := 2.0
+	wheres, params = s.buildExcludeFilter([]string{}, []interface{}{}, &model.Filter{
+		Key:  "from",
+		Type: model.NumericalFilter,
+		Min:  &min,
+		Max:  &max,
+	})
+	assert.Equal(t, []string{`("from" < $1 OR "from" > $2)`}, wheres)
+	assert.Equal(t, []interface{}{min, max}, params)
+}
+
+func TestGetViewFieldQuotesNames(t *testing.T) {
+	s := &Storage{}
+
+	assert.Equal(t, `COALESCE(CAST("total ""net""" AS double precision), 0) AS "group"`,
+		s.getViewField(`total "net"`, "group", "double precision", 0))
+}
@@ -0,0 +1,300 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-ingest/metadata"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+const (
+	// Provenance for postgres
+	Provenance = "postgres"
+
+	datasetMetadataTableName = "dataset_metadata"
+	searchConfiguration      = "english"
+	datasetMetadataFields    = "dataset_id, dataset_name, storage_name, description, folder, summary, summary_machine, " +
+		"num_rows, num_bytes, source, dataset_version, parent_dataset, root_dataset, variables::text"
+)
+
+// MetadataStorage stores the dataset metadata in postgres, keeping the
+// variables of each dataset as a JSONB document.
+type MetadataStorage struct {
+	client postgres.DatabaseDriver
+}
+
+// NewMetadataStorage returns a constructor for a metadata storage.
+func NewMetadataStorage(clientCtor postgres.ClientCtor) api.MetadataStorageCtor {
+	return func() (api.MetadataStorage, error) {
+		client, err := clientCtor()
+		if err != nil {
+			return nil, err
+		}
+
+		return NewMetadataStorageFromClient(client), nil
+	}
+}
+
+// NewMetadataStorageFromClient returns a metadata storage using an existing
+// postgres client.
+func NewMetadataStorageFromClient(client postgres.DatabaseDriver) *MetadataStorage {
+	return &MetadataStorage{
+		client: client,
+	}
+}
+
+// CreateMetadataTables creates the dataset metadata table and its full text
+// search index if they do not exist.
+func (s *MetadataStorage) CreateMetadataTables() error {
+	sql := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		dataset_id      TEXT PRIMARY KEY,
+		dataset_name    TEXT NOT NULL,
+		storage_name    TEXT NOT NULL,
+		description     TEXT NOT NULL DEFAULT '',
+		folder          TEXT NOT NULL DEFAULT '',
+		summary         TEXT NOT NULL DEFAULT '',
+		summary_machine TEXT NOT NULL DEFAULT '',
+		num_rows        BIGINT NOT NULL DEFAULT 0,
+		num_bytes       BIGINT NOT NULL DEFAULT 0,
+		source          TEXT NOT NULL DEFAULT '',
+		dataset_version INTEGER NOT NULL DEFAULT 1,
+		parent_dataset  TEXT NOT NULL DEFAULT '',
+		root_dataset    TEXT NOT NULL DEFAULT '',
+		variables       JSONB NOT NULL DEFAULT '[]',
+		search          TSVECTOR
+	);`, datasetMetadataTableName)
+	_, err := s.client.Exec(sql)
+	if err != nil {
+		return errors.Wrap(err, "unable to create dataset metadata table")
+	}
+
+	sql = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_search_idx ON %s USING GIN (search);", datasetMetadataTableName, datasetMetadataTableName)
+	_, err = s.client.Exec(sql)
+	if err != nil {
+		return errors.Wrap(err, "unable to create dataset metadata search index")
+	}
+
+	return nil
+}
+
+// IngestDataset stores the dataset metadata, replacing any existing metadata
+// for the dataset, and refreshes its search document. The dataset name and
+// folder weigh the most in searches, followed by the variable names and then
+// the descriptions.
+func (s *MetadataStorage) IngestDataset(dataset *api.Dataset) error {
+	variables, err := json.Marshal(dataset.Variables)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal dataset variables")
+	}
+
+	varNames := make([]string, len(dataset.Variables))
+	for i, v := range dataset.Variables {
+		varNames[i] = v.Name
+	}
+
+	sql := fmt.Sprintf(`INSERT INTO %s (dataset_id, dataset_name, storage_name, description, folder, summary, summary_machine,
+			num_rows, num_bytes, source, dataset_version, parent_dataset, root_dataset, variables, search)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14::jsonb,
+			setweight(to_tsvector('%s', $1::text || ' ' || $2::text || ' ' || $5::text), 'A') ||
+			setweight(to_tsvector('%s', $15::text), 'B') ||
+			setweight(to_tsvector('%s', $4::text || ' ' || $7::text), 'C'))
+		ON CONFLICT (dataset_id) DO UPDATE SET
+			dataset_name = EXCLUDED.dataset_name, storage_name = EXCLUDED.storage_name,
+			description = EXCLUDED.description, folder = EXCLUDED.folder, summary = EXCLUDED.summary,
+			summary_machine = EXCLUDED.summary_machine, num_rows = EXCLUDED.num_rows, num_bytes = EXCLUDED.num_bytes,
+			source = EXCLUDED.source, dataset_version = EXCLUDED.dataset_version, parent_dataset = EXCLUDED.parent_dataset,
+			root_dataset = EXCLUDED.root_dataset, variables = EXCLUDED.variables, search = EXCLUDED.search;`,
+		datasetMetadataTableName, searchConfiguration, searchConfiguration, searchConfiguration)
+
+	_, err = s.client.Exec(sql, dataset.ID, dataset.Name, dataset.StorageName, dataset.Description, dataset.Folder,
+		dataset.Summary, dataset.SummaryML, dataset.NumRows, dataset.NumBytes, string(dataset.Source), dataset.Version,
+		dataset.Parent, dataset.Root, string(variables), strings.Join(varNames, " "))
+	if err != nil {
+		return errors.Wrapf(err, "unable to store dataset %s metadata", dataset.ID)
+	}
+
+	return nil
+}
+
+// ImportDataset is not supported (postgres datasets are already ingested).
+func (s *MetadataStorage) ImportDataset(id string, uri string) (string, error) {
+	return "", errors.Errorf("Not Supported")
+}
+
+// FetchDatasets returns all datasets.
+func (s *MetadataStorage) FetchDatasets(includeIndex bool, includeMeta bool) ([]*api.Dataset, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s ORDER BY dataset_id;", datasetMetadataFields, datasetMetadataTableName)
+
+	rows, err := s.client.Query(sql)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres dataset fetch query failed")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	return s.parseDatasets(rows, includeIndex, includeMeta)
+}
+
+// FetchDataset returns a dataset.
+func (s *MetadataStorage) FetchDataset(datasetName string, includeIndex bool, includeMeta bool) (*api.Dataset, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE dataset_id = $1;", datasetMetadataFields, datasetMetadataTableName)
+
+	rows, err := s.client.Query(sql, datasetName)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres dataset fetch query failed")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	datasets, err := s.parseDatasets(rows, includeIndex, includeMeta)
+	if err != nil {
+		return nil, err
+	}
+	if len(datasets) == 0 {
+		return nil, errors.Errorf("dataset %s not found", datasetName)
+	}
+	return datasets[0], nil
+}
+
+// SearchDatasets returns the datasets that match any of the search terms,
+// ranked by relevance. Empty terms match every dataset.
+func (s *MetadataStorage) SearchDatasets(terms string, includeIndex bool, includeMeta bool) ([]*api.Dataset, error) {
+	if strings.TrimSpace(terms) == "" {
+		return s.FetchDatasets(includeIndex, includeMeta)
+	}
+
+	sql := fmt.Sprintf("SELECT %s FROM %s, replace(plainto_tsquery('%s', $1)::text, ' & ', ' | ')::tsquery AS query WHERE search @@ query ORDER BY ts_rank(search, query) DESC, dataset_id;",
+		datasetMetadataFields, datasetMetadataTableName, searchConfiguration)
+
+	rows, err := s.client.Query(sql, terms)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres dataset search query failed")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	return s.parseDatasets(rows, includeIndex, includeMeta)
+}
+
+// DeleteDataset removes the dataset metadata.
+func (s *MetadataStorage) DeleteDataset(dataset string) error {
+	sql := fmt.Sprintf("DELETE FROM %s WHERE dataset_id = $1;", datasetMetadataTableName)
+
+	_, err := s.client.Exec(sql, dataset)
+	if err != nil {
+		return errors.Wrapf(err, "unable to delete dataset %s metadata", dataset)
+	}
+
+	return nil
+}
+
+func (s *MetadataStorage) parseDatasets(rows *pgx.Rows, includeIndex bool, includeMeta bool) ([]*api.Dataset, error) {
+	datasets := make([]*api.Dataset, 0)
+	for rows.Next() {
+		var id string
+		var name string
+		var storageName string
+		var description string
+		var folder string
+		var summary string
+		var summaryMachine string
+		var numRows int64
+		var numBytes int64
+		var source string
+		var version int
+		var parent string
+		var root string
+		var rawVariables string
+
+		err := rows.Scan(&id, &name, &storageName, &description, &folder, &summary, &summaryMachine,
+			&numRows, &numBytes, &source, &version, &parent, &root, &rawVariables)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse dataset from postgres")
+		}
+
+		variables, err := parseVariables(rawVariables, includeIndex, includeMeta)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse dataset %s variables", id)
+		}
+
+		if source == "" {
+			source = string(metadata.Seed)
+		}
+		if root == "" {
+			root = id
+		}
+
+		datasets = append(datasets, &api.Dataset{
+			ID:          id,
+			Name:        name,
+			StorageName: storageName,
+			Description: description,
+			Folder:      folder,
+			Summary:     summary,
+			SummaryML:   summaryMachine,
+			NumRows:     numRows,
+			NumBytes:    numBytes,
+			Variables:   variables,
+			Provenance:  Provenance,
+			Source:      metadata.DatasetSource(source),
+			Version:     version,
+			Parent:      parent,
+			Root:        root,
+		})
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read datasets from postgres")
+	}
+
+	return datasets, nil
+}
+
+func parseVariables(raw string, includeIndex bool, includeMeta bool) ([]*model.Variable, error) {
+	var stored []*model.Variable
+	err := json.Unmarshal([]byte(raw), &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := make([]*model.Variable, 0)
+	for _, variable := range stored {
+		if !includeIndex && len(variable.Role) > 0 && variable.Role[0] == model.VarRoleIndex {
+			continue
+		}
+		if !includeMeta && variable.DistilRole == model.VarRoleMetadata {
+			continue
+		}
+		if variable.DisplayName == "" {
+			variable.DisplayName = variable.Name
+		}
+		variables = append(variables, variable)
+	}
+
+	return variables, nil
+}
@@ -0,0 +1,192 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+// newTestMetadataStorage connects to the database named by PG_TEST_DATABASE,
+// using the PG_* settings for everything else, and skips the test when it is
+// not set so a development database is never written to.
+func newTestMetadataStorage(t *testing.T) *MetadataStorage {
+	database := os.Getenv("PG_TEST_DATABASE")
+	if database == "" {
+		t.Skip("PG_TEST_DATABASE not set")
+	}
+
+	config, err := env.LoadConfig()
+	assert.NoError(t, err)
+	client, err := postgres.NewClient(config.PostgresHost, config.PostgresPort, config.PostgresUser,
+		config.PostgresPassword, database, config.PostgresLogLevel)()
+	if err != nil {
+		t.Fatalf("unable to connect to the test database: %v", err)
+	}
+
+	storage := NewMetadataStorageFromClient(client)
+	assert.NoError(t, storage.CreateMetadataTables())
+	return storage
+}
+
+func newTestDataset(id string, name string, description string, varNames ...string) *api.Dataset {
+	variables := []*model.Variable{
+		{
+			Name:  model.D3MIndexName,
+			Index: 0,
+			Type:  model.IntegerType,
+			Role:  []string{model.VarRoleIndex},
+		},
+	}
+	for i, varName := range varNames {
+		variables = append(variables, &model.Variable{
+			Name:  varName,
+			Index: i + 1,
+			Type:  model.StringType,
+		})
+	}
+
+	return &api.Dataset{
+		ID:          id,
+		Name:        name,
+		StorageName: model.NormalizeDatasetID(id),
+		Description: description,
+		Variables:   variables,
+	}
+}
+
+func TestParseVariables(t *testing.T) {
+	raw, err := json.Marshal([]*model.Variable{
+		{Name: model.D3MIndexName, Role: []string{model.VarRoleIndex}},
+		{Name: "species", DisplayName: "Species"},
+		{Name: "_cluster", DistilRole: model.VarRoleMetadata},
+		{Name: "height"},
+	})
+	assert.NoError(t, err)
+
+	variables, err := parseVariables(string(raw), true, true)
+	assert.NoError(t, err)
+	assert.Len(t, variables, 4)
+	assert.Equal(t, "Species", variables[1].DisplayName)
+	assert.Equal(t, "height", variables[3].DisplayName)
+
+	variables, err = parseVariables(string(raw), false, false)
+	assert.NoError(t, err)
+	assert.Len(t, variables, 2)
+	assert.Equal(t, "species", variables[0].Name)
+	assert.Equal(t, "height", variables[1].Name)
+
+	_, err = parseVariables("{", true, true)
+	assert.Error(t, err)
+}
+
+func TestSearchDatasets(t *testing.T) {
+	storage := newTestMetadataStorage(t)
+
+	datasets := []*api.Dataset{
+		newTestDataset("metadata_test_flowers", "Flowers", "Measurements of iris petals", "species", "petal_length"),
+		newTestDataset("metadata_test_houses", "Houses", "Sale prices of houses", "price", "bedrooms"),
+		newTestDataset("metadata_test_petals", "Petals", "Colours of flowers", "colour"),
+	}
+	for _, dataset := range datasets {
+		assert.NoError(t, storage.IngestDataset(dataset))
+		defer storage.DeleteDataset(dataset.ID)
+	}
+
+	// the name weighs more than the description
+	found, err := storage.SearchDatasets("petals", false, false)
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+	assert.Equal(t, "metadata_test_petals", found[0].ID)
+	assert.Equal(t, "metadata_test_flowers", found[1].ID)
+
+	// any of the terms matches, stemmed, including variable names
+	found, err = storage.SearchDatasets("bedroom iris", false, false)
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	found, err = storage.SearchDatasets("unmatched", false, false)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	// re-ingesting replaces the search document
+	datasets[1].Description = "Sale prices of cottages"
+	assert.NoError(t, storage.IngestDataset(datasets[1]))
+	found, err = storage.SearchDatasets("cottage", false, false)
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+	assert.Equal(t, "metadata_test_houses", found[0].ID)
+
+	// the index variable is filtered out
+	dataset, err := storage.FetchDataset("metadata_test_houses", false, false)
+	assert.NoError(t, err)
+	assert.Len(t, dataset.Variables, 2)
+	assert.Equal(t, "metadata_test_houses", dataset.Root)
+
+	assert.NoError(t, storage.DeleteDataset("metadata_test_houses"))
+	_, err = storage.FetchDataset("metadata_test_houses", false, false)
+	assert.Error(t, err)
+}
+
+func TestMetadataVariables(t *testing.T) {
+	storage := newTestMetadataStorage(t)
+
+	dataset := newTestDataset("metadata_test_variables", "Variables", "", "species")
+	assert.NoError(t, storage.IngestDataset(dataset))
+	defer storage.DeleteDataset(dataset.ID)
+
+	exists, err := storage.DoesVariableExist(dataset.ID, "species")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.NoError(t, storage.AddVariable(dataset.ID, "species_label", model.CategoricalType, model.VarRoleMetadata))
+	variable, err := storage.FetchVariable(dataset.ID, "species_label")
+	assert.NoError(t, err)
+	assert.Equal(t, model.CategoricalType, variable.Type)
+	assert.Equal(t, 2, variable.Index)
+
+	// metadata variables are only included when requested
+	variables, err := storage.FetchVariables(dataset.ID, false, false)
+	assert.NoError(t, err)
+	assert.Len(t, variables, 1)
+	variables, err = storage.FetchVariables(dataset.ID, true, true)
+	assert.NoError(t, err)
+	assert.Len(t, variables, 3)
+
+	assert.NoError(t, storage.SetDataType(dataset.ID, "species", model.CategoricalType))
+	variable, err = storage.FetchVariable(dataset.ID, "species")
+	assert.NoError(t, err)
+	assert.Equal(t, model.CategoricalType, variable.Type)
+
+	// deleted variables are flagged rather than removed
+	assert.NoError(t, storage.DeleteVariable(dataset.ID, "species"))
+	variable, err = storage.FetchVariable(dataset.ID, "species")
+	assert.NoError(t, err)
+	assert.True(t, variable.Deleted)
+
+	_, err = storage.FetchVariable(dataset.ID, "unknown")
+	assert.Error(t, err)
+	_, err = storage.FetchVariables("metadata_test_unknown", true, true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+)
+
+// DoesVariableExist returns whether or not a variable exists.
+func (s *MetadataStorage) DoesVariableExist(dataset string, varName string) (bool, error) {
+	vars, err := s.FetchVariables(dataset, true, true)
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range vars {
+		if v.Name == varName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FetchVariable returns the variable for the provided dataset and variable.
+func (s *MetadataStorage) FetchVariable(dataset string, varName string) (*model.Variable, error) {
+	vars, err := s.FetchVariables(dataset, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range vars {
+		if v.Name == varName {
+			return v, nil
+		}
+	}
+	return nil, errors.Errorf("unable to find variable `%s`", varName)
+}
+
+// FetchVariableDisplay returns the display variable for the provided dataset
+// and variable.
+func (s *MetadataStorage) FetchVariableDisplay(dataset string, varName string) (*model.Variable, error) {
+	// get the indicated variable.
+	variable, err := s.FetchVariable(dataset, varName)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch variable")
+	}
+
+	// DisplayVariable will identify the variable to return.
+	// If not set, no other fetch is needed.
+	if variable.DisplayName != "" && variable.DisplayName != varName {
+		return s.FetchVariable(dataset, variable.DisplayName)
+	}
+
+	return variable, nil
+}
+
+// FetchVariables returns all the variables for the provided dataset.
+func (s *MetadataStorage) FetchVariables(dataset string, includeIndex bool, includeMeta bool) ([]*model.Variable, error) {
+	sql := fmt.Sprintf("SELECT variables::text FROM %s WHERE dataset_id = $1;", datasetMetadataTableName)
+
+	rows, err := s.client.Query(sql, dataset)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres variable fetch query failed")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if !rows.Next() {
+		return nil, errors.Errorf("dataset %s not found", dataset)
+	}
+	var raw string
+	err = rows.Scan(&raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse variables from postgres")
+	}
+
+	return parseVariables(raw, includeIndex, includeMeta)
+}
+
+// FetchVariablesDisplay returns all the display variables for the provided
+// dataset.
+func (s *MetadataStorage) FetchVariablesDisplay(dataset string) ([]*model.Variable, error) {
+	// get all variables.
+	vars, err := s.FetchVariables(dataset, false, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch dataset variables")
+	}
+
+	// only include a variable once.
+	resultIncludes := make(map[string]bool)
+	result := make([]*model.Variable, 0)
+	for _, v := range vars {
+		if !resultIncludes[v.Name] {
+			result = append(result, v)
+			resultIncludes[v.Name] = true
+		}
+	}
+
+	return result, nil
+}
+
+func (s *MetadataStorage) updateVariables(dataset string, variables []*model.Variable) error {
+	serialized, err := json.Marshal(variables)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal dataset variables")
+	}
+
+	sql := fmt.Sprintf("UPDATE %s SET variables = $1::jsonb WHERE dataset_id = $2;", datasetMetadataTableName)
+	_, err = s.client.Exec(sql, string(serialized), dataset)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update variables of dataset `%s`", dataset)
+	}
+
+	return nil
+}
+
+// SetDataType updates the data type of the variable.
+func (s *MetadataStorage) SetDataType(dataset string, varName string, varType string) error {
+	// Fetch all existing variables
+	vars, err := s.FetchVariables(dataset, true, true)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch existing variable")
+	}
+
+	// Update only the variable we care about
+	for _, v := range vars {
+		if v.Name == varName {
+			v.Type = varType
+		}
+	}
+
+	return s.updateVariables(dataset, vars)
+}
+
+// AddVariable adds a new variable to the dataset.
+func (s *MetadataStorage) AddVariable(dataset string, varName string, varType string, varRole string) error {
+	// query for existing variables
+	vars, err := s.FetchVariables(dataset, true, true)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch existing variable")
+	}
+
+	// add the new variables
+	vars = append(vars, &model.Variable{
+		Name:             varName,
+		Index:            len(vars),
+		Type:             varType,
+		OriginalType:     varType,
+		OriginalVariable: varName,
+		DisplayName:      varName,
+		DistilRole:       varRole,
+		SuggestedTypes:   make([]*model.SuggestedType, 0),
+	})
+
+	return s.updateVariables(dataset, vars)
+}
+
+// DeleteVariable flags a variable as deleted.
+func (s *MetadataStorage) DeleteVariable(dataset string, varName string) error {
+	// query for existing variables
+	vars, err := s.FetchVariables(dataset, true, true)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch existing variable")
+	}
+
+	// soft delete the variable
+	for _, v := range vars {
+		if v.Name == varName {
+			v.Deleted = true
+		}
+	}
+
+	return s.updateVariables(dataset, vars)
+}
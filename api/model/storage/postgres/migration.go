@@ -0,0 +1,294 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+const (
+	schemaVersionTableName = "solution_schema_version"
+)
+
+// migration is a single versioned change to the solution metadata tables.
+type migration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+// solutionMigrations lists the changes to the solution metadata tables in the
+// order they are applied. Applied migrations must never be edited; schema
+// changes are made by appending a new migration with the next version.
+var solutionMigrations = []*migration{
+	{
+		version:     1,
+		description: "create solution metadata tables",
+		statements: []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				request_id        TEXT,
+				dataset           TEXT,
+				progress          TEXT,
+				created_time      TIMESTAMP,
+				last_updated_time TIMESTAMP
+			);`, requestTableName),
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				request_id   TEXT,
+				feature_name TEXT,
+				feature_type TEXT
+			);`, featureTableName),
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				request_id        TEXT,
+				feature_name      TEXT,
+				filter_type       TEXT,
+				filter_mode       TEXT,
+				filter_min        DOUBLE PRECISION,
+				filter_max        DOUBLE PRECISION,
+				filter_categories TEXT,
+				filter_indices    TEXT
+			);`, filterTableName),
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				request_id   TEXT,
+				solution_id  TEXT,
+				progress     TEXT,
+				created_time TIMESTAMP
+			);`, solutionTableName),
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				solution_id        TEXT,
+				fitted_solution_id TEXT,
+				result_uuid        TEXT,
+				result_uri         TEXT,
+				progress           TEXT,
+				created_time       TIMESTAMP
+			);`, solutionResultTableName),
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				solution_id TEXT,
+				metric      TEXT,
+				score       DOUBLE PRECISION
+			);`, solutionScoreTableName),
+		},
+	},
+	{
+		version:     2,
+		description: "add bivariate bounds to request filters",
+		statements: []string{
+			fmt.Sprintf(`ALTER TABLE %s
+				ADD COLUMN IF NOT EXISTS filter_min_x DOUBLE PRECISION DEFAULT 0,
+				ADD COLUMN IF NOT EXISTS filter_max_x DOUBLE PRECISION DEFAULT 0,
+				ADD COLUMN IF NOT EXISTS filter_min_y DOUBLE PRECISION DEFAULT 0,
+				ADD COLUMN IF NOT EXISTS filter_max_y DOUBLE PRECISION DEFAULT 0;`, filterTableName),
+		},
+	},
+	{
+		version:     3,
+		description: "index solution metadata lookups",
+		statements: []string{
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_request_id_idx ON %s (request_id);", requestTableName, requestTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_dataset_idx ON %s (dataset);", requestTableName, requestTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_request_id_idx ON %s (request_id);", featureTableName, featureTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_request_id_idx ON %s (request_id);", filterTableName, filterTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_request_id_idx ON %s (request_id);", solutionTableName, solutionTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_solution_id_idx ON %s (solution_id);", solutionTableName, solutionTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_solution_id_idx ON %s (solution_id);", solutionResultTableName, solutionResultTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_solution_id_idx ON %s (solution_id);", solutionScoreTableName, solutionScoreTableName),
+		},
+	},
+	{
+		version:     4,
+		description: "add cross validation folds to solution scores",
+		statements: []string{
+			fmt.Sprintf(`ALTER TABLE %s
+				ADD COLUMN IF NOT EXISTS score_type TEXT NOT NULL DEFAULT 'holdOut',
+				ADD COLUMN IF NOT EXISTS fold INTEGER NOT NULL DEFAULT 0;`, solutionScoreTableName),
+		},
+	},
+	{
+		version:     5,
+		description: "create prediction table",
+		statements: []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				result_uuid        TEXT PRIMARY KEY,
+				solution_id        TEXT NOT NULL,
+				fitted_solution_id TEXT NOT NULL,
+				dataset            TEXT NOT NULL,
+				result_uri         TEXT NOT NULL,
+				progress           TEXT NOT NULL,
+				created_time       TIMESTAMP NOT NULL
+			);`, predictionTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_solution_id_idx ON %s (solution_id);", predictionTableName, predictionTableName),
+		},
+	},
+	{
+		version:     6,
+		description: "create request state table",
+		statements: []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				request_id   TEXT PRIMARY KEY,
+				resume_state TEXT NOT NULL DEFAULT '',
+				error_reason TEXT NOT NULL DEFAULT ''
+			);`, requestStateTableName),
+		},
+	},
+	{
+		version:     7,
+		description: "create solution flag table",
+		statements: []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				solution_id  TEXT NOT NULL,
+				check_name   TEXT NOT NULL,
+				reason       TEXT NOT NULL,
+				created_time TIMESTAMP NOT NULL
+			);`, solutionFlagTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_solution_id_idx ON %s (solution_id);", solutionFlagTableName, solutionFlagTableName),
+		},
+	},
+	{
+		version:     8,
+		description: "create baseline score table",
+		statements: []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				request_id TEXT NOT NULL,
+				baseline   TEXT NOT NULL,
+				metric     TEXT NOT NULL,
+				score      DOUBLE PRECISION NOT NULL
+			);`, baselineScoreTableName),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_request_id_idx ON %s (request_id);", baselineScoreTableName, baselineScoreTableName),
+		},
+	},
+	{
+		version:     9,
+		description: "create result target order table",
+		statements: []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				result_id    TEXT NOT NULL,
+				target       TEXT NOT NULL,
+				target_index INTEGER NOT NULL,
+				PRIMARY KEY (result_id, target)
+			);`, resultTargetTableName),
+		},
+	},
+	{
+		version:     10,
+		description: "create request split table",
+		statements: []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				request_id TEXT PRIMARY KEY,
+				split      TEXT NOT NULL
+			);`, requestSplitTableName),
+		},
+	},
+}
+
+// SolutionSchemaVersion is the version of the solution metadata tables
+// expected by this build.
+func SolutionSchemaVersion() int {
+	return solutionMigrations[len(solutionMigrations)-1].version
+}
+
+// MigrateSolutionTables creates or upgrades the solution metadata tables to
+// the expected schema version, recording each applied version. Migrations run
+// in a single locked transaction so concurrent callers wait for the first one
+// to finish and a failed migration leaves the schema untouched. An error is
+// returned if the database was migrated by a newer build.
+func MigrateSolutionTables(client postgres.DatabaseDriver) error {
+	tx, err := client.Begin()
+	if err != nil {
+		return errors.Wrap(err, "Unable to start transaction")
+	}
+	// rolling back a committed transaction is a no-op
+	defer tx.Rollback()
+
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1));", schemaVersionTableName)
+	if err != nil {
+		return errors.Wrap(err, "Unable to lock solution schema")
+	}
+
+	sql := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		version      INTEGER PRIMARY KEY,
+		description  TEXT NOT NULL,
+		applied_time TIMESTAMP NOT NULL
+	);`, schemaVersionTableName)
+	_, err = tx.Exec(sql)
+	if err != nil {
+		return errors.Wrap(err, "Unable to create solution schema version table")
+	}
+
+	current, err := fetchSchemaVersion(tx)
+	if err != nil {
+		return err
+	}
+
+	expected := SolutionSchemaVersion()
+	if current > expected {
+		return errors.Errorf("solution schema version %d is newer than the supported version %d", current, expected)
+	}
+
+	for _, m := range pendingMigrations(current) {
+		log.Infof("applying solution schema migration %d (%s)", m.version, m.description)
+		for _, statement := range m.statements {
+			_, err = tx.Exec(statement)
+			if err != nil {
+				return errors.Wrapf(err, "Unable to apply solution schema migration %d", m.version)
+			}
+		}
+
+		sql = fmt.Sprintf("INSERT INTO %s (version, description, applied_time) VALUES ($1, $2, $3);", schemaVersionTableName)
+		_, err = tx.Exec(sql, m.version, m.description, time.Now())
+		if err != nil {
+			return errors.Wrapf(err, "Unable to record solution schema migration %d", m.version)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "Unable to commit transaction")
+	}
+
+	if current < expected {
+		log.Infof("solution schema migrated from version %d to %d", current, expected)
+	}
+
+	return nil
+}
+
+func fetchSchemaVersion(tx *pgx.Tx) (int, error) {
+	sql := fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s;", schemaVersionTableName)
+
+	var version int
+	err := tx.QueryRow(sql).Scan(&version)
+	if err != nil {
+		return 0, errors.Wrap(err, "Unable to read solution schema version")
+	}
+
+	return version, nil
+}
+
+func pendingMigrations(current int) []*migration {
+	pending := make([]*migration, 0)
+	for _, m := range solutionMigrations {
+		if m.version > current {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
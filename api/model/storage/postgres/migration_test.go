@@ -0,0 +1,40 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolutionMigrationsAreSequential(t *testing.T) {
+	for i, m := range solutionMigrations {
+		assert.Equal(t, i+1, m.version)
+		assert.NotEmpty(t, m.description)
+		assert.NotEmpty(t, m.statements)
+	}
+	assert.Equal(t, len(solutionMigrations), SolutionSchemaVersion())
+}
+
+func TestPendingMigrations(t *testing.T) {
+	assert.Len(t, pendingMigrations(0), len(solutionMigrations))
+	assert.Empty(t, pendingMigrations(SolutionSchemaVersion()))
+
+	pending := pendingMigrations(1)
+	assert.Len(t, pending, len(solutionMigrations)-1)
+	assert.Equal(t, 2, pending[0].version)
+}
@@ -138,7 +138,7 @@ func (f *NumericalField) fetchHistogramByResult(resultURI string, filterParams *
 	fromClause := f.getFromClause(false)
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, filterParams)
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, "", filterParams)
 	if err != nil {
 		return nil, err
 	}
@@ -167,12 +167,12 @@ func (f *NumericalField) fetchHistogramByResult(resultURI string, filterParams *
 	// Create the complete query string.
 	query := fmt.Sprintf(`
 		SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(*) AS count
-		FROM %s data INNER JOIN %s result ON data."%s" = result.index
+		FROM %s data INNER JOIN %s result ON data.%s = result.index
 		WHERE result.result_id = $%d %s
 		GROUP BY %s
 		ORDER BY %s;`,
 		bucketQuery, histogramQuery, histogramName, fromClause,
-		f.Storage.getResultTable(f.StorageName), model.D3MIndexFieldName, len(params), where, bucketQuery, histogramName)
+		quoteIdentifier(f.Storage.getResultTable(f.StorageName)), quoteIdentifier(model.D3MIndexFieldName), len(params), where, bucketQuery, histogramName)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -212,17 +212,17 @@ func (f *NumericalField) getHistogramAggQuery(extrema *api.Extrema) (string, str
 	interval := extrema.GetBucketInterval()
 
 	// get histogram agg name & query string.
-	histogramAggName := fmt.Sprintf("\"%s%s\"", api.HistogramAggPrefix, extrema.Key)
+	histogramAggName := quoteIdentifier(api.HistogramAggPrefix + extrema.Key)
 	rounded := extrema.GetBucketMinMax()
 
 	bucketQueryString := ""
 	// if only a single value, then return a simple count.
 	if rounded.Max == rounded.Min {
 		// want to return the count under bucket 0.
-		bucketQueryString = fmt.Sprintf("(\"%s\" - \"%s\")", extrema.Key, extrema.Key)
+		bucketQueryString = fmt.Sprintf("(%s - %s)", quoteIdentifier(extrema.Key), quoteIdentifier(extrema.Key))
 	} else {
-		bucketQueryString = fmt.Sprintf("width_bucket(\"%s\", %g, %g, %d) - 1",
-			extrema.Key, rounded.Min, rounded.Max, extrema.GetBucketCount())
+		bucketQueryString = fmt.Sprintf("width_bucket(%s, %g, %g, %d) - 1",
+			quoteIdentifier(extrema.Key), rounded.Min, rounded.Max, extrema.GetBucketCount())
 	}
 
 	histogramQueryString := fmt.Sprintf("(%s) * %g + %g", bucketQueryString, interval, rounded.Min)
@@ -322,8 +322,8 @@ func (f *NumericalField) getMinMaxAggsQuery() string {
 	maxAggName := api.MaxAggPrefix + f.Variable.Name
 
 	// create aggregations
-	queryPart := fmt.Sprintf("MIN(\"%s\") AS \"%s\", MAX(\"%s\") AS \"%s\"",
-		f.Variable.Name, minAggName, f.Variable.Name, maxAggName)
+	queryPart := fmt.Sprintf("MIN(%s) AS %s, MAX(%s) AS %s",
+		quoteIdentifier(f.Variable.Name), quoteIdentifier(minAggName), quoteIdentifier(f.Variable.Name), quoteIdentifier(maxAggName))
 	// add aggregations
 	return queryPart
 }
@@ -335,8 +335,8 @@ func (f *NumericalField) fetchExtremaByURI(resultURI string) (*api.Extrema, erro
 	aggQuery := f.getMinMaxAggsQuery()
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s data INNER JOIN %s result ON data.\"%s\" = result.index WHERE result.result_id = $1;",
-		aggQuery, fromClause, f.Storage.getResultTable(f.StorageName), model.D3MIndexFieldName)
+	queryString := fmt.Sprintf("SELECT %s FROM %s data INNER JOIN %s result ON data.%s = result.index WHERE result.result_id = $1;",
+		aggQuery, fromClause, quoteIdentifier(f.Storage.getResultTable(f.StorageName)), quoteIdentifier(model.D3MIndexFieldName))
 
 	// execute the postgres query
 	// NOTE: We may want to use the regular Query operation since QueryRow
@@ -376,23 +376,23 @@ func (f *NumericalField) FetchPredictedSummaryData(resultURI string, datasetResu
 	histogramName, bucketQuery, histogramQuery := f.getResultHistogramAggQuery(extrema, resultVariable)
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, filterParams)
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, f.Variable.Name, filterParams)
 	if err != nil {
 		return nil, err
 	}
 
-	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d AND result.target = $%d ", len(params)+1, len(params)+2))
-	params = append(params, resultURI, f.Variable.Name)
+	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d ", len(params)+1))
+	params = append(params, resultURI)
 
 	// Create the complete query string.
 	query := fmt.Sprintf(`
 		SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(*) AS count
-		FROM %s data INNER JOIN %s result ON data."%s" = result.index
+		FROM %s data INNER JOIN %s result ON data.%s = result.index
 		WHERE %s
 		GROUP BY %s
 		ORDER BY %s;`,
-		bucketQuery, histogramQuery, histogramName, f.StorageName, datasetResult,
-		model.D3MIndexFieldName, strings.Join(wheres, " AND "), bucketQuery, histogramName)
+		bucketQuery, histogramQuery, histogramName, quoteIdentifier(f.StorageName), quoteIdentifier(datasetResult),
+		quoteIdentifier(model.D3MIndexFieldName), strings.Join(wheres, " AND "), bucketQuery, histogramName)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -410,10 +410,10 @@ func (f *NumericalField) getResultMinMaxAggsQuery(resultVariable *model.Variable
 	maxAggName := api.MaxAggPrefix + resultVariable.Name
 
 	// Only numeric types should occur.
-	fieldTyped := fmt.Sprintf("cast(\"%s\" as double precision)", resultVariable.Name)
+	fieldTyped := fmt.Sprintf("cast(%s as double precision)", quoteIdentifier(resultVariable.Name))
 
 	// create aggregations
-	queryPart := fmt.Sprintf("MIN(%s) AS \"%s\", MAX(%s) AS \"%s\"", fieldTyped, minAggName, fieldTyped, maxAggName)
+	queryPart := fmt.Sprintf("MIN(%s) AS %s, MAX(%s) AS %s", fieldTyped, quoteIdentifier(minAggName), fieldTyped, quoteIdentifier(maxAggName))
 	// add aggregations
 	return queryPart
 }
@@ -423,17 +423,17 @@ func (f *NumericalField) getResultHistogramAggQuery(extrema *api.Extrema, result
 	interval := extrema.GetBucketInterval()
 
 	// Only numeric types should occur.
-	fieldTyped := fmt.Sprintf("cast(\"%s\" as double precision)", resultVariable.Name)
+	fieldTyped := fmt.Sprintf("cast(%s as double precision)", quoteIdentifier(resultVariable.Name))
 
 	// get histogram agg name & query string.
-	histogramAggName := fmt.Sprintf("\"%s%s\"", api.HistogramAggPrefix, extrema.Key)
+	histogramAggName := quoteIdentifier(api.HistogramAggPrefix + extrema.Key)
 	rounded := extrema.GetBucketMinMax()
 
 	bucketQueryString := ""
 	// if only a single value, then return a simple count.
 	if rounded.Max == rounded.Min {
 		// want to return the count under bucket 0.
-		bucketQueryString = fmt.Sprintf("(\"%s\" - \"%s\")", fieldTyped, fieldTyped)
+		bucketQueryString = fmt.Sprintf("(%s - %s)", fieldTyped, fieldTyped)
 	} else {
 		bucketQueryString = fmt.Sprintf("width_bucket(%s, %g, %g, %d) - 1",
 			fieldTyped, rounded.Min, rounded.Max, extrema.GetBucketCount())
@@ -448,7 +448,7 @@ func (f *NumericalField) fetchResultsExtrema(resultURI string, dataset string, r
 	aggQuery := f.getResultMinMaxAggsQuery(resultVariable)
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s WHERE result_id = $1 AND target = $2;", aggQuery, dataset)
+	queryString := fmt.Sprintf("SELECT %s FROM %s WHERE result_id = $1 AND target = $2;", aggQuery, quoteIdentifier(dataset))
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(queryString, resultURI, f.Variable.Name)
@@ -475,7 +475,7 @@ func (f *NumericalField) FetchNumericalStats(filterParams *api.FilterParams) (*N
 	}
 
 	// Create the complete query string.
-	query := fmt.Sprintf("SELECT coalesce(stddev(\"%s\"), 0) as stddev, avg(\"%s\") as avg FROM %s %s;", f.Variable.Name, f.Variable.Name, fromClause, where)
+	query := fmt.Sprintf("SELECT coalesce(stddev(%s), 0) as stddev, avg(%s) as avg FROM %s %s;", quoteIdentifier(f.Variable.Name), quoteIdentifier(f.Variable.Name), fromClause, where)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -494,7 +494,7 @@ func (f *NumericalField) FetchNumericalStatsByResult(resultURI string, filterPar
 	fromClause := f.getFromClause(false)
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, filterParams)
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, "", filterParams)
 	if err != nil {
 		return nil, err
 	}
@@ -507,8 +507,8 @@ func (f *NumericalField) FetchNumericalStatsByResult(resultURI string, filterPar
 	}
 
 	// Create the complete query string.
-	query := fmt.Sprintf("SELECT coalesce(stddev(\"%s\"), 0) as stddev, avg(\"%s\") as avg FROM %s data INNER JOIN %s result ON data.\"%s\" = result.index WHERE result.result_id = $%d %s;",
-		f.Variable.Name, f.Variable.Name, fromClause, f.Storage.getResultTable(f.StorageName), model.D3MIndexFieldName, len(params), where)
+	query := fmt.Sprintf("SELECT coalesce(stddev(%s), 0) as stddev, avg(%s) as avg FROM %s data INNER JOIN %s result ON data.%s = result.index WHERE result.result_id = $%d %s;",
+		quoteIdentifier(f.Variable.Name), quoteIdentifier(f.Variable.Name), fromClause, quoteIdentifier(f.Storage.getResultTable(f.StorageName)), quoteIdentifier(model.D3MIndexFieldName), len(params), where)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -559,11 +559,11 @@ func (f *NumericalField) parseStats(row *pgx.Rows) (*NumericalStats, error) {
 }
 
 func (f *NumericalField) getFromClause(alias bool) string {
-	fromClause := f.StorageName
+	fromClause := quoteIdentifier(f.StorageName)
 	if f.subSelect != nil {
 		fromClause = f.subSelect()
 		if alias {
-			fromClause = fmt.Sprintf("%s as nested INNER JOIN %s as data on nested.\"%s\" = data.\"%s\"", fromClause, f.StorageName, model.D3MIndexFieldName, model.D3MIndexFieldName)
+			fromClause = fmt.Sprintf("%s as nested INNER JOIN %s as data on nested.%s = data.%s", fromClause, quoteIdentifier(f.StorageName), quoteIdentifier(model.D3MIndexFieldName), quoteIdentifier(model.D3MIndexFieldName))
 		}
 	}
 
@@ -17,13 +17,11 @@ package postgres
 
 import (
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
 
-	"github.com/uncharted-distil/distil-compute/model"
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
 	api "github.com/uncharted-distil/distil/api/model"
 )
@@ -48,56 +46,52 @@ func (s *Storage) PersistSolutionResult(solutionID string, fittedSolutionID stri
 
 // PersistSolutionScore persist the solution score to Postgres.
 func (s *Storage) PersistSolutionScore(solutionID string, metric string, score float64) error {
-	sql := fmt.Sprintf("INSERT INTO %s (solution_id, metric, score) VALUES ($1, $2, $3);", solutionScoreTableName)
+	return s.PersistSolutionFoldScore(solutionID, metric, api.ScoreTypeHoldOut, 0, score)
+}
+
+// PersistSolutionFoldScore persists a cross validation score to Postgres. The
+// fold is only meaningful for per fold scores.
+func (s *Storage) PersistSolutionFoldScore(solutionID string, metric string, scoreType string, fold int, score float64) error {
+	sql := fmt.Sprintf("INSERT INTO %s (solution_id, metric, score_type, fold, score) VALUES ($1, $2, $3, $4, $5);", solutionScoreTableName)
 
-	_, err := s.client.Exec(sql, solutionID, metric, score)
+	_, err := s.client.Exec(sql, solutionID, metric, scoreType, fold, score)
 
 	return err
 }
 
-func (s *Storage) isBadSolution(solution *api.Solution) (bool, error) {
+// PersistSolutionFlag persists a failed solution quality check to Postgres.
+func (s *Storage) PersistSolutionFlag(solutionID string, check string, reason string) error {
+	sql := fmt.Sprintf("INSERT INTO %s (solution_id, check_name, reason, created_time) VALUES ($1, $2, $3, $4);", solutionFlagTableName)
 
-	if solution.Result == nil {
-		return false, nil
-	}
+	_, err := s.client.Exec(sql, solutionID, check, reason, time.Now())
 
-	request, err := s.FetchRequest(solution.RequestID)
-	if err != nil {
-		return false, err
-	}
+	return err
+}
 
-	dataset := request.Dataset
-	storageName := model.NormalizeDatasetID(request.Dataset)
-	target := request.TargetFeature()
+// FetchSolutionFlags pulls the failed quality checks of a solution from
+// Postgres.
+func (s *Storage) FetchSolutionFlags(solutionID string) ([]*api.SolutionFlag, error) {
+	sql := fmt.Sprintf("SELECT check_name, reason FROM %s WHERE solution_id = $1 ORDER BY created_time, check_name;", solutionFlagTableName)
 
-	// check target var type
-	variable, err := s.metadata.FetchVariable(dataset, target)
+	rows, err := s.client.Query(sql, solutionID)
 	if err != nil {
-		return false, err
-	}
-
-	if !model.IsNumerical(variable.Type) {
-		return false, nil
+		return nil, errors.Wrap(err, "Unable to pull solution flags from Postgres")
 	}
-	f := NewNumericalField(s, storageName, variable)
-
-	// predicted extrema
-	predictedExtrema, err := s.FetchResultsExtremaByURI(dataset, storageName, solution.Result.ResultURI)
-	if err != nil {
-		return false, err
+	if rows != nil {
+		defer rows.Close()
 	}
 
-	// result mean and stddev
-	stats, err := f.FetchNumericalStats(&api.FilterParams{})
-	if err != nil {
-		return false, err
+	flags := make([]*api.SolutionFlag, 0)
+	for rows.Next() {
+		flag := &api.SolutionFlag{}
+		err = rows.Scan(&flag.Check, &flag.Reason)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse solution flag from Postgres")
+		}
+		flags = append(flags, flag)
 	}
 
-	minDiff := math.Abs(predictedExtrema.Min - stats.Mean)
-	maxDiff := math.Abs(predictedExtrema.Max - stats.Mean)
-	numStdDevs := 10.0
-
-	return minDiff > (numStdDevs*stats.StdDev) || maxDiff > (numStdDevs*stats.StdDev), nil
+	return flags, nil
 }
 
 // FetchSolution pulls solution information from Postgres.
@@ -113,18 +107,7 @@ func (s *Storage) FetchSolution(solutionID string) (*api.Solution, error) {
 	}
 	rows.Next()
 
-	solution, err := s.parseSolution(rows)
-	if err != nil {
-		return nil, err
-	}
-
-	isBad, err := s.isBadSolution(solution)
-	if err != nil {
-		return nil, err
-	}
-
-	solution.IsBad = isBad
-	return solution, nil
+	return s.parseSolution(rows)
 }
 
 func (s *Storage) parseSolution(rows *pgx.Rows) (*api.Solution, error) {
@@ -148,6 +131,11 @@ func (s *Storage) parseSolution(rows *pgx.Rows) (*api.Solution, error) {
 		return nil, errors.Wrap(err, "Unable to parse solution scores from Postgres")
 	}
 
+	flags, err := s.FetchSolutionFlags(solutionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse solution flags from Postgres")
+	}
+
 	return &api.Solution{
 		RequestID:   requestID,
 		SolutionID:  solutionID,
@@ -155,6 +143,8 @@ func (s *Storage) parseSolution(rows *pgx.Rows) (*api.Solution, error) {
 		CreatedTime: createdTime,
 		Result:      result,
 		Scores:      scores,
+		Flags:       flags,
+		IsBad:       len(flags) > 0,
 	}, nil
 }
 
@@ -218,7 +208,8 @@ func (s *Storage) FetchSolutionResult(solutionID string) (*api.SolutionResult, e
 	return res, nil
 }
 
-// FetchSolutionResultByUUID pulls solution result information from Postgres.
+// FetchSolutionResultByUUID pulls solution result information from Postgres,
+// including the predictions made on other datasets.
 func (s *Storage) FetchSolutionResultByUUID(resultUUID string) (*api.SolutionResult, error) {
 	sql := fmt.Sprintf("SELECT result.solution_id, result.fitted_solution_id, result.result_uuid, "+
 		"result.result_uri, result.progress, result.created_time, request.dataset "+
@@ -239,17 +230,35 @@ func (s *Storage) FetchSolutionResultByUUID(resultUUID string) (*api.SolutionRes
 		return nil, errors.Wrap(err, "Unable to parse solution results from Postgres")
 	}
 
-	var res *api.SolutionResult
 	if results != nil && len(results) > 0 {
-		res = results[0]
+		return results[0], nil
 	}
 
-	return res, nil
+	// predictions made on other datasets are results of the solution too
+	return s.fetchPredictionResult(resultUUID)
+}
+
+// getMetricLabel returns the label of a stored metric, including the metrics
+// scored outside of the TA2 system.
+func getMetricLabel(metric string) string {
+	if metric == api.MetricMeanAbsolutePercentageError {
+		return "MAPE"
+	}
+	return compute.GetMetricLabel(metric)
+}
+
+// getMetricScoreMultiplier returns the multiplier sorting the scores of a
+// stored metric from best to worst.
+func getMetricScoreMultiplier(metric string) float64 {
+	if metric == api.MetricMeanAbsolutePercentageError {
+		return -1
+	}
+	return compute.GetMetricScoreMultiplier(metric)
 }
 
 // FetchSolutionScores pulls solution score from Postgres.
 func (s *Storage) FetchSolutionScores(solutionID string) ([]*api.SolutionScore, error) {
-	sql := fmt.Sprintf("SELECT solution_id, metric, score FROM %s WHERE solution_id = $1;", solutionScoreTableName)
+	sql := fmt.Sprintf("SELECT solution_id, metric, score_type, fold, score FROM %s WHERE solution_id = $1 ORDER BY score_type, fold;", solutionScoreTableName)
 
 	rows, err := s.client.Query(sql, solutionID)
 	if err != nil {
@@ -263,9 +272,11 @@ func (s *Storage) FetchSolutionScores(solutionID string) ([]*api.SolutionScore,
 	for rows.Next() {
 		var solutionID string
 		var metric string
+		var scoreType string
+		var fold int
 		var score float64
 
-		err = rows.Scan(&solutionID, &metric, &score)
+		err = rows.Scan(&solutionID, &metric, &scoreType, &fold, &score)
 		if err != nil {
 			return nil, errors.Wrap(err, "Unable to parse result score from Postgres")
 		}
@@ -273,9 +284,11 @@ func (s *Storage) FetchSolutionScores(solutionID string) ([]*api.SolutionScore,
 		results = append(results, &api.SolutionScore{
 			SolutionID:     solutionID,
 			Metric:         metric,
-			Label:          compute.GetMetricLabel(metric),
+			Label:          getMetricLabel(metric),
 			Score:          score,
-			SortMultiplier: compute.GetMetricScoreMultiplier(metric),
+			SortMultiplier: getMetricScoreMultiplier(metric),
+			Type:           scoreType,
+			Fold:           fold,
 		})
 	}
 
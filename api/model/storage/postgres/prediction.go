@@ -0,0 +1,83 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// PersistPrediction persists the prediction metadata to Postgres.
+func (s *Storage) PersistPrediction(resultUUID string, solutionID string, fittedSolutionID string, dataset string, resultURI string, progress string, createdTime time.Time) error {
+	sql := fmt.Sprintf("INSERT INTO %s (result_uuid, solution_id, fitted_solution_id, dataset, result_uri, progress, created_time) VALUES ($1, $2, $3, $4, $5, $6, $7) "+
+		"ON CONFLICT (result_uuid) DO UPDATE SET progress = EXCLUDED.progress, created_time = EXCLUDED.created_time;", predictionTableName)
+
+	_, err := s.client.Exec(sql, resultUUID, solutionID, fittedSolutionID, dataset, resultURI, progress, createdTime)
+
+	return err
+}
+
+// FetchPrediction pulls prediction information from Postgres.
+func (s *Storage) FetchPrediction(resultUUID string) (*api.Prediction, error) {
+	sql := fmt.Sprintf("SELECT result_uuid, solution_id, fitted_solution_id, dataset, result_uri, progress, created_time FROM %s WHERE result_uuid = $1;", predictionTableName)
+
+	rows, err := s.client.Query(sql, resultUUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull prediction from Postgres")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	prediction := &api.Prediction{}
+	err = rows.Scan(&prediction.ResultUUID, &prediction.SolutionID, &prediction.FittedSolutionID, &prediction.Dataset,
+		&prediction.ResultURI, &prediction.Progress, &prediction.CreatedTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse prediction from Postgres")
+	}
+
+	return prediction, nil
+}
+
+// fetchPredictionResult pulls a prediction from Postgres as a solution result
+// of the dataset the predictions were made on.
+func (s *Storage) fetchPredictionResult(resultUUID string) (*api.SolutionResult, error) {
+	prediction, err := s.FetchPrediction(resultUUID)
+	if err != nil {
+		return nil, err
+	}
+	if prediction == nil {
+		return nil, nil
+	}
+
+	return &api.SolutionResult{
+		SolutionID:       prediction.SolutionID,
+		FittedSolutionID: prediction.FittedSolutionID,
+		ResultURI:        prediction.ResultURI,
+		ResultUUID:       prediction.ResultUUID,
+		Progress:         prediction.Progress,
+		CreatedTime:      prediction.CreatedTime,
+		Dataset:          prediction.Dataset,
+	}, nil
+}
@@ -55,7 +55,7 @@ func (s *Storage) PersistRequestFeature(requestID string, featureName string, fe
 
 // PersistRequestFilters persists request filters information to Postgres.
 func (s *Storage) PersistRequestFilters(requestID string, filters *api.FilterParams) error {
-	sql := fmt.Sprintf("INSERT INTO %s (request_id, feature_name, filter_type, filter_mode, filter_min, filter_max, filter_min_x, filter_max_x, filter_min_y, filter_max_y, filter_categories, filter_indices) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);", filterTableName)
+	sql := fmt.Sprintf("INSERT INTO %s (request_id, feature_name, filter_type, filter_mode, filter_min, filter_max, filter_min_x, filter_max_x, filter_min_y, filter_max_y, filter_categories, filter_indices) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);", filterTableName)
 
 	for _, filter := range filters.Filters {
 		switch filter.Type {
@@ -84,6 +84,41 @@ func (s *Storage) PersistRequestFilters(requestID string, filters *api.FilterPar
 	return nil
 }
 
+// PersistRequestSplit persists the train / test split of a request, encoded as
+// JSON, to Postgres.
+func (s *Storage) PersistRequestSplit(requestID string, split string) error {
+	sql := fmt.Sprintf("INSERT INTO %s (request_id, split) VALUES ($1, $2) "+
+		"ON CONFLICT (request_id) DO UPDATE SET split = EXCLUDED.split;", requestSplitTableName)
+
+	_, err := s.client.Exec(sql, requestID, split)
+
+	return err
+}
+
+// fetchRequestSplit pulls the split of a request from Postgres, returning nil
+// if the request uses the default split.
+func (s *Storage) fetchRequestSplit(requestID string) ([]byte, error) {
+	sql := fmt.Sprintf("SELECT split FROM %s WHERE request_id = $1;", requestSplitTableName)
+
+	rows, err := s.client.Query(sql, requestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull request split from Postgres")
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	var split string
+	err = rows.Scan(&split)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse request split from Postgres")
+	}
+
+	return []byte(split), nil
+}
+
 // FetchRequest pulls request information from Postgres.
 func (s *Storage) FetchRequest(requestID string) (*api.Request, error) {
 	sql := fmt.Sprintf("SELECT request_id, dataset, progress, created_time, last_updated_time FROM %s WHERE request_id = $1 ORDER BY created_time desc LIMIT 1;", requestTableName)
@@ -141,6 +176,11 @@ func (s *Storage) loadRequest(rows *pgx.Rows) (*api.Request, error) {
 		return nil, errors.Wrap(err, "Unable to get request filters from Postgres")
 	}
 
+	split, err := s.fetchRequestSplit(requestID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &api.Request{
 		RequestID:       requestID,
 		Dataset:         dataset,
@@ -149,6 +189,7 @@ func (s *Storage) loadRequest(rows *pgx.Rows) (*api.Request, error) {
 		LastUpdatedTime: lastUpdatedTime,
 		Features:        features,
 		Filters:         filters,
+		Split:           split,
 	}, nil
 }
 
@@ -360,3 +401,57 @@ func (s *Storage) FetchRequestByDatasetTarget(dataset string, target string, sol
 
 	return requests, nil
 }
+
+// FetchRequestIDsByDataset pulls the ids of all requests made against a dataset.
+func (s *Storage) FetchRequestIDsByDataset(dataset string) ([]string, error) {
+	sql := fmt.Sprintf("SELECT request_id FROM %s WHERE dataset = $1 ORDER BY created_time;", requestTableName)
+
+	rows, err := s.client.Query(sql, dataset)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull request ids from Postgres")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	requestIDs := make([]string, 0)
+	for rows.Next() {
+		var requestID string
+		err = rows.Scan(&requestID)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse request id from Postgres")
+		}
+		requestIDs = append(requestIDs, requestID)
+	}
+
+	return requestIDs, nil
+}
+
+// DeleteRequest removes a request along with its features, filters and all
+// solution data from Postgres.
+func (s *Storage) DeleteRequest(requestID string) error {
+	solutionSQL := fmt.Sprintf("SELECT solution_id FROM %s WHERE request_id = $1", solutionTableName)
+	statements := []string{
+		fmt.Sprintf("DELETE FROM %s WHERE solution_id IN (%s);", solutionScoreTableName, solutionSQL),
+		fmt.Sprintf("DELETE FROM %s WHERE result_id IN (SELECT result_uri FROM %s WHERE solution_id IN (%s));", resultTargetTableName, solutionResultTableName, solutionSQL),
+		fmt.Sprintf("DELETE FROM %s WHERE solution_id IN (%s);", solutionResultTableName, solutionSQL),
+		fmt.Sprintf("DELETE FROM %s WHERE solution_id IN (%s);", predictionTableName, solutionSQL),
+		fmt.Sprintf("DELETE FROM %s WHERE solution_id IN (%s);", solutionFlagTableName, solutionSQL),
+		fmt.Sprintf("DELETE FROM %s WHERE request_id = $1;", solutionTableName),
+		fmt.Sprintf("DELETE FROM %s WHERE request_id = $1;", featureTableName),
+		fmt.Sprintf("DELETE FROM %s WHERE request_id = $1;", filterTableName),
+		fmt.Sprintf("DELETE FROM %s WHERE request_id = $1;", requestStateTableName),
+		fmt.Sprintf("DELETE FROM %s WHERE request_id = $1;", baselineScoreTableName),
+		fmt.Sprintf("DELETE FROM %s WHERE request_id = $1;", requestSplitTableName),
+		fmt.Sprintf("DELETE FROM %s WHERE request_id = $1;", requestTableName),
+	}
+
+	for _, sql := range statements {
+		_, err := s.client.Exec(sql, requestID)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to delete request %s from Postgres", requestID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// PersistRequestState persists the resume state and error reason of a
+// request to Postgres, replacing any previous state.
+func (s *Storage) PersistRequestState(requestID string, resumeState string, errorReason string) error {
+	sql := fmt.Sprintf("INSERT INTO %s (request_id, resume_state, error_reason) VALUES ($1, $2, $3) "+
+		"ON CONFLICT (request_id) DO UPDATE SET resume_state = EXCLUDED.resume_state, error_reason = EXCLUDED.error_reason;", requestStateTableName)
+
+	_, err := s.client.Exec(sql, requestID, resumeState, errorReason)
+
+	return err
+}
+
+// FetchRequestState pulls the state of a request from Postgres. Nil is
+// returned if no state was stored.
+func (s *Storage) FetchRequestState(requestID string) (*api.RequestState, error) {
+	sql := fmt.Sprintf("SELECT request_id, resume_state, error_reason FROM %s WHERE request_id = $1;", requestStateTableName)
+
+	rows, err := s.client.Query(sql, requestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull request state from Postgres")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	state := &api.RequestState{}
+	err = rows.Scan(&state.RequestID, &state.ResumeState, &state.ErrorReason)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse request state from Postgres")
+	}
+
+	return state, nil
+}
+
+// FetchResumableRequests pulls the requests whose latest progress is one of
+// the provided values, or whose dispatch never finished, from Postgres.
+func (s *Storage) FetchResumableRequests(progress []string) ([]*api.Request, error) {
+	sql := fmt.Sprintf("SELECT latest.request_id FROM (SELECT DISTINCT ON (request_id) request_id, progress, created_time FROM %s "+
+		"ORDER BY request_id, created_time desc) AS latest LEFT JOIN %s AS state ON state.request_id = latest.request_id "+
+		"WHERE latest.progress = ANY($1) OR COALESCE(state.resume_state, '') <> '' ORDER BY latest.created_time;",
+		requestTableName, requestStateTableName)
+
+	requestIDs, err := s.queryIDs(sql, progress)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull requests from Postgres")
+	}
+
+	requests := make([]*api.Request, 0)
+	for _, requestID := range requestIDs {
+		request, err := s.FetchRequest(requestID)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
+
+// FetchSolutionIDsByProgress pulls the ids of the solutions of a request whose
+// latest progress is one of the provided values from Postgres.
+func (s *Storage) FetchSolutionIDsByProgress(requestID string, progress []string) ([]string, error) {
+	sql := fmt.Sprintf("SELECT solution_id FROM (SELECT DISTINCT ON (solution_id) solution_id, progress, created_time FROM %s "+
+		"WHERE request_id = $1 ORDER BY solution_id, created_time desc) AS latest WHERE progress = ANY($2) ORDER BY created_time;", solutionTableName)
+
+	solutionIDs, err := s.queryIDs(sql, requestID, progress)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull solutions from Postgres")
+	}
+
+	return solutionIDs, nil
+}
+
+func (s *Storage) queryIDs(sql string, params ...interface{}) ([]string, error) {
+	rows, err := s.client.Query(sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
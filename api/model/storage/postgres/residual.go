@@ -24,9 +24,9 @@ import (
 )
 
 // FetchResidualsExtremaByURI fetches the residual extrema by resultURI.
-func (s *Storage) FetchResidualsExtremaByURI(dataset string, storageName string, resultURI string) (*api.Extrema, error) {
+func (s *Storage) FetchResidualsExtremaByURI(dataset string, storageName string, resultURI string, target string) (*api.Extrema, error) {
 	storageNameResult := s.getResultTable(storageName)
-	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI, target)
 	if err != nil {
 		return nil, err
 	}
@@ -42,9 +42,9 @@ func (s *Storage) FetchResidualsExtremaByURI(dataset string, storageName string,
 }
 
 // FetchResidualsSummary fetches a histogram of the residuals associated with a set of numerical predictions.
-func (s *Storage) FetchResidualsSummary(dataset string, storageName string, resultURI string, filterParams *api.FilterParams, extrema *api.Extrema) (*api.Histogram, error) {
+func (s *Storage) FetchResidualsSummary(dataset string, storageName string, resultURI string, target string, filterParams *api.FilterParams, extrema *api.Extrema) (*api.Histogram, error) {
 	storageNameResult := s.getResultTable(storageName)
-	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI, target)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +67,7 @@ func (s *Storage) FetchResidualsSummary(dataset string, storageName string, resu
 }
 
 func getErrorTyped(variableName string) string {
-	return fmt.Sprintf("(cast(value as double precision) - cast(\"%s\" as double precision))", variableName)
+	return fmt.Sprintf("(cast(value as double precision) - cast(%s as double precision))", quoteIdentifier(variableName))
 }
 
 func (s *Storage) getResidualsHistogramAggQuery(extrema *api.Extrema, variable *model.Variable, resultVariable *model.Variable) (string, string, string) {
@@ -78,7 +78,7 @@ func (s *Storage) getResidualsHistogramAggQuery(extrema *api.Extrema, variable *
 	errorTyped := getErrorTyped(variable.Name)
 
 	// get histogram agg name & query string.
-	histogramAggName := fmt.Sprintf("\"%s%s\"", api.HistogramAggPrefix, extrema.Key)
+	histogramAggName := quoteIdentifier(api.HistogramAggPrefix + extrema.Key)
 	rounded := extrema.GetBucketMinMax()
 	bucketQueryString := fmt.Sprintf("width_bucket(%s, %g, %g, %d) - 1",
 		errorTyped, rounded.Min, rounded.Max, extrema.GetBucketCount())
@@ -89,7 +89,7 @@ func (s *Storage) getResidualsHistogramAggQuery(extrema *api.Extrema, variable *
 
 func getResultJoin(storageName string) string {
 	// FROM clause to join result and base data on d3mIdex value
-	return fmt.Sprintf("%s_result as res inner join %s as data on data.\"%s\" = res.index", storageName, storageName, model.D3MIndexFieldName)
+	return fmt.Sprintf("%s as res inner join %s as data on data.%s = res.index", quoteIdentifier(storageName+"_result"), quoteIdentifier(storageName), quoteIdentifier(model.D3MIndexFieldName))
 }
 
 func getResidualsMinMaxAggsQuery(variable *model.Variable, resultVariable *model.Variable) string {
@@ -101,7 +101,7 @@ func getResidualsMinMaxAggsQuery(variable *model.Variable, resultVariable *model
 	errorTyped := getErrorTyped(variable.Name)
 
 	// create aggregations
-	queryPart := fmt.Sprintf("MIN(%s) AS \"%s\", MAX(%s) AS \"%s\"", errorTyped, minAggName, errorTyped, maxAggName)
+	queryPart := fmt.Sprintf("MIN(%s) AS %s, MAX(%s) AS %s", errorTyped, quoteIdentifier(minAggName), errorTyped, quoteIdentifier(maxAggName))
 
 	return queryPart
 }
@@ -34,28 +34,56 @@ func (s *Storage) getResultTable(dataset string) string {
 	return fmt.Sprintf("%s_result", dataset)
 }
 
-func (s *Storage) getResultTargetName(storageName string, resultURI string) (string, error) {
-	// Assume only a single target / result. Read the target name from the
-	// database table.
-	sql := fmt.Sprintf("SELECT target FROM %s WHERE result_id = $1 LIMIT 1;", storageName)
+// getResultTargetNames returns the targets of a result in the order of the
+// request, the first one being its primary target. Results persisted without
+// a target order list their targets by name.
+func (s *Storage) getResultTargetNames(storageName string, resultURI string) ([]string, error) {
+	// Read the target names from the database table, a result storing a row
+	// per target.
+	sql := fmt.Sprintf(`SELECT res.target FROM (SELECT DISTINCT target FROM %s WHERE result_id = $1) AS res
+		LEFT JOIN %s AS ord ON ord.result_id = $1 AND ord.target = res.target
+		ORDER BY ord.target_index NULLS LAST, res.target;`, quoteIdentifier(storageName), resultTargetTableName)
 
 	rows, err := s.client.Query(sql, resultURI)
 	if err != nil {
-		return "", errors.Wrap(err, fmt.Sprintf("Unable to get target variable name from results for result URI `%s`", resultURI))
+		return nil, errors.Wrap(err, fmt.Sprintf("Unable to get target variable names from results for result URI `%s`", resultURI))
 	}
 	defer rows.Close()
 
-	if rows.Next() {
+	targetNames := make([]string, 0)
+	for rows.Next() {
 		var targetName string
 		err = rows.Scan(&targetName)
 		if err != nil {
-			return "", errors.Wrap(err, fmt.Sprintf("Unable to get target variable name for result URI `%s`", resultURI))
+			return nil, errors.Wrap(err, fmt.Sprintf("Unable to get target variable name for result URI `%s`", resultURI))
 		}
+		targetNames = append(targetNames, targetName)
+	}
+
+	if len(targetNames) == 0 {
+		return nil, errors.Errorf("Target feature for result URI `%s` not found", resultURI)
+	}
+
+	return targetNames, nil
+}
 
-		return targetName, nil
+func (s *Storage) getResultTargetName(storageName string, resultURI string, target string) (string, error) {
+	targetNames, err := s.getResultTargetNames(storageName, resultURI)
+	if err != nil {
+		return "", err
+	}
+
+	// default to the primary target when none is specified
+	if target == "" {
+		return targetNames[0], nil
+	}
+	for _, targetName := range targetNames {
+		if targetName == target {
+			return targetName, nil
+		}
 	}
 
-	return "", errors.Errorf("Target feature for result URI `%s` not found", resultURI)
+	return "", errors.Errorf("Target feature `%s` for result URI `%s` not found", target, resultURI)
 }
 
 func (s *Storage) getResultTargetVariable(dataset string, targetName string) (*model.Variable, error) {
@@ -67,8 +95,9 @@ func (s *Storage) getResultTargetVariable(dataset string, targetName string) (*m
 	return variable, nil
 }
 
-// PersistResult stores the solution result to Postgres.
-func (s *Storage) PersistResult(dataset string, storageName string, resultURI string, target string) error {
+// PersistResult stores the solution result to Postgres, with a result row
+// per target.
+func (s *Storage) PersistResult(dataset string, storageName string, resultURI string, targets []string) error {
 	// Read the results file.
 	file, err := os.Open(resultURI)
 	if err != nil {
@@ -84,30 +113,58 @@ func (s *Storage) PersistResult(dataset string, storageName string, resultURI st
 		return errors.Wrap(err, "solution csv empty")
 	}
 
-	// currently only support a single result column.
-	if len(records[0]) > 2 {
-		log.Warnf("Result contains %d columns, expected 2.  Additional columns will be ignored.", len(records[0]))
-	}
-
 	// Translate from display name to storage name.
-	targetName := target
-	targetDisplayName, err := s.getDisplayName(dataset, targetName)
-	if err != nil {
-		return errors.Wrap(err, "unable to map target name")
+	targetDisplayNames := make(map[string]string)
+	for _, targetName := range targets {
+		targetDisplayName, err := s.getDisplayName(dataset, targetName)
+		if err != nil {
+			return errors.Wrap(err, "unable to map target name")
+		}
+		if targetDisplayName == "" {
+			// datasets used for predictions may not have the target
+			targetDisplayName = targetName
+		}
+		targetDisplayNames[targetDisplayName] = targetName
 	}
 
-	// Header row will have the target. Find the index.
-	targetIndex := -1
+	// Header row will have the targets. Find the indices.
+	targetIndices := make(map[string]int)
 	d3mIndexIndex := -1
 	for i, v := range records[0] {
-		if v == targetDisplayName {
-			targetIndex = i
+		if targetName, ok := targetDisplayNames[v]; ok {
+			targetIndices[targetName] = i
 		} else if v == model.D3MIndexFieldName {
 			d3mIndexIndex = i
 		}
 	}
+	for _, targetName := range targets {
+		if _, ok := targetIndices[targetName]; !ok {
+			return errors.Errorf("solution result missing target `%s`", targetName)
+		}
+	}
+
+	// the target order identifies the primary target of the result
+	for i, targetName := range targets {
+		err = s.executeInsertResultTargetStatement(resultURI, targetName, i)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert result target in database")
+		}
+	}
+
+	// additional numeric columns such as probabilities are stored as outputs
+	outputColumns, ignored := getResultOutputColumns(records, d3mIndexIndex, targetIndices)
+	if len(ignored) > 0 {
+		log.Warnf("Result contains non numeric columns %s.  They will be ignored.", strings.Join(ignored, ", "))
+	}
+	if len(outputColumns) > 0 {
+		err = s.createResultOutputTable(storageName)
+		if err != nil {
+			return err
+		}
+	}
 
 	// store all results to the storage
+	outputRows := make([][]interface{}, 0)
 	for i := 1; i < len(records); i++ {
 		// Each data row is index, target.
 		err = nil
@@ -125,18 +182,47 @@ func (s *Storage) PersistResult(dataset string, storageName string, resultURI st
 			parsedVal = int64(parsedValFloat)
 		}
 
-		// store the result to the storage
-		err = s.executeInsertResultStatement(storageName, resultURI, parsedVal, targetName, records[i][targetIndex])
+		// store the result of each target to the storage
+		for _, targetName := range targets {
+			err = s.executeInsertResultStatement(storageName, resultURI, parsedVal, targetName, records[i][targetIndices[targetName]])
+			if err != nil {
+				return errors.Wrap(err, "failed to insert result in database")
+			}
+		}
+
+		for _, column := range outputColumns {
+			if column >= len(records[i]) || records[i][column] == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(records[i][column], 64)
+			if err != nil {
+				return errors.Wrap(err, "failed csv output parsing")
+			}
+			outputRows = append(outputRows, []interface{}{resultURI, parsedVal, records[0][column], value})
+		}
+	}
+
+	if len(outputColumns) > 0 {
+		err = s.insertResultOutputs(storageName, resultURI, outputRows)
 		if err != nil {
-			return errors.Wrap(err, "failed to insert result in database")
+			return errors.Wrap(err, "failed to insert result outputs in database")
 		}
 	}
 
 	return nil
 }
 
+func (s *Storage) executeInsertResultTargetStatement(resultID string, target string, index int) error {
+	statement := fmt.Sprintf("INSERT INTO %s (result_id, target, target_index) VALUES ($1, $2, $3) "+
+		"ON CONFLICT (result_id, target) DO UPDATE SET target_index = EXCLUDED.target_index;", resultTargetTableName)
+
+	_, err := s.client.Exec(statement, resultID, target, index)
+
+	return err
+}
+
 func (s *Storage) executeInsertResultStatement(storageName string, resultID string, index int64, target string, value string) error {
-	statement := fmt.Sprintf("INSERT INTO %s (result_id, index, target, value) VALUES ($1, $2, $3, $4);", s.getResultTable(storageName))
+	statement := fmt.Sprintf("INSERT INTO %s (result_id, index, target, value) VALUES ($1, $2, $3, $4);", quoteIdentifier(s.getResultTable(storageName)))
 
 	_, err := s.client.Exec(statement, resultID, index, target, value)
 
@@ -159,10 +245,13 @@ func (s *Storage) parseFilteredResults(variables []*model.Variable, numRows int,
 			typ := "unknown"
 			if api.IsPredictedKey(key) {
 				label = "Predicted " + api.StripKeySuffix(key)
-				typ = target.Type
+				typ = getResultKeyType(key, variables, target)
 			} else if api.IsErrorKey(key) {
 				label = "Error"
-				typ = target.Type
+				typ = getResultKeyType(key, variables, target)
+			} else if api.IsOutputKey(key) {
+				label = api.StripKeySuffix(key)
+				typ = model.FloatType
 			} else {
 				v := getVariableByKey(key, variables)
 				if v != nil {
@@ -196,6 +285,16 @@ func (s *Storage) parseFilteredResults(variables []*model.Variable, numRows int,
 	return result, nil
 }
 
+// getResultKeyType returns the type of the target of a predicted or error
+// key, falling back on the type of the result target.
+func getResultKeyType(key string, variables []*model.Variable, target *model.Variable) string {
+	v := getVariableByKey(api.StripKeySuffix(key), variables)
+	if v != nil {
+		return v.Type
+	}
+	return target.Type
+}
+
 func appendAndClause(expression string, andClause string) string {
 	if expression == "" {
 		return andClause
@@ -222,7 +321,7 @@ func addIncludeCorrectnessFilterToWhere(wheres []string, params []interface{}, c
 	} else if strings.EqualFold(correctnessFilter.Categories[0], IncorrectCategory) {
 		op = "!="
 	}
-	where = fmt.Sprintf("predicted.value %s data.\"%s\"", op, target.Name)
+	where = fmt.Sprintf("predicted.value %s data.%s", op, quoteIdentifier(target.Name))
 	wheres = append(wheres, where)
 	return wheres, params, nil
 }
@@ -239,7 +338,7 @@ func addExcludeCorrectnessFilterToWhere(wheres []string, params []interface{}, c
 	} else if strings.EqualFold(correctnessFilter.Categories[0], IncorrectCategory) {
 		op = "="
 	}
-	where = fmt.Sprintf("predicted.value %s data.\"%s\"", op, target.Name)
+	where = fmt.Sprintf("predicted.value %s data.%s", op, quoteIdentifier(target.Name))
 	wheres = append(wheres, where)
 	return wheres, params, nil
 }
@@ -387,11 +486,21 @@ func addExcludeErrorFilterToWhere(wheres []string, params []interface{}, targetN
 
 // FetchResults pulls the results from the Postgres database.
 func (s *Storage) FetchResults(dataset string, storageName string, resultURI string, solutionID string, filterParams *api.FilterParams) (*api.FilteredData, error) {
+	// break filters out groups for specific handling
+	filters := s.splitFilters(filterParams)
+
+	// the rows of the filtered target are returned, along with the predictions
+	// of the other targets of the result
 	storageNameResult := s.getResultTable(storageName)
-	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI, filters.resultTarget())
 	if err != nil {
 		return nil, err
 	}
+	targetNames, err := s.getResultTargetNames(storageNameResult, resultURI)
+	if err != nil {
+		return nil, err
+	}
+	filters.dropOtherTargets(targetName)
 
 	// fetch the variable info to resolve its type - skip the first column since that will be the d3m_index value
 	variable, err := s.getResultTargetVariable(dataset, targetName)
@@ -411,9 +520,6 @@ func (s *Storage) FetchResults(dataset string, storageName string, resultURI str
 		return nil, errors.Wrap(err, "Could not build field list")
 	}
 
-	// break filters out groups for specific handling
-	filters := s.splitFilters(filterParams)
-
 	// Create the filter portion of the where clause.
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
@@ -449,6 +555,14 @@ func (s *Storage) FetchResults(dataset string, storageName string, resultURI str
 		}
 	}
 
+	// Add the output filters into the where clause
+	for _, outputFilter := range filters.outputFilters {
+		wheres, params, err = s.addOutputFilterToWhere(wheres, params, storageName, "predicted", outputFilter)
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not add output to where clause")
+		}
+	}
+
 	// Add the error filter into the where clause if it was included in the filter set
 	if filters.residualFilter != nil {
 		if filters.residualFilter.Mode == model.IncludeFilter {
@@ -471,18 +585,48 @@ func (s *Storage) FetchResults(dataset string, storageName string, resultURI str
 
 	errorExpr := ""
 	if model.IsNumerical(variable.Type) {
-		errorExpr = fmt.Sprintf("%s as \"%s\",", getErrorTyped(variable.Name), errorCol)
+		errorExpr = fmt.Sprintf("%s as %s,", getErrorTyped(variable.Name), quoteIdentifier(errorCol))
+	}
+
+	// include the additional outputs such as probabilities or confidences
+	outputs, err := s.fetchResultOutputNames(storageName, resultURI)
+	if err != nil {
+		return nil, err
+	}
+	outputExpr := ""
+	for _, output := range outputs {
+		params = append(params, output)
+		outputExpr = fmt.Sprintf("%s%s as %s, ", outputExpr, s.getResultOutputExpr(storageName, "predicted", len(params)), quoteIdentifier(api.GetOutputKey(output, solutionID)))
+	}
+
+	// include the predictions and residuals of the other targets
+	for _, otherName := range targetNames {
+		if otherName == targetName {
+			continue
+		}
+		otherVariable, err := s.getResultTargetVariable(dataset, otherName)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, otherName)
+		otherPredicted := s.getResultTargetExpr(storageName, "predicted", len(params))
+		outputExpr = fmt.Sprintf("%s%s as %s, ", outputExpr, otherPredicted, quoteIdentifier(api.GetPredictedKey(otherName, solutionID)))
+		if model.IsNumerical(otherVariable.Type) {
+			outputExpr = fmt.Sprintf("%s(cast(%s as double precision) - cast(data.%s as double precision)) as %s, ",
+				outputExpr, otherPredicted, quoteIdentifier(otherName), quoteIdentifier(api.GetErrorKey(otherName, solutionID)))
+		}
 	}
 
 	query := fmt.Sprintf(
-		"SELECT value as \"%s\", "+
-			"\"%s\" as \"%s\", "+
+		"SELECT value as %s, "+
+			"%s as %s, "+
 			"%s "+
+			"%s"+
 			"%s "+
-			"FROM %s as predicted inner join %s as data on data.\"%s\" = predicted.index "+
+			"FROM %s as predicted inner join %s as data on data.%s = predicted.index "+
 			"WHERE result_id = $%d AND target = $%d",
-		predictedCol, targetName, targetCol, errorExpr, fields, storageNameResult, storageName,
-		model.D3MIndexFieldName, len(params)+1, len(params)+2)
+		quoteIdentifier(predictedCol), quoteIdentifier(targetName), quoteIdentifier(targetCol), errorExpr, outputExpr, fields, quoteIdentifier(storageNameResult), quoteIdentifier(storageName),
+		quoteIdentifier(model.D3MIndexFieldName), len(params)+1, len(params)+2)
 
 	params = append(params, resultURI)
 	params = append(params, targetName)
@@ -502,6 +646,7 @@ func (s *Storage) FetchResults(dataset string, storageName string, resultURI str
 
 	countFilter := map[string]interface{}{
 		"result_id": resultURI,
+		"target":    targetName,
 	}
 	numRows, err := s.FetchNumRows(storageNameResult, countFilter)
 	if err != nil {
@@ -511,16 +656,24 @@ func (s *Storage) FetchResults(dataset string, storageName string, resultURI str
 	return s.parseFilteredResults(variables, numRows, rows, variable)
 }
 
+// getResultTargetExpr returns an expression selecting the predicted value of
+// another target of the result rows aliased as resultAlias, the target name
+// being the numbered query parameter.
+func (s *Storage) getResultTargetExpr(storageName string, resultAlias string, targetParam int) string {
+	return fmt.Sprintf("(SELECT t.value FROM %s AS t WHERE t.result_id = %s.result_id AND t.index = %s.index AND t.target = $%d)",
+		quoteIdentifier(s.getResultTable(storageName)), resultAlias, resultAlias, targetParam)
+}
+
 func (s *Storage) getResultMinMaxAggsQuery(variable *model.Variable, resultVariable *model.Variable) string {
 	// get min / max agg names
 	minAggName := api.MinAggPrefix + resultVariable.Name
 	maxAggName := api.MaxAggPrefix + resultVariable.Name
 
 	// Only numeric types should occur.
-	fieldTyped := fmt.Sprintf("cast(\"%s\" as double precision)", resultVariable.Name)
+	fieldTyped := fmt.Sprintf("cast(%s as double precision)", quoteIdentifier(resultVariable.Name))
 
 	// create aggregations
-	queryPart := fmt.Sprintf("MIN(%s) AS \"%s\", MAX(%s) AS \"%s\"", fieldTyped, minAggName, fieldTyped, maxAggName)
+	queryPart := fmt.Sprintf("MIN(%s) AS %s, MAX(%s) AS %s", fieldTyped, quoteIdentifier(minAggName), fieldTyped, quoteIdentifier(maxAggName))
 	// add aggregations
 	return queryPart
 }
@@ -530,7 +683,7 @@ func (s *Storage) fetchResultsExtrema(resultURI string, dataset string, variable
 	aggQuery := s.getResultMinMaxAggsQuery(variable, resultVariable)
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s WHERE result_id = $1 AND target = $2;", aggQuery, dataset)
+	queryString := fmt.Sprintf("SELECT %s FROM %s WHERE result_id = $1 AND target = $2;", aggQuery, quoteIdentifier(dataset))
 
 	// execute the postgres query
 	res, err := s.client.Query(queryString, resultURI, variable.Name)
@@ -543,9 +696,9 @@ func (s *Storage) fetchResultsExtrema(resultURI string, dataset string, variable
 }
 
 // FetchResultsExtremaByURI fetches the results extrema by resultURI.
-func (s *Storage) FetchResultsExtremaByURI(dataset string, storageName string, resultURI string) (*api.Extrema, error) {
+func (s *Storage) FetchResultsExtremaByURI(dataset string, storageName string, resultURI string, target string) (*api.Extrema, error) {
 	storageNameResult := s.getResultTable(storageName)
-	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI, target)
 	if err != nil {
 		return nil, err
 	}
@@ -563,10 +716,11 @@ func (s *Storage) FetchResultsExtremaByURI(dataset string, storageName string, r
 }
 
 // FetchPredictedSummary gets the summary data about a target variable from the
-// results table.
-func (s *Storage) FetchPredictedSummary(dataset string, storageName string, resultURI string, filterParams *api.FilterParams, extrema *api.Extrema) (*api.Histogram, error) {
+// results table. The first target of the result is summarized when no target
+// is specified.
+func (s *Storage) FetchPredictedSummary(dataset string, storageName string, resultURI string, target string, filterParams *api.FilterParams, extrema *api.Extrema) (*api.Histogram, error) {
 	storageNameResult := s.getResultTable(storageName)
-	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI, target)
 	if err != nil {
 		return nil, err
 	}
@@ -597,6 +751,7 @@ func (s *Storage) FetchPredictedSummary(dataset string, storageName string, resu
 	// add filter if results
 	filter := map[string]interface{}{
 		"result_id": resultURI,
+		"target":    targetName,
 	}
 
 	// get number of rows
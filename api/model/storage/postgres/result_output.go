@@ -0,0 +1,248 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// Additional numeric result columns, such as class probabilities or
+// confidences, are stored one value per row in a table next to the result
+// table since their number and names vary between pipelines.
+
+func (s *Storage) getResultOutputTable(storageName string) string {
+	return fmt.Sprintf("%s_result_output", storageName)
+}
+
+func (s *Storage) createResultOutputTable(storageName string) error {
+	sql := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		result_id TEXT,
+		index     BIGINT,
+		output    TEXT,
+		value     DOUBLE PRECISION,
+		PRIMARY KEY (result_id, index, output)
+	);`, quoteIdentifier(s.getResultOutputTable(storageName)))
+
+	_, err := s.client.Exec(sql)
+	if err != nil {
+		return errors.Wrap(err, "unable to create result output table")
+	}
+
+	return nil
+}
+
+// insertResultOutputs replaces the outputs of a result with the supplied rows
+// of result id, index, output and value, copying them in a single batch.
+func (s *Storage) insertResultOutputs(storageName string, resultID string, rows [][]interface{}) error {
+	sql := fmt.Sprintf("DELETE FROM %s WHERE result_id = $1;", quoteIdentifier(s.getResultOutputTable(storageName)))
+	_, err := s.client.Exec(sql, resultID)
+	if err != nil {
+		return errors.Wrap(err, "unable to delete existing result outputs")
+	}
+
+	_, err = s.client.CopyFrom(pgx.Identifier{s.getResultOutputTable(storageName)}, []string{"result_id", "index", "output", "value"}, pgx.CopyFromRows(rows))
+	if err != nil {
+		return errors.Wrap(err, "unable to copy result outputs")
+	}
+
+	return nil
+}
+
+// getResultOutputColumns returns the indices of the columns of a result csv
+// that hold numeric values, excluding the index and target columns, along
+// with the names of the non numeric columns that are ignored.
+func getResultOutputColumns(records [][]string, d3mIndexIndex int, targetIndices map[string]int) ([]int, []string) {
+	excluded := map[int]bool{d3mIndexIndex: true}
+	for _, targetIndex := range targetIndices {
+		excluded[targetIndex] = true
+	}
+
+	columns := make([]int, 0)
+	ignored := make([]string, 0)
+	for i, name := range records[0] {
+		if excluded[i] {
+			continue
+		}
+
+		numeric := true
+		for _, row := range records[1:] {
+			if i >= len(row) || row[i] == "" {
+				continue
+			}
+			_, err := strconv.ParseFloat(row[i], 64)
+			if err != nil {
+				numeric = false
+				break
+			}
+		}
+
+		if numeric {
+			columns = append(columns, i)
+		} else {
+			ignored = append(ignored, name)
+		}
+	}
+
+	return columns, ignored
+}
+
+func (s *Storage) fetchResultOutputNames(storageName string, resultURI string) ([]string, error) {
+	var exists bool
+	err := s.client.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_class WHERE relname = $1);", s.getResultOutputTable(storageName)).Scan(&exists)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to check for result outputs")
+	}
+	if !exists {
+		return []string{}, nil
+	}
+
+	sql := fmt.Sprintf("SELECT DISTINCT output FROM %s WHERE result_id = $1 ORDER BY output;", quoteIdentifier(s.getResultOutputTable(storageName)))
+
+	rows, err := s.client.Query(sql, resultURI)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch result outputs")
+	}
+	defer rows.Close()
+
+	outputs := make([]string, 0)
+	for rows.Next() {
+		var output string
+		err = rows.Scan(&output)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse result output")
+		}
+		outputs = append(outputs, output)
+	}
+
+	return outputs, nil
+}
+
+// getResultOutputExpr returns an expression selecting an output value of the
+// result rows aliased as resultAlias, the output name being the numbered
+// query parameter.
+func (s *Storage) getResultOutputExpr(storageName string, resultAlias string, outputParam int) string {
+	return fmt.Sprintf("(SELECT o.value FROM %s AS o WHERE o.result_id = %s.result_id AND o.index = %s.index AND o.output = $%d)",
+		quoteIdentifier(s.getResultOutputTable(storageName)), resultAlias, resultAlias, outputParam)
+}
+
+func (s *Storage) addOutputFilterToWhere(wheres []string, params []interface{}, storageName string, resultAlias string, outputFilter *model.Filter) ([]string, []interface{}, error) {
+	if outputFilter.Type != model.NumericalFilter {
+		return nil, nil, errors.Errorf("unexpected type %s for output %s", outputFilter.Type, outputFilter.Key)
+	}
+
+	params = append(params, api.StripKeySuffix(outputFilter.Key))
+	output := s.getResultOutputExpr(storageName, resultAlias, len(params))
+
+	where := ""
+	if outputFilter.Mode == model.IncludeFilter {
+		where = fmt.Sprintf("(%s >= $%d AND %s <= $%d)", output, len(params)+1, output, len(params)+2)
+	} else {
+		where = fmt.Sprintf("(%s < $%d OR %s > $%d)", output, len(params)+1, output, len(params)+2)
+	}
+	params = append(params, *outputFilter.Min)
+	params = append(params, *outputFilter.Max)
+
+	wheres = append(wheres, where)
+	return wheres, params, nil
+}
+
+// FetchResultOutputSummary fetches a histogram of an additional numeric
+// output of a result, such as the confidence of the predictions.
+func (s *Storage) FetchResultOutputSummary(dataset string, storageName string, resultURI string, output string, filterParams *api.FilterParams, extrema *api.Extrema) (*api.Histogram, error) {
+	outputs, err := s.fetchResultOutputNames(storageName, resultURI)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, name := range outputs {
+		found = found || name == output
+	}
+	if !found {
+		return nil, errors.Errorf("result does not contain output `%s`", output)
+	}
+
+	variable := &model.Variable{
+		Name:        output,
+		DisplayName: output,
+		Type:        model.FloatType,
+	}
+
+	// get filter where / params
+	wheres, params, err := s.buildResultQueryFilters(storageName, resultURI, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d", len(params)+1))
+	params = append(params, resultURI, output)
+
+	// select the output value of every filtered row
+	outputQuery := fmt.Sprintf("SELECT %s AS value FROM %s AS data INNER JOIN %s AS result ON data.%s = result.index WHERE %s",
+		s.getResultOutputExpr(storageName, "result", len(params)), quoteIdentifier(storageName), quoteIdentifier(s.getResultTable(storageName)),
+		quoteIdentifier(model.D3MIndexFieldName), strings.Join(wheres, " AND "))
+
+	// need the extrema to calculate the histogram interval
+	if extrema == nil {
+		query := fmt.Sprintf("SELECT MIN(value), MAX(value) FROM (%s) AS outputs;", outputQuery)
+		res, err := s.client.Query(query, params...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch extrema for result output from postgres")
+		}
+		extrema, err = s.parseExtrema(res, variable)
+		res.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		extrema.Key = variable.Name
+		extrema.Type = variable.Type
+	}
+
+	// compute the buckets from the min/max and desired bucket count.
+	interval := extrema.GetBucketInterval()
+	rounded := extrema.GetBucketMinMax()
+	histogramName := quoteIdentifier(api.HistogramAggPrefix + extrema.Key)
+	bucketQuery := fmt.Sprintf("width_bucket(value, %g, %g, %d) - 1", rounded.Min, rounded.Max, extrema.GetBucketCount())
+	histogramQuery := fmt.Sprintf("(%s) * %g + %g", bucketQuery, interval, rounded.Min)
+
+	query := fmt.Sprintf(`
+		SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(*) AS count FROM (%s) AS outputs
+		WHERE value IS NOT NULL
+		GROUP BY %s ORDER BY %s;`, bucketQuery, histogramQuery, histogramName, outputQuery, bucketQuery, histogramName)
+
+	res, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for result output summaries from postgres")
+	}
+	defer res.Close()
+
+	field := NewNumericalField(s, storageName, variable)
+
+	histogram, err := field.parseHistogram(res, extrema)
+	if err != nil {
+		return nil, err
+	}
+	histogram.Dataset = dataset
+
+	return histogram, nil
+}
@@ -0,0 +1,58 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResultOutputColumns(t *testing.T) {
+	records := [][]string{
+		{"d3mIndex", "species", "confidence", "prob_setosa", "notes"},
+		{"0", "setosa", "0.9", "0.9", "sure"},
+		{"1", "virginica", "0.55", "", "unsure"},
+		{"2", "setosa", "1", "0.99", ""},
+	}
+
+	columns, ignored := getResultOutputColumns(records, 0, map[string]int{"species": 1})
+	assert.Equal(t, []int{2, 3}, columns)
+	assert.Equal(t, []string{"notes"}, ignored)
+}
+
+func TestGetResultOutputColumnsNone(t *testing.T) {
+	records := [][]string{
+		{"d3mIndex", "species"},
+		{"0", "setosa"},
+	}
+
+	columns, ignored := getResultOutputColumns(records, 0, map[string]int{"species": 1})
+	assert.Empty(t, columns)
+	assert.Empty(t, ignored)
+}
+
+func TestGetResultOutputColumnsMultipleTargets(t *testing.T) {
+	records := [][]string{
+		{"d3mIndex", "species", "confidence", "height"},
+		{"0", "setosa", "0.9", "1.2"},
+		{"1", "virginica", "0.55", "0.8"},
+	}
+
+	columns, ignored := getResultOutputColumns(records, 0, map[string]int{"species": 1, "height": 3})
+	assert.Equal(t, []int{2}, columns)
+	assert.Empty(t, ignored)
+}
@@ -0,0 +1,109 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// FetchResultQualityStats summarizes the predictions of a result against the
+// target values for the solution quality checks.
+func (s *Storage) FetchResultQualityStats(dataset string, storageName string, resultURI string, target string) (*api.ResultQualityStats, error) {
+	storageNameResult := s.getResultTable(storageName)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI, target)
+	if err != nil {
+		return nil, err
+	}
+
+	variable, err := s.getResultTargetVariable(dataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	fromClause := fmt.Sprintf("%s AS result INNER JOIN %s AS data ON data.%s = result.index",
+		quoteIdentifier(storageNameResult), quoteIdentifier(storageName), quoteIdentifier(model.D3MIndexFieldName))
+	predicted := "NULLIF(result.value, '')"
+	target := fmt.Sprintf("data.%s", quoteIdentifier(targetName))
+
+	stats := &api.ResultQualityStats{
+		Numerical: model.IsNumerical(variable.Type),
+	}
+
+	var predictedMin *float64
+	var predictedMax *float64
+	var resultError *float64
+	if stats.Numerical {
+		// the spread of the target over the whole dataset
+		targetStats, err := NewNumericalField(s, storageName, variable).FetchNumericalStats(&api.FilterParams{})
+		if err != nil {
+			return nil, err
+		}
+		stats.TargetMean = targetStats.Mean
+		stats.TargetStdDev = targetStats.StdDev
+
+		predictedTyped := fmt.Sprintf("cast(%s as double precision)", predicted)
+		targetTyped := fmt.Sprintf("cast(%s as double precision)", target)
+		query := fmt.Sprintf("SELECT COUNT(*), COUNT(%s), COUNT(DISTINCT %s), MIN(%s), MAX(%s), AVG(ABS(%s - %s)), AVG(ABS(%s - $3)) "+
+			"FROM %s WHERE result.result_id = $1 AND result.target = $2;",
+			predicted, predicted, predictedTyped, predictedTyped, predictedTyped, targetTyped, targetTyped, fromClause)
+
+		var baselineError *float64
+		err = s.client.QueryRow(query, resultURI, targetName, stats.TargetMean).Scan(&stats.NumRows, &stats.NumPredicted,
+			&stats.NumDistinct, &predictedMin, &predictedMax, &resultError, &baselineError)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch result quality stats from postgres")
+		}
+		if baselineError != nil {
+			stats.BaselineError = *baselineError
+		}
+	} else {
+		query := fmt.Sprintf("SELECT COUNT(*), COUNT(%s), COUNT(DISTINCT %s), AVG(CASE WHEN result.value = %s THEN 0.0 ELSE 1.0 END) "+
+			"FROM %s WHERE result.result_id = $1 AND result.target = $2;",
+			predicted, predicted, target, fromClause)
+
+		err = s.client.QueryRow(query, resultURI, targetName).Scan(&stats.NumRows, &stats.NumPredicted, &stats.NumDistinct, &resultError)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch result quality stats from postgres")
+		}
+
+		// the baseline always predicts the most frequent class
+		if stats.NumRows > 0 {
+			query = fmt.Sprintf("SELECT COUNT(*) AS count FROM %s WHERE result.result_id = $1 AND result.target = $2 "+
+				"GROUP BY %s ORDER BY count desc LIMIT 1;", fromClause, target)
+
+			var majority int
+			err = s.client.QueryRow(query, resultURI, targetName).Scan(&majority)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to fetch result majority class from postgres")
+			}
+			stats.BaselineError = 1 - float64(majority)/float64(stats.NumRows)
+		}
+	}
+
+	if predictedMin != nil && predictedMax != nil {
+		stats.PredictedMin = *predictedMin
+		stats.PredictedMax = *predictedMax
+	}
+	if resultError != nil {
+		stats.Error = *resultError
+	}
+
+	return stats, nil
+}
@@ -27,6 +27,12 @@ const (
 	solutionScoreTableName  = "solution_score"
 	featureTableName        = "request_feature"
 	filterTableName         = "request_filter"
+	predictionTableName     = "prediction"
+	requestStateTableName   = "request_state"
+	solutionFlagTableName   = "solution_flag"
+	baselineScoreTableName  = "baseline_score"
+	resultTargetTableName   = "result_target"
+	requestSplitTableName   = "request_split"
 	wordStemTableName       = "word_stem"
 )
 
@@ -70,10 +70,10 @@ func (f *TextField) fetchHistogram(filterParams *api.FilterParams) (*api.Histogr
 
 	// Get count by category.
 	query := fmt.Sprintf("SELECT w.word as %s, COUNT(*) as count "+
-		"FROM (SELECT unnest(tsvector_to_array(to_tsvector(\"%s\"))) as stem FROM %s %s) as r "+
+		"FROM (SELECT unnest(tsvector_to_array(to_tsvector(%s))) as stem FROM %s %s) as r "+
 		"INNER JOIN %s as w on r.stem = w.stem "+
 		"GROUP BY w.word ORDER BY count desc, w.word LIMIT %d;",
-		f.Variable.Name, f.Variable.Name, f.StorageName, where, wordStemTableName, catResultLimit)
+		quoteIdentifier(f.Variable.Name), quoteIdentifier(f.Variable.Name), quoteIdentifier(f.StorageName), where, wordStemTableName, catResultLimit)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -90,7 +90,7 @@ func (f *TextField) fetchHistogram(filterParams *api.FilterParams) (*api.Histogr
 func (f *TextField) fetchHistogramByResult(resultURI string, filterParams *api.FilterParams) (*api.Histogram, error) {
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, filterParams)
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, "", filterParams)
 	if err != nil {
 		return nil, err
 	}
@@ -103,13 +103,13 @@ func (f *TextField) fetchHistogramByResult(resultURI string, filterParams *api.F
 	}
 
 	// Get count by category.
-	query := fmt.Sprintf("SELECT w.word as \"%s\", COUNT(*) as count "+
-		"FROM (SELECT unnest(tsvector_to_array(to_tsvector(\"%s\"))) as stem "+
-		"FROM %s data INNER JOIN %s result ON data.\"%s\" = result.index WHERE result.result_id = $%d %s) as r "+
+	query := fmt.Sprintf("SELECT w.word as %s, COUNT(*) as count "+
+		"FROM (SELECT unnest(tsvector_to_array(to_tsvector(%s))) as stem "+
+		"FROM %s data INNER JOIN %s result ON data.%s = result.index WHERE result.result_id = $%d %s) as r "+
 		"INNER JOIN %s as w on r.stem = w.stem "+
 		"GROUP BY w.word ORDER BY count desc, w.word LIMIT %d;",
-		f.Variable.Name, f.Variable.Name, f.StorageName, f.Storage.getResultTable(f.StorageName),
-		model.D3MIndexFieldName, len(params), where, wordStemTableName, catResultLimit)
+		quoteIdentifier(f.Variable.Name), quoteIdentifier(f.Variable.Name), quoteIdentifier(f.StorageName), quoteIdentifier(f.Storage.getResultTable(f.StorageName)),
+		quoteIdentifier(model.D3MIndexFieldName), len(params), where, wordStemTableName, catResultLimit)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -172,21 +172,21 @@ func (f *TextField) FetchPredictedSummaryData(resultURI string, datasetResult st
 	targetName := f.Variable.Name
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, filterParams)
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, targetName, filterParams)
 	if err != nil {
 		return nil, err
 	}
 
-	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d AND result.target = $%d ", len(params)+1, len(params)+2))
-	params = append(params, resultURI, targetName)
+	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d ", len(params)+1))
+	params = append(params, resultURI)
 
-	query := fmt.Sprintf("SELECT word_b.word as \"%s\", word_v.word as value, COUNT(*) as count "+
-		"FROM (SELECT unnest(tsvector_to_array(to_tsvector(base.\"%s\"))) as stem_b, "+
+	query := fmt.Sprintf("SELECT word_b.word as %s, word_v.word as value, COUNT(*) as count "+
+		"FROM (SELECT unnest(tsvector_to_array(to_tsvector(base.%s))) as stem_b, "+
 		"unnest(tsvector_to_array(to_tsvector(result.value))) as stem_v "+
 		"FROM %s AS result INNER JOIN %s AS base ON result.index = base.\"d3mIndex\" "+
 		"WHERE %s) r INNER JOIN %s word_b ON r.stem_b = word_b.stem INNER JOIN %s word_v ON r.stem_v = word_v.stem "+
 		"GROUP BY word_v.word, word_b.word "+
-		"ORDER BY count desc;", targetName, targetName, datasetResult, f.StorageName, strings.Join(wheres, " AND "), wordStemTableName, wordStemTableName)
+		"ORDER BY count desc;", quoteIdentifier(targetName), quoteIdentifier(targetName), quoteIdentifier(datasetResult), quoteIdentifier(f.StorageName), strings.Join(wheres, " AND "), wordStemTableName, wordStemTableName)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -70,8 +70,8 @@ func (f *TimeSeriesField) fetchRepresentationTimeSeries(categoryBuckets []*api.B
 		prefixedVarName := f.clusterVarName(f.Variable.Name)
 
 		// pull sample row containing bucket
-		query := fmt.Sprintf("SELECT \"%s\" FROM %s WHERE \"%s\" = $1 LIMIT 1;",
-			f.Variable.Name, f.StorageName, prefixedVarName)
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1 LIMIT 1;",
+			quoteIdentifier(f.Variable.Name), quoteIdentifier(f.StorageName), quoteIdentifier(prefixedVarName))
 
 		// execute the postgres query
 		rows, err := f.Storage.client.Query(query, bucket.Key)
@@ -107,8 +107,8 @@ func (f *TimeSeriesField) fetchHistogram(filterParams *api.FilterParams) (*api.H
 	}
 
 	// Get count by category.
-	query := fmt.Sprintf("SELECT \"%s\", COUNT(*) AS count FROM %s %s GROUP BY \"%s\" ORDER BY count desc, \"%s\" LIMIT %d;",
-		prefixedVarName, f.StorageName, where, prefixedVarName, prefixedVarName, catResultLimit)
+	query := fmt.Sprintf("SELECT %s, COUNT(*) AS count FROM %s %s GROUP BY %s ORDER BY count desc, %s LIMIT %d;",
+		quoteIdentifier(prefixedVarName), quoteIdentifier(f.StorageName), where, quoteIdentifier(prefixedVarName), quoteIdentifier(prefixedVarName), catResultLimit)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -135,7 +135,7 @@ func (f *TimeSeriesField) fetchHistogram(filterParams *api.FilterParams) (*api.H
 func (f *TimeSeriesField) fetchHistogramByResult(resultURI string, filterParams *api.FilterParams) (*api.Histogram, error) {
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, filterParams)
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, "", filterParams)
 	if err != nil {
 		return nil, err
 	}
@@ -151,14 +151,14 @@ func (f *TimeSeriesField) fetchHistogramByResult(resultURI string, filterParams
 
 	// Get count by category.
 	query := fmt.Sprintf(
-		`SELECT data."%s", COUNT(*) AS count
-		 FROM %s data INNER JOIN %s result ON data."%s" = result.index
+		`SELECT data.%s, COUNT(*) AS count
+		 FROM %s data INNER JOIN %s result ON data.%s = result.index
 		 WHERE result.result_id = $%d %s
-		 GROUP BY "%s"
-		 ORDER BY count desc, "%s" LIMIT %d;`,
-		prefixedVarName, f.StorageName, f.Storage.getResultTable(f.StorageName),
-		model.D3MIndexFieldName, len(params), where, prefixedVarName,
-		prefixedVarName, catResultLimit)
+		 GROUP BY %s
+		 ORDER BY count desc, %s LIMIT %d;`,
+		quoteIdentifier(prefixedVarName), quoteIdentifier(f.StorageName), quoteIdentifier(f.Storage.getResultTable(f.StorageName)),
+		quoteIdentifier(model.D3MIndexFieldName), len(params), where, quoteIdentifier(prefixedVarName),
+		quoteIdentifier(prefixedVarName), catResultLimit)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -234,21 +234,21 @@ func (f *TimeSeriesField) FetchPredictedSummaryData(resultURI string, datasetRes
 	targetName := f.clusterVarName(f.Variable.Name)
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, filterParams)
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.StorageName, resultURI, targetName, filterParams)
 	if err != nil {
 		return nil, err
 	}
 
-	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d AND result.target = $%d ", len(params)+1, len(params)+2))
-	params = append(params, resultURI, targetName)
+	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d ", len(params)+1))
+	params = append(params, resultURI)
 
 	query := fmt.Sprintf(
-		`SELECT data."%s", result.value, COUNT(*) AS count
-		 FROM %s AS result INNER JOIN %s AS data ON result.index = data."%s"
+		`SELECT data.%s, result.value, COUNT(*) AS count
+		 FROM %s AS result INNER JOIN %s AS data ON result.index = data.%s
 		 WHERE %s
-		 GROUP BY result.value, data."%s"
+		 GROUP BY result.value, data.%s
 		 ORDER BY count desc;`,
-		targetName, datasetResult, f.StorageName, model.D3MIndexFieldName, strings.Join(wheres, " AND "), targetName)
+		quoteIdentifier(targetName), quoteIdentifier(datasetResult), quoteIdentifier(f.StorageName), quoteIdentifier(model.D3MIndexFieldName), strings.Join(wheres, " AND "), quoteIdentifier(targetName))
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
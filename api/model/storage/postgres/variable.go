@@ -61,7 +61,7 @@ func (s *Storage) getMinMaxAggsQuery(variable *model.Variable) string {
 	maxAggName := api.MaxAggPrefix + variable.Name
 
 	// create aggregations
-	queryPart := fmt.Sprintf("MIN(\"%s\") AS \"%s\", MAX(\"%s\") AS \"%s\"", variable.Name, minAggName, variable.Name, maxAggName)
+	queryPart := fmt.Sprintf("MIN(%s) AS %s, MAX(%s) AS %s", quoteIdentifier(variable.Name), quoteIdentifier(minAggName), quoteIdentifier(variable.Name), quoteIdentifier(maxAggName))
 	// add aggregations
 	return queryPart
 }
@@ -71,7 +71,7 @@ func (s *Storage) fetchExtrema(dataset string, variable *model.Variable) (*api.E
 	aggQuery := s.getMinMaxAggsQuery(variable)
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s;", aggQuery, dataset)
+	queryString := fmt.Sprintf("SELECT %s FROM %s;", aggQuery, quoteIdentifier(dataset))
 
 	// execute the postgres query
 	// NOTE: We may want to use the regular Query operation since QueryRow
@@ -92,8 +92,8 @@ func (s *Storage) fetchExtremaByURI(storageName string, resultURI string, variab
 	aggQuery := s.getMinMaxAggsQuery(variable)
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s data INNER JOIN %s result ON data.\"%s\" = result.index WHERE result.result_id = $1;",
-		aggQuery, storageName, s.getResultTable(storageName), model.D3MIndexFieldName)
+	queryString := fmt.Sprintf("SELECT %s FROM %s data INNER JOIN %s result ON data.%s = result.index WHERE result.result_id = $1;",
+		aggQuery, quoteIdentifier(storageName), quoteIdentifier(s.getResultTable(storageName)), quoteIdentifier(model.D3MIndexFieldName))
 
 	// execute the postgres query
 	// NOTE: We may want to use the regular Query operation since QueryRow
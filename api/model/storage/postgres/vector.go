@@ -104,6 +104,6 @@ func (f *VectorField) isNumerical() bool {
 }
 
 func (f *VectorField) subSelect() string {
-	return fmt.Sprintf("(SELECT \"%s\", unnest(\"%s\") as %s FROM %s)",
-		model.D3MIndexFieldName, f.Unnested, f.Variable.Name, f.StorageName)
+	return fmt.Sprintf("(SELECT %s, unnest(%s) as %s FROM %s)",
+		quoteIdentifier(model.D3MIndexFieldName), quoteIdentifier(f.Unnested), quoteIdentifier(f.Variable.Name), quoteIdentifier(f.StorageName))
 }
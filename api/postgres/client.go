@@ -17,6 +17,7 @@ package postgres
 
 import (
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -44,6 +45,9 @@ type DatabaseDriver interface {
 	Query(string, ...interface{}) (*pgx.Rows, error)
 	QueryRow(string, ...interface{}) *pgx.Row
 	Exec(string, ...interface{}) (pgx.CommandTag, error)
+	CopyFromReader(io.Reader, string) (int64, error)
+	CopyFrom(pgx.Identifier, []string, pgx.CopyFromSource) (int, error)
+	Begin() (*pgx.Tx, error)
 	GetUpdateClient() *pg.DB
 }
 
@@ -89,6 +93,33 @@ func (ic IntegratedClient) Exec(sql string, params ...interface{}) (pgx.CommandT
 	return ic.pgxClient.Exec(sql, params...)
 }
 
+// CopyFromReader streams the reader contents to the database using the
+// supplied COPY FROM STDIN statement, returning the number of rows copied.
+func (ic IntegratedClient) CopyFromReader(r io.Reader, sql string) (int64, error) {
+	conn, err := ic.pgxClient.Acquire()
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to acquire connection")
+	}
+	defer ic.pgxClient.Release(conn)
+
+	tag, err := conn.CopyFromReader(r, sql)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CopyFrom copies the rows of the source into the columns of the table
+// using the COPY protocol, returning the number of rows copied.
+func (ic IntegratedClient) CopyFrom(tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int, error) {
+	return ic.pgxClient.CopyFrom(tableName, columnNames, rowSrc)
+}
+
+// Begin starts a transaction on a connection from the pool.
+func (ic IntegratedClient) Begin() (*pgx.Tx, error) {
+	return ic.pgxClient.Begin()
+}
+
 func (p pgxLogAdapter) Log(level pgx.LogLevel, msg string, data map[string]interface{}) {
 	switch level {
 	case pgx.LogLevelDebug:
@@ -0,0 +1,30 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"strings"
+)
+
+// QuoteIdentifier quotes a table, view or column name for use in a
+// dynamically built query. Embedded double quotes are escaped so names taken
+// from uploaded data, including ones with spaces or matching SQL keywords,
+// always refer to a single identifier. Every name interpolated into SQL must
+// go through this function.
+func QuoteIdentifier(name string) string {
+	name = strings.Replace(name, "\x00", "", -1)
+	return "\"" + strings.Replace(name, "\"", "\"\"", -1) + "\""
+}
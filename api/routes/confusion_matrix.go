@@ -0,0 +1,107 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"goji.io/pat"
+
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// ConfusionMatrix contains the confusion matrix of a result.
+type ConfusionMatrix struct {
+	ConfusionMatrix *api.ConfusionMatrix `json:"confusionMatrix"`
+}
+
+// ConfusionMatrixHandler computes the confusion matrix and per class report
+// of a categorical result for consumption in a downstream view.
+func ConfusionMatrixHandler(solutionCtor api.SolutionStorageCtor, dataCtor api.DataStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// extract route parameters
+		dataset := pat.Param(r, "dataset")
+		storageName := model.NormalizeDatasetID(dataset)
+
+		// results of multiple targets are summarized for the requested one,
+		// defaulting to the first target
+		target := r.URL.Query().Get("target")
+
+		resultUUID, err := url.PathUnescape(pat.Param(r, "results-uuid"))
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to unescape results uuid"))
+			return
+		}
+
+		// parse POST params
+		params, err := getPostParameters(r)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "Unable to parse post parameters"))
+			return
+		}
+
+		// get variable names and ranges out of the params
+		filterParams, err := api.ParseFilterParamsFromJSON(params)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		solution, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		data, err := dataCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// get the result URI
+		res, err := solution.FetchSolutionResultByUUID(resultUUID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if res == nil {
+			http.Error(w, fmt.Sprintf("result %s not found", resultUUID), http.StatusNotFound)
+			return
+		}
+
+		// compute the confusion matrix
+		matrix, err := data.FetchConfusionMatrix(dataset, storageName, res.ResultURI, target, filterParams)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		matrix.SolutionID = res.SolutionID
+
+		// marshal data and sent the response back
+		err = handleJSON(w, ConfusionMatrix{
+			ConfusionMatrix: matrix,
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal confusion matrix into JSON"))
+			return
+		}
+	}
+}
@@ -38,6 +38,10 @@ func CorrectnessSummaryHandler(solutionCtor api.SolutionStorageCtor, dataCtor ap
 		dataset := pat.Param(r, "dataset")
 		storageName := model.NormalizeDatasetID(dataset)
 
+		// results of multiple targets are summarized for the requested one,
+		// defaulting to the first target
+		target := r.URL.Query().Get("target")
+
 		resultUUID, err := url.PathUnescape(pat.Param(r, "results-uuid"))
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable to unescape results uuid"))
@@ -78,7 +82,7 @@ func CorrectnessSummaryHandler(solutionCtor api.SolutionStorageCtor, dataCtor ap
 		}
 
 		// fetch summary histogram
-		histogram, err := data.FetchCorrectnessSummary(dataset, storageName, res.ResultURI, filterParams)
+		histogram, err := data.FetchCorrectnessSummary(dataset, storageName, res.ResultURI, target, filterParams)
 		if err != nil {
 			handleError(w, err)
 			return
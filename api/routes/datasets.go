@@ -27,6 +27,7 @@ import (
 
 	"github.com/uncharted-distil/distil/api/model"
 	"github.com/uncharted-distil/distil/api/model/storage/datamart"
+	"github.com/uncharted-distil/distil/api/task"
 )
 
 // DatasetResult represents the result of a dataset response.
@@ -77,7 +78,8 @@ func DatasetHandler(ctor model.MetadataStorageCtor) func(http.ResponseWriter, *h
 // variable list for any dataset that matches. The search parameter is optional
 // it contains the search terms if set, and if unset, flags that a list of all
 // datasets should be returned.  The full list will be contain names only,
-// descriptions and variable lists will not be included.
+// descriptions and variable lists will not be included. Setting the `latest`
+// parameter only lists the latest version of each dataset.
 func DatasetsHandler(metaCtors []model.MetadataStorageCtor) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var datasets []*model.Dataset
@@ -134,6 +136,11 @@ func DatasetsHandler(metaCtors []model.MetadataStorageCtor) func(http.ResponseWr
 			deconflicted = append(deconflicted, dataset)
 		}
 
+		// optionally only list the latest version of each dataset
+		if r.URL.Query().Get("latest") == "true" {
+			deconflicted = model.FilterLatestVersions(deconflicted)
+		}
+
 		// marshal data
 		err = handleJSON(w, DatasetsResult{
 			Datasets: deconflicted,
@@ -151,3 +158,45 @@ func renderMarkdown(markdown string) string {
 	// just to be safe, sanatize the HTML
 	return string(bluemonday.UGCPolicy().SanitizeBytes(unsafe))
 }
+
+// DatasetDeleteHandler generates a route handler that deletes a dataset along
+// with its stored data, solutions and working folders. Setting the `dryRun`
+// query parameter lists what would be deleted without removing anything.
+func DatasetDeleteHandler(metaCtor model.MetadataStorageCtor, dataCtor model.DataStorageCtor, solutionCtor model.SolutionStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// get dataset name
+		dataset := pat.Param(r, "dataset")
+		dryRun := r.URL.Query().Get("dryRun") == "true"
+
+		meta, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		data, err := dataCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		solution, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		deletion, err := task.DeleteDataset(dataset, meta, data, solution, dryRun)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data
+		err = handleJSON(w, deletion)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal dataset deletion into JSON"))
+			return
+		}
+	}
+}
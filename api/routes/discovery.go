@@ -65,6 +65,13 @@ func ProblemDiscoveryHandler(ctorData api.DataStorageCtor, ctorMeta api.Metadata
 		}
 		filterParams.Size = -1
 
+		// the optional split is described as in the solution requests
+		split, err := parseSplitParam(params["split"])
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
 		// NOTE: D3M index field is needed in the persisted data.
 		filterParams.Variables = append(filterParams.Variables, model.D3MIndexFieldName)
 
@@ -93,7 +100,7 @@ func ProblemDiscoveryHandler(ctorData api.DataStorageCtor, ctorMeta api.Metadata
 			return
 		}
 
-		problem, problemID, err := compute.CreateProblemSchema(problemDir, dataset, targetVar, filterParams)
+		problem, problemID, err := compute.CreateProblemSchema(problemDir, dataset, targetVar, filterParams, split)
 		if err != nil {
 			handleError(w, err)
 			return
@@ -172,3 +179,15 @@ func ProblemDiscoveryHandler(ctorData api.DataStorageCtor, ctorMeta api.Metadata
 		w.Write(bytes)
 	}
 }
+
+func parseSplitParam(param interface{}) (*compute.SplitSpec, error) {
+	if param == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(param)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal split")
+	}
+	return compute.ParseSplitSpec(data)
+}
@@ -16,7 +16,6 @@
 package routes
 
 import (
-	"context"
 	"net/http"
 	"os"
 
@@ -24,46 +23,69 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	api "github.com/uncharted-distil/distil/api/compute"
 	"github.com/uncharted-distil/distil/api/model"
 	"github.com/unchartedsoftware/plog"
 )
 
+// ExportResult contains the location of an exported solution bundle.
+type ExportResult struct {
+	SolutionID string `json:"solutionId"`
+	Path       string `json:"path"`
+}
+
 // ExportHandler exports the caller supplied solution by calling through to the compute
-// server export functionality.
-func ExportHandler(solutionCtor model.SolutionStorageCtor, metaCtor model.MetadataStorageCtor, client *compute.Client, exportPath string) func(http.ResponseWriter, *http.Request) {
+// server export functionality and writing a bundle describing the solution to the
+// export path. In evaluation mode the process exits once the export completes, as
+// expected by the evaluation harness, the exit status only reflecting the export by
+// the compute server.
+func ExportHandler(solutionCtor model.SolutionStorageCtor, metaCtor model.MetadataStorageCtor, client *compute.Client, exportPath string, exitOnExport bool) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// extract route parameters
 		solutionID := pat.Param(r, "solution-id")
 
-		// get the solution target
 		solution, err := solutionCtor()
 		if err != nil {
 			handleError(w, err)
 			return
 		}
 
-		// get the initial target
-		sol, err := solution.FetchSolution(solutionID)
+		meta, err := metaCtor()
 		if err != nil {
 			handleError(w, err)
 			return
 		}
 
-		// export relies on a fitted model
-		fittedSolutionID := sol.Result.FittedSolutionID
-		if fittedSolutionID == "" {
-			handleError(w, errors.Errorf("export failed - no fitted solution found for solution %s", solutionID))
+		err = api.ExportSolution(client, solution, solutionID)
+		if exitOnExport && err != nil {
+			log.Errorf("Failed solution export request for %s: %+v", solutionID, err)
+			os.Exit(1)
+		}
+		if err != nil {
+			handleError(w, err)
 			return
 		}
 
-		err = client.ExportSolution(context.Background(), fittedSolutionID)
-		if err != nil {
-			log.Infof("Failed solution export request for %s", fittedSolutionID)
-			os.Exit(1)
-		} else {
-			log.Infof("Completed export request for %s", fittedSolutionID)
+		bundlePath, err := api.WriteSolutionBundle(solution, meta, solutionID, exportPath)
+		if exitOnExport {
+			if err != nil {
+				log.Warnf("Unable to write export bundle for %s: %+v", solutionID, err)
+			}
+			log.Infof("Completed export request for %s", solutionID)
 			os.Exit(0)
 		}
-		return
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, &ExportResult{
+			SolutionID: solutionID,
+			Path:       bundlePath,
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal export result into JSON"))
+			return
+		}
 	}
 }
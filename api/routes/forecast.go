@@ -0,0 +1,98 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"goji.io/pat"
+
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// Forecast contains the forecasted and actual series of a result.
+type Forecast struct {
+	Forecast *api.Forecast `json:"forecast"`
+}
+
+// ForecastHandler fetches the forecasted series of a result per time series,
+// along with the actual series for plotting. The time column is required and
+// the series column identifies the series of datasets holding several.
+func ForecastHandler(solutionCtor api.SolutionStorageCtor, dataCtor api.DataStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// extract route parameters
+		dataset := pat.Param(r, "dataset")
+		target := pat.Param(r, "target")
+		storageName := model.NormalizeDatasetID(dataset)
+
+		timeColumn := r.URL.Query().Get("time")
+		if timeColumn == "" {
+			handleError(w, errors.New("time column is required"))
+			return
+		}
+		seriesColumn := r.URL.Query().Get("series")
+
+		resultUUID, err := url.PathUnescape(pat.Param(r, "results-uuid"))
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to unescape results uuid"))
+			return
+		}
+
+		solution, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		data, err := dataCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// get the result URI
+		res, err := solution.FetchSolutionResultByUUID(resultUUID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if res == nil {
+			http.Error(w, fmt.Sprintf("result %s not found", resultUUID), http.StatusNotFound)
+			return
+		}
+
+		forecast, err := data.FetchForecast(dataset, storageName, res.ResultURI, target, timeColumn, seriesColumn)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		forecast.Key = api.GetPredictedKey(forecast.Key, res.SolutionID)
+		forecast.SolutionID = res.SolutionID
+
+		// marshal data and sent the response back
+		err = handleJSON(w, Forecast{
+			Forecast: forecast,
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal forecast into JSON"))
+			return
+		}
+	}
+}
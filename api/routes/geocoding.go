@@ -70,26 +70,17 @@ func GeocodingHandler(metaCtor api.MetadataStorageCtor, dataCtor api.DataStorage
 			return
 		}
 
-		// create the new metadata and database variables
+		// create the new database and metadata variables, the data storage
+		// updating the metadata while holding the dataset lock
 		if !latVarExist {
-			err = metaStorage.AddVariable(dataset, latVarName, model.LatitudeType, "geocoding")
-			if err != nil {
-				handleError(w, err)
-				return
-			}
-			err = dataStorage.AddVariable(dataset, storageName, latVarName, model.LatitudeType)
+			err = dataStorage.AddVariable(dataset, storageName, latVarName, model.LatitudeType, "geocoding")
 			if err != nil {
 				handleError(w, err)
 				return
 			}
 		}
 		if !lonVarExist {
-			err = metaStorage.AddVariable(dataset, lonVarName, model.LongitudeType, "geocoding")
-			if err != nil {
-				handleError(w, err)
-				return
-			}
-			err = dataStorage.AddVariable(dataset, storageName, lonVarName, model.LongitudeType)
+			err = dataStorage.AddVariable(dataset, storageName, lonVarName, model.LongitudeType, "geocoding")
 			if err != nil {
 				handleError(w, err)
 				return
@@ -29,7 +29,9 @@ import (
 	"github.com/uncharted-distil/distil/api/task"
 )
 
-// ImportHandler imports a dataset to the local file system and then ingests it.
+// ImportHandler imports a dataset to the local file system and then submits
+// an ingest job for it, returning the job id without waiting for the ingest
+// to complete.
 func ImportHandler(nyuDatamartMetaCtor model.MetadataStorageCtor, isiDatamartMetaCtor model.MetadataStorageCtor, fileMetaCtor model.MetadataStorageCtor, esMetaCtor model.MetadataStorageCtor, config *task.IngestTaskConfig) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		datasetID := pat.Param(r, "datasetID")
@@ -54,6 +56,14 @@ func ImportHandler(nyuDatamartMetaCtor model.MetadataStorageCtor, isiDatamartMet
 
 		ingestConfig := *config
 		ingestConfig.SummaryEnabled = false
+		if restageFrom := r.URL.Query().Get("restageFrom"); restageFrom != "" {
+			err = task.ValidateRestageStage(restageFrom, &ingestConfig)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			ingestConfig.RestageFrom = restageFrom
+		}
 
 		_, err = meta.ImportDataset(datasetID, uri)
 		if err != nil {
@@ -62,14 +72,13 @@ func ImportHandler(nyuDatamartMetaCtor model.MetadataStorageCtor, isiDatamartMet
 		}
 
 		// ingest the imported dataset
-		err = task.IngestDataset(source, esMetaCtor, cfg.ESDatasetsIndex, datasetID, &ingestConfig)
-		if err != nil {
-			handleError(w, err)
-			return
-		}
+		job := task.SubmitIngestJob(source, esMetaCtor, cfg.ESDatasetsIndex, datasetID, &ingestConfig)
 
 		// marshal data and sent the response back
-		err = handleJSON(w, map[string]interface{}{"result": "ingested"})
+		err = handleJSON(w, map[string]interface{}{
+			"result": "ingesting",
+			"jobId":  job.ID,
+		})
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable marshal result histogram into JSON"))
 			return
@@ -0,0 +1,108 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"goji.io/pat"
+
+	"github.com/uncharted-distil/distil/api/task"
+)
+
+// IngestStatusHandler returns the status of an ingest job, including the
+// progress and timing of each stage.
+func IngestStatusHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobID := pat.Param(r, "job-id")
+
+		job, err := task.FetchIngestJob(jobID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data and sent the response back
+		err = handleJSON(w, job.Status())
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal ingest status into JSON"))
+			return
+		}
+	}
+}
+
+// IngestCancelHandler cancels a running ingest job. The job stops once the
+// stage currently executing finishes.
+func IngestCancelHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobID := pat.Param(r, "job-id")
+
+		err := task.CancelIngestJob(jobID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data and sent the response back
+		err = handleJSON(w, map[string]interface{}{
+			"success": true,
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal response into JSON"))
+			return
+		}
+	}
+}
+
+// IngestRetryHandler resubmits a finished ingest job, reusing the outputs of
+// the stages it completed. An optional `restageFrom` query parameter forces
+// that stage and every stage after it to rerun.
+func IngestRetryHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobID := pat.Param(r, "job-id")
+		restageFrom := r.URL.Query().Get("restageFrom")
+
+		if restageFrom != "" {
+			job, err := task.FetchIngestJob(jobID)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+			err = job.ValidateRestageStage(restageFrom)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		job, err := task.RetryIngestJob(jobID, restageFrom)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data and sent the response back
+		err = handleJSON(w, map[string]interface{}{
+			"result": "ingesting",
+			"jobId":  job.ID,
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal response into JSON"))
+			return
+		}
+	}
+}
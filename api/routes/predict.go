@@ -0,0 +1,186 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"goji.io/pat"
+
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/uncharted-distil/distil-ingest/metadata"
+	api "github.com/uncharted-distil/distil/api/compute"
+	"github.com/uncharted-distil/distil/api/env"
+	"github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/task"
+)
+
+// PredictHandler produces predictions for a dataset using the fitted version
+// of the supplied solution. The dataset is either an ingested dataset read
+// from the `dataset` POST parameter, or a file uploaded as multipart form data
+// that is ingested first as the dataset named by the `dataset` query
+// parameter.
+func PredictHandler(client *compute.Client, solutionCtor model.SolutionStorageCtor, metaCtor model.MetadataStorageCtor, dataCtor model.DataStorageCtor,
+	fileMetaCtor model.MetadataStorageCtor, uploadPath string, maxSize int64, config *task.IngestTaskConfig) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		solutionID, err := url.PathUnescape(pat.Param(r, "solution-id"))
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to unescape solution id"))
+			return
+		}
+
+		dataset := ""
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			dataset = r.URL.Query().Get("dataset")
+			if dataset == "" {
+				handleError(w, errors.New("no dataset name specified for the uploaded file"))
+				return
+			}
+			err = ingestUpload(w, r, dataset, fileMetaCtor, metaCtor, uploadPath, maxSize, config)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+		} else {
+			params, err := getPostParameters(r)
+			if err != nil {
+				handleError(w, errors.Wrap(err, "Unable to parse post parameters"))
+				return
+			}
+			dataset, _ = params["dataset"].(string)
+			if dataset == "" {
+				handleError(w, errors.New("no dataset specified for prediction"))
+				return
+			}
+		}
+
+		solution, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		meta, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		data, err := dataCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		prediction, err := api.ProducePredictions(client, solution, meta, data, solutionID, dataset)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data and sent the response back
+		err = handleJSON(w, prediction)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal prediction into JSON"))
+			return
+		}
+	}
+}
+
+// ingestUpload creates a dataset from the file uploaded with the request and
+// ingests it, waiting for the ingest to complete.
+func ingestUpload(w http.ResponseWriter, r *http.Request, dataset string, fileMetaCtor model.MetadataStorageCtor, metaCtor model.MetadataStorageCtor,
+	uploadPath string, maxSize int64, config *task.IngestTaskConfig) error {
+	filename, format, err := receiveFile(w, r, maxSize)
+	if err != nil {
+		return errors.Wrap(err, "unable to receive file from request")
+	}
+	defer os.Remove(filename)
+
+	_, err = task.CreateDatasetFromUpload(dataset, filename, format, uploadPath, config)
+	if err != nil {
+		return errors.Wrap(err, "unable to create dataset")
+	}
+
+	fileMeta, err := fileMetaCtor()
+	if err != nil {
+		return err
+	}
+	_, err = fileMeta.ImportDataset(dataset, env.ResolvePath(metadata.Augmented, dataset))
+	if err != nil {
+		return err
+	}
+
+	cfg, err := env.LoadConfig()
+	if err != nil {
+		return err
+	}
+	ingestConfig := *config
+	ingestConfig.SummaryEnabled = false
+
+	job := task.SubmitIngestJob(metadata.Augmented, metaCtor, cfg.ESDatasetsIndex, dataset, &ingestConfig)
+	err = job.Wait()
+	if err != nil {
+		return errors.Wrap(err, "unable to ingest uploaded dataset")
+	}
+
+	return nil
+}
+
+// PredictionDownloadHandler returns the predictions of a result set as a CSV
+// file.
+func PredictionDownloadHandler(solutionCtor model.SolutionStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resultUUID := pat.Param(r, "results-uuid")
+
+		solution, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		prediction, err := solution.FetchPrediction(resultUUID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if prediction == nil {
+			http.Error(w, fmt.Sprintf("prediction %s not found", resultUUID), http.StatusNotFound)
+			return
+		}
+
+		file, err := os.Open(prediction.ResultURI)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to open prediction file"))
+			return
+		}
+		defer file.Close()
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s_predictions.csv\"", prediction.Dataset))
+		_, err = io.Copy(w, file)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to write prediction file"))
+			return
+		}
+	}
+}
@@ -58,7 +58,7 @@ func fetchSolutionPredictedExtrema(meta api.MetadataStorage, data api.DataStorag
 				// result uri
 				resultURI := sol.Result.ResultURI
 				// predicted extrema
-				predictedExtrema, err := data.FetchResultsExtremaByURI(dataset, storageName, resultURI)
+				predictedExtrema, err := data.FetchResultsExtremaByURI(dataset, storageName, resultURI, target)
 				if err != nil {
 					return nil, err
 				}
@@ -138,7 +138,7 @@ func PredictedSummaryHandler(metaCtor api.MetadataStorageCtor, solutionCtor api.
 		}
 
 		// fetch summary histogram
-		histogram, err := data.FetchPredictedSummary(dataset, storageName, res.ResultURI, filterParams, extrema)
+		histogram, err := data.FetchPredictedSummary(dataset, storageName, res.ResultURI, target, filterParams, extrema)
 		if err != nil {
 			handleError(w, err)
 			return
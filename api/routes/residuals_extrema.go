@@ -57,7 +57,7 @@ func fetchSolutionResidualExtrema(meta api.MetadataStorage, data api.DataStorage
 				// result uri
 				resultURI := sol.Result.ResultURI
 				// predicted extrema
-				residualExtrema, err := data.FetchResidualsExtremaByURI(dataset, storageName, resultURI)
+				residualExtrema, err := data.FetchResidualsExtremaByURI(dataset, storageName, resultURI, target)
 				if err != nil {
 					return nil, err
 				}
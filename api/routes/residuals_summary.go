@@ -92,7 +92,7 @@ func ResidualsSummaryHandler(metaCtor api.MetadataStorageCtor, solutionCtor api.
 		}
 
 		// fetch summary histogram
-		histogram, err := data.FetchResidualsSummary(dataset, storageName, res.ResultURI, filterParams, extrema)
+		histogram, err := data.FetchResidualsSummary(dataset, storageName, res.ResultURI, target, filterParams, extrema)
 		if err != nil {
 			handleError(w, err)
 			return
@@ -27,28 +27,36 @@ import (
 
 // Solution represents a pipeline solution.
 type Solution struct {
-	RequestID    string                 `json:"requestId"`
-	Feature      string                 `json:"feature"`
-	SolutionID   string                 `json:"solutionId"`
-	ResultUUID   string                 `json:"resultId"`
-	Progress     string                 `json:"progress"`
-	Scores       []*model.SolutionScore `json:"scores"`
-	Timestamp    time.Time              `json:"timestamp"`
-	Dataset      string                 `json:"dataset"`
-	Features     []*model.Feature       `json:"features"`
-	Filters      *model.FilterParams    `json:"filters"`
-	PredictedKey string                 `json:"predictedKey"`
-	ErrorKey     string                 `json:"errorKey"`
+	RequestID     string                 `json:"requestId"`
+	Feature       string                 `json:"feature"`
+	Targets       []string               `json:"targets"`
+	SolutionID    string                 `json:"solutionId"`
+	ResultUUID    string                 `json:"resultId"`
+	Progress      string                 `json:"progress"`
+	Scores        []*model.SolutionScore `json:"scores"`
+	CVScores      []*model.SolutionScore `json:"crossValidationScores"`
+	Timestamp     time.Time              `json:"timestamp"`
+	Dataset       string                 `json:"dataset"`
+	Features      []*model.Feature       `json:"features"`
+	Filters       *model.FilterParams    `json:"filters"`
+	PredictedKey  string                 `json:"predictedKey"`
+	ErrorKey      string                 `json:"errorKey"`
+	PredictedKeys map[string]string      `json:"predictedKeys"`
+	ErrorKeys     map[string]string      `json:"errorKeys"`
+	IsBad         bool                   `json:"isBad"`
+	Flags         []*model.SolutionFlag  `json:"flags"`
 }
 
 // RequestResponse represents a request response.
 type RequestResponse struct {
-	RequestID string      `json:"requestId"`
-	Dataset   string      `json:"dataset"`
-	Feature   string      `json:"feature"`
-	Progress  string      `json:"progress"`
-	Timestamp time.Time   `json:"timestamp"`
-	Solutions []*Solution `json:"solutions"`
+	RequestID string                 `json:"requestId"`
+	Dataset   string                 `json:"dataset"`
+	Feature   string                 `json:"feature"`
+	Targets   []string               `json:"targets"`
+	Progress  string                 `json:"progress"`
+	Timestamp time.Time              `json:"timestamp"`
+	Solutions []*Solution            `json:"solutions"`
+	Baselines []*model.BaselineScore `json:"baselines"`
 }
 
 // SolutionHandler fetches existing solutions.
@@ -94,16 +102,36 @@ func SolutionHandler(solutionCtor model.SolutionStorageCtor) func(http.ResponseW
 					RequestID: req.RequestID,
 					Dataset:   req.Dataset,
 					Feature:   req.TargetFeature(),
+					Targets:   req.TargetFeatures(),
 					Features:  req.Features,
 					Filters:   req.Filters,
 					// solution
 					SolutionID: sol.SolutionID,
-					Scores:     sol.Scores,
+					Scores:     make([]*model.SolutionScore, 0),
+					CVScores:   make([]*model.SolutionScore, 0),
 					Timestamp:  sol.CreatedTime,
 					Progress:   sol.Progress,
 					// keys
-					PredictedKey: model.GetPredictedKey(req.TargetFeature(), sol.SolutionID),
-					ErrorKey:     model.GetErrorKey(req.TargetFeature(), sol.SolutionID),
+					PredictedKey:  model.GetPredictedKey(req.TargetFeature(), sol.SolutionID),
+					ErrorKey:      model.GetErrorKey(req.TargetFeature(), sol.SolutionID),
+					PredictedKeys: make(map[string]string),
+					ErrorKeys:     make(map[string]string),
+					// quality checks
+					IsBad: sol.IsBad,
+					Flags: sol.Flags,
+				}
+				// each target has its own predicted and error columns
+				for _, target := range req.TargetFeatures() {
+					solution.PredictedKeys[target] = model.GetPredictedKey(target, sol.SolutionID)
+					solution.ErrorKeys[target] = model.GetErrorKey(target, sol.SolutionID)
+				}
+				// cross validation scores are listed apart from the held out scores
+				for _, score := range sol.Scores {
+					if score.Type == "" || score.Type == model.ScoreTypeHoldOut {
+						solution.Scores = append(solution.Scores, score)
+					} else {
+						solution.CVScores = append(solution.CVScores, score)
+					}
 				}
 				if sol.Result != nil {
 					// result
@@ -112,13 +140,22 @@ func SolutionHandler(solutionCtor model.SolutionStorageCtor) func(http.ResponseW
 				solutions = append(solutions, solution)
 			}
 
+			// baselines give a reference point for the solution scores
+			baselines, err := solution.FetchBaselineScores(req.RequestID)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+
 			response = append(response, &RequestResponse{
 				RequestID: req.RequestID,
 				Dataset:   req.Dataset,
 				Feature:   req.TargetFeature(),
+				Targets:   req.TargetFeatures(),
 				Progress:  req.Progress,
 				Timestamp: req.CreatedTime,
 				Solutions: solutions,
+				Baselines: baselines,
 			})
 		}
 
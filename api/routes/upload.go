@@ -16,31 +16,38 @@
 package routes
 
 import (
-	"bytes"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path"
 
 	"github.com/pkg/errors"
 	log "github.com/unchartedsoftware/plog"
 	"goji.io/pat"
 
+	"github.com/uncharted-distil/distil/api/env"
 	"github.com/uncharted-distil/distil/api/task"
 )
 
 // UploadHandler uploads a file to the local file system and then imports it.
-func UploadHandler(outputPath string, config *task.IngestTaskConfig) func(http.ResponseWriter, *http.Request) {
+// Csv, parquet and newline delimited JSON files are supported, as well as zip
+// archives of complete D3M datasets, detected by content type or extension.
+// Uploads are streamed to disk and rejected once they exceed the max size.
+func UploadHandler(outputPath string, maxSize int64, config *task.IngestTaskConfig) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		dataset := pat.Param(r, "dataset")
 
-		// read the file from the request
-		bytes, err := receiveFile(r)
+		// stream the file from the request to disk
+		filename, format, err := receiveFile(w, r, maxSize)
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable to receive file from request"))
 			return
 		}
+		defer os.Remove(filename)
 
 		// create the raw dataset schema doc
-		formattedPath, err := task.CreateDataset(dataset, bytes, outputPath, config)
+		formattedPath, err := task.CreateDatasetFromUpload(dataset, filename, format, outputPath, config)
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable to create dataset"))
 			return
@@ -55,19 +62,64 @@ func UploadHandler(outputPath string, config *task.IngestTaskConfig) func(http.R
 	}
 }
 
-func receiveFile(r *http.Request) ([]byte, error) {
-	file, _, err := r.FormFile("file")
+func receiveFile(w http.ResponseWriter, r *http.Request, maxSize int64) (string, string, error) {
+	// leave some headroom for the multipart framing
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1024*1024)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to read multipart request")
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return "", "", errors.New("no file found in request")
+		}
+		if err != nil {
+			return "", "", errors.Wrap(err, "unable to read multipart request")
+		}
+
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+		defer part.Close()
+
+		filename, err := writeUpload(part, maxSize)
+		if err != nil {
+			return "", "", err
+		}
+
+		format := task.DetectUploadFormat(part.FileName(), part.Header.Get("Content-Type"))
+		return filename, format, nil
+	}
+}
+
+func writeUpload(source io.Reader, maxSize int64) (string, error) {
+	uploadPath := path.Join(env.GetTmpPath(), "uploads")
+	err := os.MkdirAll(uploadPath, os.ModePerm)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to get file from request")
+		return "", errors.Wrap(err, "unable to create upload folder")
+	}
+
+	file, err := ioutil.TempFile(uploadPath, "upload-")
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create upload file")
 	}
 	defer file.Close()
 
-	// Copy the file data to the buffer
-	var buf bytes.Buffer
-	_, err = io.Copy(&buf, file)
+	// copy one byte past the limit to detect oversized uploads
+	written, err := io.Copy(file, io.LimitReader(source, maxSize+1))
+	if err == nil && written > maxSize {
+		err = errors.Errorf("upload exceeds the maximum size of %d bytes", maxSize)
+	} else if err != nil {
+		err = errors.Wrap(err, "unable to copy file")
+	}
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to copy file")
+		os.Remove(file.Name())
+		return "", err
 	}
 
-	return buf.Bytes(), nil
+	return file.Name(), nil
 }
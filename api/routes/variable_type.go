@@ -30,7 +30,7 @@ import (
 
 // VariableTypeHandler generates a route handler that facilitates the update
 // of a variable type.
-func VariableTypeHandler(storageCtor api.DataStorageCtor, metaCtor api.MetadataStorageCtor) func(http.ResponseWriter, *http.Request) {
+func VariableTypeHandler(storageCtor api.DataStorageCtor) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		params, err := getPostParameters(r)
 		if err != nil {
@@ -48,26 +48,15 @@ func VariableTypeHandler(storageCtor api.DataStorageCtor, metaCtor api.MetadataS
 			handleError(w, err)
 			return
 		}
-		meta, err := metaCtor()
-		if err != nil {
-			handleError(w, err)
-			return
-		}
 
-		// update the variable type in the storage
+		// update the variable type in the storage, which also updates the
+		// metadata while the dataset is locked
 		err = storage.SetDataType(dataset, storageName, field, typ)
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable to update the data type in storage"))
 			return
 		}
 
-		// update the variable type in the metadata
-		err = meta.SetDataType(dataset, field, typ)
-		if err != nil {
-			handleError(w, errors.Wrap(err, "unable to update the data type in metadata"))
-			return
-		}
-
 		// TODO: fix this, this shouldn't be necessary
 		time.Sleep(time.Second)
 
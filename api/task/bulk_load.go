@@ -0,0 +1,149 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	log "github.com/unchartedsoftware/plog"
+
+	pgclient "github.com/uncharted-distil/distil/api/postgres"
+)
+
+const (
+	wordStemTableName = "word_stem"
+	wordStemBatchSize = 5000
+)
+
+// bulkLoadData streams the csv data file into the base table using COPY FROM
+// STDIN, returning the number of rows loaded.
+func bulkLoadData(client pgclient.DatabaseDriver, tableName string, dataResource *model.DataResource, dataPath string) (int64, error) {
+	reader, err := os.Open(dataPath)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to open data file")
+	}
+	defer reader.Close()
+
+	columns := make([]string, len(dataResource.Variables))
+	for i, v := range dataResource.Variables {
+		columns[i] = pgclient.QuoteIdentifier(v.Name)
+	}
+
+	sql := fmt.Sprintf("COPY %s (%s) FROM STDIN WITH (FORMAT csv, HEADER true);", pgclient.QuoteIdentifier(tableName), strings.Join(columns, ", "))
+	count, err := client.CopyFromReader(reader, sql)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to copy data into base table")
+	}
+
+	return count, nil
+}
+
+// addWordStems extracts the words of the csv data file and stores their
+// stems, letting postgres compute the stems in batches. Words are only
+// deduplicated within a batch so memory stays bounded on large datasets,
+// the insert ignoring the stems already stored.
+func addWordStems(client pgclient.DatabaseDriver, dataPath string) error {
+	reader, err := os.Open(dataPath)
+	if err != nil {
+		return errors.Wrap(err, "unable to open data file")
+	}
+	defer reader.Close()
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+
+	// skip header
+	_, err = csvReader.Read()
+	if err != nil {
+		return errors.Wrap(err, "unable to read header")
+	}
+
+	seen := make(map[string]bool)
+	batch := make([]string, 0, wordStemBatchSize)
+	count := 0
+	for {
+		line, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "unable to read data line")
+		}
+
+		for _, field := range line {
+			for _, word := range splitWords(field) {
+				if seen[word] {
+					continue
+				}
+				seen[word] = true
+				batch = append(batch, word)
+
+				if len(batch) == wordStemBatchSize {
+					err = insertWordStems(client, batch)
+					if err != nil {
+						return err
+					}
+					count += len(batch)
+					batch = batch[:0]
+					seen = make(map[string]bool)
+				}
+			}
+		}
+	}
+
+	err = insertWordStems(client, batch)
+	if err != nil {
+		return err
+	}
+	count += len(batch)
+	log.Infof("stored stems for %d words", count)
+
+	return nil
+}
+
+func insertWordStems(client pgclient.DatabaseDriver, words []string) error {
+	if len(words) == 0 {
+		return nil
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (stem, word) "+
+		"SELECT DISTINCT ON (stem) stem, word FROM "+
+		"(SELECT unnest(tsvector_to_array(to_tsvector(w))) AS stem, w AS word FROM unnest($1::text[]) AS w) AS s "+
+		"ON CONFLICT DO NOTHING;", pgclient.QuoteIdentifier(wordStemTableName))
+	_, err := client.Exec(sql, words)
+	if err != nil {
+		return errors.Wrap(err, "unable to store word stems")
+	}
+
+	return nil
+}
+
+func splitWords(field string) []string {
+	words := strings.FieldsFunc(field, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return words
+}
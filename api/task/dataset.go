@@ -19,6 +19,7 @@ import (
 	"os"
 	"path"
 
+	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
 
@@ -28,11 +29,28 @@ import (
 
 // CreateDataset structures a raw csv file into a valid D3M dataset.
 func CreateDataset(dataset string, csvData []byte, outputPath string, config *IngestTaskConfig) (string, error) {
+	return createDataset(dataset, func(dataPath string) ([]*model.Variable, error) {
+		return nil, util.WriteFileWithDirs(dataPath, csvData, os.ModePerm)
+	}, outputPath, config)
+}
+
+// CreateDatasetFromFile structures a raw csv file on disk into a valid D3M
+// dataset. The csv file is moved into the dataset folder.
+func CreateDatasetFromFile(dataset string, csvFile string, outputPath string, config *IngestTaskConfig) (string, error) {
+	return createDataset(dataset, func(dataPath string) ([]*model.Variable, error) {
+		return nil, moveFile(csvFile, dataPath)
+	}, outputPath, config)
+}
+
+// createDataset structures a csv file into a valid D3M dataset. The write
+// function stores the csv data at the supplied path. If it returns variables
+// they seed the schema in place of the raw csv header.
+func createDataset(dataset string, write func(dataPath string) ([]*model.Variable, error), outputPath string, config *IngestTaskConfig) (string, error) {
 	// save the csv file in the file system datasets folder
 	outputDatasetPath := path.Join(outputPath, dataset)
 	dataFilePath := path.Join(compute.D3MDataFolder, compute.D3MLearningData)
 	dataPath := path.Join(outputDatasetPath, dataFilePath)
-	err := util.WriteFileWithDirs(dataPath, csvData, os.ModePerm)
+	variables, err := write(dataPath)
 	if err != nil {
 		return "", err
 	}
@@ -42,6 +60,10 @@ func CreateDataset(dataset string, csvData []byte, outputPath string, config *In
 	meta := model.NewMetadata(dataset, dataset, "", datasetID)
 	dr := model.NewDataResource("0", model.ResTypeRaw, []string{compute.D3MResourceFormat})
 	dr.ResPath = dataFilePath
+	if variables != nil {
+		dr.ResType = model.ResTypeTable
+		dr.Variables = variables
+	}
 	meta.DataResources = []*model.DataResource{dr}
 
 	schemaPath := path.Join(outputDatasetPath, compute.D3MDataSchema)
@@ -71,3 +93,22 @@ func CreateDataset(dataset string, csvData []byte, outputPath string, config *In
 
 	return formattedPath, nil
 }
+
+func moveFile(source string, destination string) error {
+	err := os.MkdirAll(path.Dir(destination), os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "unable to create destination folder")
+	}
+
+	// renaming fails across devices so fall back to copying
+	err = os.Rename(source, destination)
+	if err == nil {
+		return nil
+	}
+
+	err = util.Copy(source, destination)
+	if err != nil {
+		return err
+	}
+	return os.Remove(source)
+}
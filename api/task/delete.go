@@ -0,0 +1,156 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-ingest/metadata"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// DatasetDeletion lists everything removed when deleting a dataset, or that
+// would be removed on a dry run.
+type DatasetDeletion struct {
+	Dataset  string   `json:"dataset"`
+	DryRun   bool     `json:"dryRun"`
+	Metadata bool     `json:"metadata"`
+	Tables   []string `json:"tables"`
+	Requests []string `json:"requests"`
+	Folders  []string `json:"folders"`
+}
+
+// DeleteDataset removes the dataset metadata, its database tables, every
+// request and solution made against it and its working folders on disk. Seed
+// datasets are inputs and their source folder is never removed. Every step
+// can be repeated, so a deletion that fails part way can be retried. On a dry
+// run nothing is removed and the returned deletion lists what would be.
+func DeleteDataset(dataset string, metaStorage api.MetadataStorage, dataStorage api.DataStorage, solutionStorage api.SolutionStorage, dryRun bool) (*DatasetDeletion, error) {
+	if dataset == "" {
+		return nil, errors.New("no dataset specified for deletion")
+	}
+
+	ds, err := metaStorage.FetchDataset(dataset, true, true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to fetch dataset %s", dataset)
+	}
+
+	tables, err := dataStorage.FetchDatasetTables(ds.StorageName)
+	if err != nil {
+		return nil, err
+	}
+
+	requestIDs, err := solutionStorage.FetchRequestIDsByDataset(dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	datasets, err := metaStorage.FetchDatasets(false, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch datasets")
+	}
+
+	deletion := &DatasetDeletion{
+		Dataset:  dataset,
+		DryRun:   dryRun,
+		Metadata: true,
+		Tables:   tables,
+		Requests: requestIDs,
+		Folders:  datasetFolders(dataset, ds, datasets),
+	}
+	if dryRun {
+		return deletion, nil
+	}
+
+	// the metadata is removed last so a failed deletion can be retried, the
+	// dataset still being listed along with what is left of it
+	for _, requestID := range requestIDs {
+		err = solutionStorage.DeleteRequest(requestID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = dataStorage.DeleteDataset(ds.StorageName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, folder := range deletion.Folders {
+		err = os.RemoveAll(folder)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to remove folder %s", folder)
+		}
+	}
+
+	err = metaStorage.DeleteDataset(dataset)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("deleted dataset %s", dataset)
+
+	return deletion, nil
+}
+
+// datasetFolders returns the existing on disk folders holding copies of the
+// dataset. The versions of a dataset share their folders, so a folder still
+// used by any other dataset is left out.
+func datasetFolders(dataset string, ds *api.Dataset, datasets []*api.Dataset) []string {
+	used := make(map[string]bool)
+	for _, other := range datasets {
+		if other.ID == dataset {
+			continue
+		}
+		used[other.ID] = true
+		used[other.GetRoot()] = true
+		if other.Folder != "" {
+			used[other.Folder] = true
+		}
+	}
+
+	names := []string{dataset}
+	if ds.Folder != "" && ds.Folder != dataset {
+		names = append(names, ds.Folder)
+	}
+
+	folders := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if used[name] {
+			continue
+		}
+		candidates := []string{
+			path.Join(env.GetTmpPath(), name),
+			env.ResolvePath(metadata.Augmented, name),
+			env.ResolvePath(metadata.Contrib, name),
+		}
+		for _, folder := range candidates {
+			if seen[folder] {
+				continue
+			}
+			seen[folder] = true
+			if _, err := os.Stat(folder); err == nil {
+				folders = append(folders, folder)
+			}
+		}
+	}
+	return folders
+}
@@ -0,0 +1,109 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uncharted-distil/distil/api/compute/ta2mock"
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func writeTestFolder(t *testing.T, folder string) {
+	err := os.MkdirAll(folder, os.ModePerm)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(path.Join(folder, "datasetDoc.json"), []byte("{}"), 0644)
+	assert.NoError(t, err)
+}
+
+func TestDeleteDatasetVersion(t *testing.T) {
+	// the paths match the ones of the join test as they can only be set once
+	cfg, err := env.LoadConfig()
+	assert.NoError(t, err)
+	cfg.TmpDataPath = "test_data"
+	cfg.D3MInputDir = "test_data"
+	cfg.DatamartImportFolder = "test_data"
+	env.Initialize(&cfg)
+
+	source := path.Join(env.GetTmpPath(), "delete_test")
+	augmented := path.Join(env.GetTmpPath(), cfg.AugmentedSubFolder, "delete_test")
+	versioned := path.Join(env.GetTmpPath(), "delete_test_v2")
+	writeTestFolder(t, source)
+	writeTestFolder(t, augmented)
+	writeTestFolder(t, versioned)
+	defer os.RemoveAll(source)
+	defer os.RemoveAll(augmented)
+	defer os.RemoveAll(versioned)
+
+	metaStorage := ta2mock.NewMetadataStorage(
+		&api.Dataset{
+			ID:          "delete_test",
+			StorageName: "delete_test",
+			Folder:      "delete_test",
+			Version:     1,
+		},
+		&api.Dataset{
+			ID:          "delete_test_v2",
+			StorageName: "delete_test_v2",
+			Folder:      "delete_test",
+			Version:     2,
+			Parent:      "delete_test",
+			Root:        "delete_test",
+		})
+	dataStorage := ta2mock.NewDataStorage()
+	solutionStorage := ta2mock.NewSolutionStorage()
+	err = solutionStorage.PersistRequest("request", "delete_test_v2", "COMPLETED", time.Now())
+	assert.NoError(t, err)
+
+	// the folders shared with the first version are not listed
+	deletion, err := DeleteDataset("delete_test_v2", metaStorage, dataStorage, solutionStorage, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{versioned}, deletion.Folders)
+	assert.Equal(t, []string{"request"}, deletion.Requests)
+
+	deletion, err = DeleteDataset("delete_test_v2", metaStorage, dataStorage, solutionStorage, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{versioned}, deletion.Folders)
+
+	// the first version remains readable
+	_, err = os.Stat(versioned)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(path.Join(source, "datasetDoc.json"))
+	assert.NoError(t, err)
+	_, err = os.Stat(path.Join(augmented, "datasetDoc.json"))
+	assert.NoError(t, err)
+	_, err = metaStorage.FetchDataset("delete_test", true, true)
+	assert.NoError(t, err)
+	_, err = metaStorage.FetchDataset("delete_test_v2", true, true)
+	assert.Error(t, err)
+	requestIDs, err := solutionStorage.FetchRequestIDsByDataset("delete_test_v2")
+	assert.NoError(t, err)
+	assert.Empty(t, requestIDs)
+
+	// once it is the last version its folders go with it
+	deletion, err = DeleteDataset("delete_test", metaStorage, dataStorage, solutionStorage, false)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{source, augmented}, deletion.Folders)
+	_, err = os.Stat(source)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -16,17 +16,15 @@
 package task
 
 import (
-	"bytes"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"path"
 
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	"github.com/uncharted-distil/distil-ingest/metadata"
-
-	"github.com/uncharted-distil/distil/api/util"
 )
 
 // Format will format a dataset to have the required structures for D3M.
@@ -48,23 +46,16 @@ func Format(datasetSource metadata.DatasetSource, schemaFile string, config *Ing
 		return "", errors.Wrap(err, "unable to copy source data folder")
 	}
 
-	// read the raw data
-	dataPath := path.Join(path.Dir(schemaFile), dr.ResPath)
-	lines, err := ReadCSVFile(dataPath, config.HasHeader)
-	if err != nil {
-		return "", errors.Wrap(err, "error reading raw data")
-	}
-
 	// fix for d3m index requirement
-	if !checkD3MIndexExists(meta) {
-		meta, lines, err = addD3MIndex(schemaFile, meta, lines)
-		if err != nil {
-			return "", errors.Wrap(err, "unable to load original schema file")
-		}
+	addIndex := !checkD3MIndexExists(meta)
+	if addIndex {
+		addD3MIndex(meta)
 	}
 
-	// output the data
-	err = outputDataset(outputPath, meta, lines)
+	// output the data, streaming the raw rows so large files are never held
+	// in memory
+	dataPath := path.Join(path.Dir(schemaFile), dr.ResPath)
+	err = outputDataset(outputPath, meta, dataPath, config.HasHeader, addIndex)
 	if err != nil {
 		return "", errors.Wrap(err, "unable to store formatted dataset")
 	}
@@ -72,12 +63,35 @@ func Format(datasetSource metadata.DatasetSource, schemaFile string, config *Ing
 	return path.Dir(outputPath.outputSchema), nil
 }
 
-func outputDataset(paths *datasetCopyPath, meta *model.Metadata, lines [][]string) error {
+func outputDataset(paths *datasetCopyPath, meta *model.Metadata, dataPath string, hasHeader bool, addIndex bool) error {
 	dr := meta.GetMainDataResource()
 
-	// append the row count as d3m index
+	// open the raw data
+	input, err := os.Open(dataPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to open data file")
+	}
+	defer input.Close()
+	reader := csv.NewReader(input)
+
+	// skip the header as needed
+	if hasHeader {
+		_, err = reader.Read()
+		if err != nil {
+			return errors.Wrap(err, "failed to read header from file")
+		}
+	}
+
 	// initialize csv writer
-	output := &bytes.Buffer{}
+	err = os.MkdirAll(path.Dir(paths.outputData), os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "unable to create output folder")
+	}
+	output, err := os.Create(paths.outputData)
+	if err != nil {
+		return errors.Wrap(err, "unable to create output file")
+	}
+	defer output.Close()
 	writer := csv.NewWriter(output)
 
 	// output the header
@@ -85,17 +99,31 @@ func outputDataset(paths *datasetCopyPath, meta *model.Metadata, lines [][]strin
 	for _, v := range dr.Variables {
 		header[v.Index] = v.Name
 	}
-	err := writer.Write(header)
+	err = writer.Write(header)
 	if err != nil {
 		return errors.Wrap(err, "error storing header")
 	}
 
-	// output the formatted data
-	err = writer.WriteAll(lines)
+	// output the formatted data, appending the row count as d3m index
+	for i := 1; ; i++ {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "failed to read line from file")
+		}
+
+		if addIndex {
+			line = append(line, fmt.Sprintf("%d", i))
+		}
+		err = writer.Write(line)
+		if err != nil {
+			return errors.Wrap(err, "error storing line")
+		}
+	}
 
-	// output the data with the new feature
 	writer.Flush()
-	err = util.WriteFileWithDirs(paths.outputData, output.Bytes(), os.ModePerm)
+	err = writer.Error()
 	if err != nil {
 		return errors.Wrap(err, "error writing output")
 	}
@@ -113,20 +141,13 @@ func outputDataset(paths *datasetCopyPath, meta *model.Metadata, lines [][]strin
 	return nil
 }
 
-func addD3MIndex(schemaFile string, meta *model.Metadata, data [][]string) (*model.Metadata, [][]string, error) {
-	// add the d3m index variable to the metadata
+// addD3MIndex adds the d3m index variable to the metadata. The index values
+// are appended to the rows as they are written out.
+func addD3MIndex(meta *model.Metadata) {
 	dr := meta.DataResources[0]
 	name := model.D3MIndexFieldName
 	v := model.NewVariable(len(dr.Variables), name, name, name, model.IntegerType, model.IntegerType, []string{"index"}, model.VarRoleIndex, nil, dr.Variables, false)
 	dr.Variables = append(dr.Variables, v)
-
-	// parse the raw output and write the line out
-	for i, line := range data {
-		line = append(line, fmt.Sprintf("%d", i+1))
-		data[i] = line
-	}
-
-	return meta, data, nil
 }
 
 func checkD3MIndexExists(meta *model.Metadata) bool {
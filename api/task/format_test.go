@@ -0,0 +1,79 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	"github.com/uncharted-distil/distil/api/env"
+)
+
+func TestFormatLargeDataset(t *testing.T) {
+	// the paths match the ones of the join test as they can only be set once
+	cfg, err := env.LoadConfig()
+	assert.NoError(t, err)
+	cfg.TmpDataPath = "test_data"
+	cfg.D3MInputDir = "test_data"
+	cfg.DatamartImportFolder = "test_data"
+	env.Initialize(&cfg)
+
+	outputPath, err := ioutil.TempDir("", "format")
+	assert.NoError(t, err)
+	defer os.RemoveAll(outputPath)
+	defer os.RemoveAll(path.Join(env.GetTmpPath(), "format_test"))
+
+	// well beyond the csv read buffer, with quoted values spanning lines
+	rows := 5000
+	raw := &bytes.Buffer{}
+	raw.WriteString("name,description\n")
+	for i := 0; i < rows; i++ {
+		raw.WriteString(fmt.Sprintf("row %d,\"first line\nsecond line of row %d\"\n", i, i))
+	}
+	assert.True(t, raw.Len() > 64*1024)
+
+	config := &IngestTaskConfig{
+		HasHeader:                  true,
+		FormatOutputSchemaRelative: cfg.FormatOutputSchemaRelative,
+		FormatOutputDataRelative:   cfg.FormatOutputDataRelative,
+	}
+	_, err = CreateDataset("format_test", raw.Bytes(), outputPath, config)
+	assert.NoError(t, err)
+
+	data, err := os.Open(path.Join(outputPath, "format_test", "tables", "learningData.csv"))
+	assert.NoError(t, err)
+	defer data.Close()
+	lines, err := csv.NewReader(data).ReadAll()
+	assert.NoError(t, err)
+
+	// every row is kept, with the d3m index appended
+	assert.Len(t, lines, rows+1)
+	assert.Equal(t, []string{"name", "description", model.D3MIndexFieldName}, lines[0])
+	assert.Equal(t, []string{"row 0", "first line\nsecond line of row 0", "1"}, lines[1])
+	last := lines[rows]
+	assert.Equal(t, fmt.Sprintf("row %d", rows-1), last[0])
+	assert.True(t, strings.HasSuffix(last[1], fmt.Sprintf("row %d", rows-1)))
+	assert.Equal(t, fmt.Sprintf("%d", rows), last[2])
+}
@@ -16,11 +16,8 @@
 package task
 
 import (
-	"bufio"
-	"context"
 	"fmt"
 	"net/http"
-	"os"
 	"path"
 	"time"
 
@@ -34,6 +31,8 @@ import (
 
 	"github.com/uncharted-distil/distil/api/env"
 	api "github.com/uncharted-distil/distil/api/model"
+	pgstorage "github.com/uncharted-distil/distil/api/model/storage/postgres"
+	pgclient "github.com/uncharted-distil/distil/api/postgres"
 )
 
 const (
@@ -75,11 +74,20 @@ type IngestTaskConfig struct {
 	ESEndpoint                         string
 	ESTimeout                          int
 	ESDatasetPrefix                    string
+	MetadataStorage                    string
 	HardFail                           bool
+	ResumeEnabled                      bool
+	RestageFrom                        string
+	MaxArchiveSize                     int64
+	MaxArchiveEntries                  int
 }
 
 // IngestDataset executes the complete ingest process for the specified dataset.
 func IngestDataset(datasetSource metadata.DatasetSource, metaCtor api.MetadataStorageCtor, index string, dataset string, config *IngestTaskConfig) error {
+	return ingestDataset(nil, datasetSource, metaCtor, index, dataset, config)
+}
+
+func ingestDataset(job *IngestJob, datasetSource metadata.DatasetSource, metaCtor api.MetadataStorageCtor, index string, dataset string, config *IngestTaskConfig) error {
 	// Set the probability threshold
 	metadata.SetTypeProbabilityThreshold(config.ClassificationProbabilityThreshold)
 
@@ -91,81 +99,27 @@ func IngestDataset(datasetSource metadata.DatasetSource, metaCtor api.MetadataSt
 	sourceFolder := env.ResolvePath(datasetSource, dataset)
 
 	originalSchemaFile := path.Join(sourceFolder, config.SchemaPathRelative)
-	latestSchemaOutput := originalSchemaFile
 
-	output, err := Merge(datasetSource, latestSchemaOutput, index, dataset, config)
-	if err != nil {
-		return errors.Wrap(err, "unable to merge all data into a single file")
-	}
-	latestSchemaOutput = output
-	log.Infof("finished merging the dataset")
+	unlock := lockIngestDataset(dataset)
+	defer unlock()
 
-	output, err = Clean(datasetSource, latestSchemaOutput, index, dataset, config)
-	if err != nil {
-		return errors.Wrap(err, "unable to clean all data")
+	manifest := loadIngestManifest(dataset, config)
+	ctx := &IngestStageContext{
+		Source:  datasetSource,
+		Index:   index,
+		Dataset: dataset,
+		Config:  config,
 	}
-	latestSchemaOutput = output
-	log.Infof("finished cleaning the dataset")
 
-	if config.ClusteringEnabled {
-		output, err = Cluster(datasetSource, latestSchemaOutput, index, dataset, config)
-		if err != nil {
-			if config.HardFail {
-				return errors.Wrap(err, "unable to cluster all data")
-			}
-			log.Errorf("unable to cluster all data: %v", err)
-		} else {
-			latestSchemaOutput = output
-		}
-		log.Infof("finished clustering the dataset")
-	}
-
-	output, err = Featurize(datasetSource, latestSchemaOutput, index, dataset, config)
+	latestSchemaOutput, err := runIngestStages(job, ctx, manifest, originalSchemaFile)
 	if err != nil {
-		if config.HardFail {
-			return errors.Wrap(err, "unable to featurize all data")
-		}
-		log.Errorf("unable to featurize all data: %v", err)
-	} else {
-		latestSchemaOutput = output
-	}
-	log.Infof("finished featurizing the dataset")
-
-	err = Classify(latestSchemaOutput, index, dataset, config)
-	if err != nil {
-		return errors.Wrap(err, "unable to classify fields")
-	}
-	log.Infof("finished classifying the dataset")
-
-	err = Rank(latestSchemaOutput, index, dataset, config)
-	if err != nil {
-		return errors.Wrap(err, "unable to rank field importance")
-	}
-	log.Infof("finished ranking the dataset")
-
-	if config.SummaryEnabled {
-		err = Summarize(latestSchemaOutput, index, dataset, config)
-		log.Infof("finished summarizing the dataset")
-		if err != nil {
-			if config.HardFail {
-				return errors.Wrap(err, "unable to summarize the dataset")
-			}
-			log.Errorf("unable to summarize the dataset: %v", err)
-		}
-	} else {
-		log.Infof("summarization disabled")
-	}
-
-	if config.GeocodingEnabled {
-		output, err = GeocodeForwardDataset(datasetSource, latestSchemaOutput, index, dataset, config)
-		if err != nil {
-			return errors.Wrap(err, "unable to geocode all data")
-		}
-		latestSchemaOutput = output
-		log.Infof("finished geocoding the dataset")
+		return err
 	}
 
-	err = Ingest(originalSchemaFile, latestSchemaOutput, storage, index, dataset, datasetSource, config)
+	// the ingest stage writes to external stores so always runs
+	err = job.runStage(StoreStage, func() error {
+		return Ingest(originalSchemaFile, latestSchemaOutput, storage, index, dataset, datasetSource, config)
+	})
 	if err != nil {
 		return errors.Wrap(err, "unable to ingest ranked data")
 	}
@@ -208,17 +162,6 @@ func Ingest(originalSchemaFile string, schemaFile string, storage api.MetadataSt
 		log.Errorf("unable to load machine summary: %v", err)
 	}
 
-	// create elasticsearch client
-	elasticClient, err := elastic.NewClient(
-		elastic.SetURL(config.ESEndpoint),
-		elastic.SetHttpClient(&http.Client{Timeout: time.Second * time.Duration(config.ESTimeout)}),
-		elastic.SetMaxRetries(10),
-		elastic.SetSniff(false),
-		elastic.SetGzip(true))
-	if err != nil {
-		return errors.Wrap(err, "unable to initialize elastic client")
-	}
-
 	// Connect to the database.
 	postgresConfig := &conf.Conf{
 		DBPassword:  config.DatabasePassword,
@@ -233,29 +176,15 @@ func Ingest(originalSchemaFile string, schemaFile string, storage api.MetadataSt
 		return errors.Wrap(err, "unable to initialize a new database")
 	}
 
-	// Check for existing dataset
-	match, err := matchDataset(storage, meta, index)
+	// Check for an existing version of the dataset
+	match, err := matchDataset(storage, meta, config)
 	// Ignore the error for now as if this fails we still want ingest to succeed.
 	if err != nil {
 		log.Error(err)
 	}
-	if match != "" {
-		log.Infof("Matched %s to dataset %s", meta.Name, match)
-		err = deleteDataset(match, index, pg, elasticClient)
-		log.Infof("Deleted dataset %s", match)
-	}
-
-	// ingest the metadata
-	// Create the metadata index if it doesn't exist
-	err = metadata.CreateMetadataIndex(elasticClient, index, false)
-	if err != nil {
-		return errors.Wrap(err, "unable to create metadata index")
-	}
-
-	// Ingest the dataset info into the metadata index
-	err = metadata.IngestMetadata(elasticClient, index, config.ESDatasetPrefix, source, meta)
-	if err != nil {
-		return errors.Wrap(err, "unable to ingest metadata")
+	version := newDatasetVersion(meta, match)
+	if match != nil {
+		log.Infof("Matched %s to dataset %s, ingesting as version %d (%s)", meta.Name, match.ID, version.Version, meta.ID)
 	}
 
 	dbTable := meta.StorageName
@@ -286,87 +215,138 @@ func Ingest(originalSchemaFile string, schemaFile string, storage api.MetadataSt
 		return errors.Wrap(err, "unable to create the result table")
 	}
 
-	err = pg.CreateSolutionMetadataTables()
+	client, err := pgclient.NewClient(config.DatabaseHost, config.DatabasePort, config.DatabaseUser, config.DatabasePassword, config.Database, "")()
 	if err != nil {
-		return errors.Wrap(err, "unable to create solution metadata tables")
+		return errors.Wrap(err, "unable to initialize postgres client")
+	}
+
+	err = pgstorage.MigrateSolutionTables(client)
+	if err != nil {
+		return errors.Wrap(err, "unable to migrate solution metadata tables")
 	}
 
 	// Load the data.
-	log.Infof("inserting rows into database based on data found in %s", dataDir)
-	reader, err := os.Open(dataDir)
-	scanner := bufio.NewScanner(reader)
 
-	// skip header
-	scanner.Scan()
-	count := 0
-	for scanner.Scan() {
-		line := scanner.Text()
+	log.Infof("bulk loading rows into database based on data found in %s", dataDir)
+	count, err := bulkLoadData(client, fmt.Sprintf("%s%s", dbTable, baseTableSuffix), meta.DataResources[0], dataDir)
+	if err != nil {
+		return err
+	}
+	log.Infof("loaded %d rows", count)
+
+	err = addWordStems(client, dataDir)
+	if err != nil {
+		log.Warnf("%v", err)
+	}
 
-		err = pg.AddWordStems(line)
+	// verify everything made it into the database
+	if count != int64(meta.NumRows) {
+		return errors.Errorf("loaded %d rows but dataset stats report %d rows", count, meta.NumRows)
+	}
+
+	// ingest the metadata last so the dataset is only listed once its data is
+	// fully loaded
+	err = storeMetadata(meta, source, version, index, config)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("all data ingested")
+
+	return nil
+}
+
+// storeMetadata writes the dataset metadata to the configured metadata
+// storage, creating the index or tables as needed.
+func storeMetadata(meta *model.Metadata, source metadata.DatasetSource, version *datasetVersion, index string, config *IngestTaskConfig) error {
+	if config.MetadataStorage == pgstorage.Provenance {
+		client, err := pgclient.NewClient(config.DatabaseHost, config.DatabasePort, config.DatabaseUser, config.DatabasePassword, config.Database, "")()
 		if err != nil {
-			log.Warn(fmt.Sprintf("%v", err))
+			return errors.Wrap(err, "unable to initialize postgres client")
 		}
+		storage := pgstorage.NewMetadataStorageFromClient(client)
 
-		err = pg.IngestRow(dbTable, line)
+		err = storage.CreateMetadataTables()
 		if err != nil {
-			return errors.Wrap(err, "unable to ingest row")
+			return err
 		}
 
-		count = count + 1
-		if count%10000 == 0 {
-			log.Infof("inserted %d rows so far", count)
+		// merge all variables into a single set as done by the ES ingest
+		variables := make([]*model.Variable, 0)
+		for _, dr := range meta.DataResources {
+			variables = append(variables, dr.Variables...)
 		}
+		return storage.IngestDataset(&api.Dataset{
+			ID:          meta.ID,
+			Name:        meta.Name,
+			StorageName: meta.StorageName,
+			Description: meta.Description,
+			Folder:      meta.DatasetFolder,
+			Summary:     meta.Summary,
+			SummaryML:   meta.SummaryMachine,
+			NumRows:     int64(meta.NumRows),
+			NumBytes:    int64(meta.NumBytes),
+			Variables:   variables,
+			Source:      source,
+			Version:     version.Version,
+			Parent:      version.Parent,
+			Root:        version.Root,
+		})
 	}
 
-	log.Infof("ingesting final rows")
-	err = pg.InsertRemainingRows()
+	// create elasticsearch client
+	elasticClient, err := elastic.NewClient(
+		elastic.SetURL(config.ESEndpoint),
+		elastic.SetHttpClient(&http.Client{Timeout: time.Second * time.Duration(config.ESTimeout)}),
+		elastic.SetMaxRetries(10),
+		elastic.SetSniff(false),
+		elastic.SetGzip(true))
 	if err != nil {
-		return errors.Wrap(err, "unable to ingest last rows")
+		return errors.Wrap(err, "unable to initialize elastic client")
 	}
 
-	log.Infof("all data ingested")
+	// Create the metadata index if it doesn't exist
+	err = metadata.CreateMetadataIndex(elasticClient, index, false)
+	if err != nil {
+		return errors.Wrap(err, "unable to create metadata index")
+	}
 
-	return nil
+	// Ingest the dataset info into the metadata index
+	err = metadata.IngestMetadata(elasticClient, index, config.ESDatasetPrefix, source, meta)
+	if err != nil {
+		return errors.Wrap(err, "unable to ingest metadata")
+	}
+
+	return storeDatasetVersion(elasticClient, index, metadataDocumentID(meta.ID, config), version)
 }
 
-func matchDataset(storage api.MetadataStorage, meta *model.Metadata, index string) (string, error) {
+func matchDataset(storage api.MetadataStorage, meta *model.Metadata, config *IngestTaskConfig) (*api.Dataset, error) {
 	// load the datasets from ES.
 	datasets, err := storage.FetchDatasets(true, true)
 	if err != nil {
-		return "", errors.Wrap(err, "unable to fetch datasets for matching")
+		return nil, errors.Wrap(err, "unable to fetch datasets for matching")
 	}
 
-	// See if any of the loaded datasets match.
+	// See if any of the loaded datasets match, keeping the latest version.
+	// Only the versions of the same source dataset can match, as unrelated
+	// datasets can have the same columns. The first version of a dataset
+	// without lineage is identified by its stored id.
+	var match *api.Dataset
 	for _, dataset := range datasets {
+		root := dataset.GetRoot()
+		if root != meta.ID && root != metadataDocumentID(meta.ID, config) {
+			continue
+		}
 		variables := make([]string, 0)
 		for _, v := range dataset.Variables {
 			variables = append(variables, v.Name)
 		}
 		if metadata.DatasetMatches(meta, variables) {
-			// Return the name of the matching set.
-			return dataset.Name, nil
-		}
-	}
-
-	// No matching set.
-	return "", nil
-}
-
-func deleteDataset(name string, index string, pg *postgres.Database, es *elastic.Client) error {
-	id := name
-	success := false
-	for i := 0; i < 10 && !success; i++ {
-		_, err := es.Delete().Index(index).Id(id).Type("metadata").Do(context.Background())
-		if err != nil {
-			log.Error(err)
-		} else {
-			success = true
+			if match == nil || dataset.Version > match.Version {
+				match = dataset
+			}
 		}
 	}
 
-	if success {
-		pg.DeleteDataset(name)
-	}
-
-	return nil
+	return match, nil
 }
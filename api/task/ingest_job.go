@@ -0,0 +1,483 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/uncharted-distil/distil-ingest/metadata"
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+const (
+	// IngestPendingStatus represents that the ingest job has been created but not yet started.
+	IngestPendingStatus = "INGEST_PENDING"
+	// IngestRunningStatus represents that the ingest job is executing its stages.
+	IngestRunningStatus = "INGEST_RUNNING"
+	// IngestErroredStatus represents that the ingest job has terminated with an error.
+	IngestErroredStatus = "INGEST_ERRORED"
+	// IngestCancelledStatus represents that the ingest job was cancelled before completing.
+	IngestCancelledStatus = "INGEST_CANCELLED"
+	// IngestCompletedStatus represents that the ingest job has completed successfully.
+	IngestCompletedStatus = "INGEST_COMPLETED"
+
+	// StagePendingStatus represents that the stage has not yet started.
+	StagePendingStatus = "STAGE_PENDING"
+	// StageRunningStatus represents that the stage is executing.
+	StageRunningStatus = "STAGE_RUNNING"
+	// StageErroredStatus represents that the stage has terminated with an error.
+	StageErroredStatus = "STAGE_ERRORED"
+	// StageCompletedStatus represents that the stage has completed successfully.
+	StageCompletedStatus = "STAGE_COMPLETED"
+	// StageReusedStatus represents that the stage output from a previous run was reused.
+	StageReusedStatus = "STAGE_REUSED"
+
+	// MergeStage merges all data resources into a single resource.
+	MergeStage = "merge"
+	// CleanStage cleans bad data values.
+	CleanStage = "clean"
+	// ClusterStage clusters the data.
+	ClusterStage = "cluster"
+	// FeaturizeStage featurizes referenced resources.
+	FeaturizeStage = "featurize"
+	// ClassifyStage classifies the variable types.
+	ClassifyStage = "classify"
+	// RankStage ranks the variable importance.
+	RankStage = "rank"
+	// SummarizeStage summarizes the dataset.
+	SummarizeStage = "summarize"
+	// GeocodeStage geocodes place names.
+	GeocodeStage = "geocode"
+	// StoreStage stores the metadata and data.
+	StoreStage = "ingest"
+)
+
+var (
+	ingestJobs   = make(map[string]*IngestJob)
+	ingestJobsMu = &sync.Mutex{}
+
+	// ingestJobTTL is how long a finished job remains available for status
+	// requests and retries before being evicted.
+	ingestJobTTL = time.Hour
+
+	errIngestCancelled = errors.New("ingest job cancelled")
+)
+
+// IngestStageStatus represents the status and timing of a single ingest stage.
+type IngestStageStatus struct {
+	Name      string    `json:"name"`
+	Progress  string    `json:"progress"`
+	Error     string    `json:"error"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Duration  float64   `json:"duration"`
+}
+
+// IngestJobStatus represents the status of an ingest job.
+type IngestJobStatus struct {
+	JobID           string              `json:"jobId"`
+	Dataset         string              `json:"dataset"`
+	Progress        string              `json:"progress"`
+	Stage           string              `json:"stage"`
+	Stages          []IngestStageStatus `json:"stages"`
+	Error           string              `json:"error"`
+	CreatedTime     time.Time           `json:"timestamp"`
+	LastUpdatedTime time.Time           `json:"lastUpdatedTime"`
+}
+
+// IngestStatusListener executes on a new ingest job status.
+type IngestStatusListener func(status IngestJobStatus)
+
+// ingestStatusQueue buffers the statuses of a single listener, preserving
+// the order in which they were made.
+type ingestStatusQueue struct {
+	mu       *sync.Mutex
+	statuses []IngestJobStatus
+	ready    chan struct{}
+}
+
+func newIngestStatusQueue() *ingestStatusQueue {
+	return &ingestStatusQueue{
+		mu:    &sync.Mutex{},
+		ready: make(chan struct{}, 1),
+	}
+}
+
+func (q *ingestStatusQueue) push(status IngestJobStatus) {
+	q.mu.Lock()
+	q.statuses = append(q.statuses, status)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *ingestStatusQueue) drain(listener IngestStatusListener) {
+	q.mu.Lock()
+	statuses := q.statuses
+	q.statuses = nil
+	q.mu.Unlock()
+
+	for _, status := range statuses {
+		listener(status)
+	}
+}
+
+// IngestJob tracks the asynchronous execution of a dataset ingest.
+type IngestJob struct {
+	ID           string
+	Dataset      string
+	mu           *sync.Mutex
+	status       IngestJobStatus
+	listeners    map[int]*ingestStatusQueue
+	nextListener int
+	cancelled    bool
+	err          error
+	finished     chan struct{}
+	finishedTime time.Time
+	resubmit     func(restageFrom string) *IngestJob
+}
+
+func newIngestJob(ctx *IngestStageContext) *IngestJob {
+	now := time.Now()
+	id := uuid.NewV4().String()
+
+	stages := make([]IngestStageStatus, 0)
+	for _, name := range ingestStageNames(ctx) {
+		stages = append(stages, IngestStageStatus{
+			Name:     name,
+			Progress: StagePendingStatus,
+		})
+	}
+
+	return &IngestJob{
+		ID:        id,
+		Dataset:   ctx.Dataset,
+		mu:        &sync.Mutex{},
+		listeners: make(map[int]*ingestStatusQueue),
+		finished:  make(chan struct{}),
+		status: IngestJobStatus{
+			JobID:           id,
+			Dataset:         ctx.Dataset,
+			Progress:        IngestPendingStatus,
+			Stages:          stages,
+			CreatedTime:     now,
+			LastUpdatedTime: now,
+		},
+	}
+}
+
+func ingestStageNames(ctx *IngestStageContext) []string {
+	names := make([]string, 0)
+	for _, r := range enabledIngestStages(ctx) {
+		names = append(names, r.stage.Name())
+	}
+	return append(names, StoreStage)
+}
+
+// SubmitIngestJob creates an ingest job for the dataset and starts executing
+// it in the background, returning immediately.
+func SubmitIngestJob(datasetSource metadata.DatasetSource, metaCtor api.MetadataStorageCtor, index string, dataset string, config *IngestTaskConfig) *IngestJob {
+	job := newIngestJob(&IngestStageContext{
+		Source:  datasetSource,
+		Index:   index,
+		Dataset: dataset,
+		Config:  config,
+	})
+
+	job.resubmit = func(restageFrom string) *IngestJob {
+		retryConfig := *config
+		retryConfig.ResumeEnabled = true
+		retryConfig.RestageFrom = restageFrom
+		return SubmitIngestJob(datasetSource, metaCtor, index, dataset, &retryConfig)
+	}
+
+	startIngestJob(job, func() error {
+		return ingestDataset(job, datasetSource, metaCtor, index, dataset, config)
+	})
+	log.Infof("submitted ingest job %s for dataset '%s'", job.ID, dataset)
+
+	return job
+}
+
+// startIngestJob registers the job and runs the ingest in the background.
+func startIngestJob(job *IngestJob, ingest func() error) {
+	ingestJobsMu.Lock()
+	evictIngestJobs(time.Now())
+	ingestJobs[job.ID] = job
+	ingestJobsMu.Unlock()
+
+	go job.run(ingest)
+}
+
+// evictIngestJobs removes the jobs that finished more than the TTL before
+// now. It must be called with the jobs locked.
+func evictIngestJobs(now time.Time) {
+	for id, job := range ingestJobs {
+		job.mu.Lock()
+		expired := !job.finishedTime.IsZero() && now.Sub(job.finishedTime) > ingestJobTTL
+		job.mu.Unlock()
+		if expired {
+			delete(ingestJobs, id)
+		}
+	}
+}
+
+// FetchIngestJob returns the ingest job with the given id.
+func FetchIngestJob(jobID string) (*IngestJob, error) {
+	ingestJobsMu.Lock()
+	defer ingestJobsMu.Unlock()
+
+	evictIngestJobs(time.Now())
+	job, ok := ingestJobs[jobID]
+	if !ok {
+		return nil, errors.Errorf("ingest job `%s` not found", jobID)
+	}
+	return job, nil
+}
+
+// CancelIngestJob flags the ingest job for cancellation. The job stops before
+// starting its next stage.
+func CancelIngestJob(jobID string) error {
+	job, err := FetchIngestJob(jobID)
+	if err != nil {
+		return err
+	}
+	return job.Cancel()
+}
+
+// RetryIngestJob submits a new ingest job for the same dataset as a finished
+// job. Stages completed by the previous run are reused unless they are stale,
+// or at or after the optional restage stage.
+func RetryIngestJob(jobID string, restageFrom string) (*IngestJob, error) {
+	job, err := FetchIngestJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := job.Status()
+	if !isTerminalIngestStatus(status.Progress) {
+		return nil, errors.Errorf("ingest job `%s` is still running", jobID)
+	}
+	if restageFrom != "" {
+		err = job.ValidateRestageStage(restageFrom)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return job.resubmit(restageFrom), nil
+}
+
+// ValidateRestageStage returns an error if the stage is not one of the
+// stages the job ran through the manifest. The store stage always runs, so
+// it can not be restaged from.
+func (j *IngestJob) ValidateRestageStage(stage string) error {
+	status := j.Status()
+	if stage == StoreStage || status.stageIndex(stage) < 0 {
+		return errors.Errorf("ingest job `%s` has no stage `%s` to restage from", j.ID, stage)
+	}
+	return nil
+}
+
+// Status returns a snapshot of the current job status.
+func (j *IngestJob) Status() IngestJobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.snapshot()
+}
+
+// Cancel flags the job for cancellation.
+func (j *IngestJob) Cancel() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if isTerminalIngestStatus(j.status.Progress) {
+		return errors.Errorf("ingest job `%s` already finished with status %s", j.ID, j.status.Progress)
+	}
+	j.cancelled = true
+	return nil
+}
+
+// Wait blocks until the job finishes and returns the job error, if any.
+func (j *IngestJob) Wait() error {
+	<-j.finished
+	return j.err
+}
+
+// Listen sends the current status and every subsequent status update to the
+// listener, in order, returning once the job has finished. The listener is
+// removed early, returning an error, if the done channel is closed first.
+func (j *IngestJob) Listen(listener IngestStatusListener, done <-chan struct{}) error {
+	// the initial status is queued under the lock so no update can overtake it
+	queue := newIngestStatusQueue()
+	j.mu.Lock()
+	id := j.nextListener
+	j.nextListener++
+	j.listeners[id] = queue
+	queue.push(j.snapshot())
+	j.mu.Unlock()
+
+	defer func() {
+		j.mu.Lock()
+		delete(j.listeners, id)
+		j.mu.Unlock()
+	}()
+
+	for {
+		queue.drain(listener)
+		select {
+		case <-queue.ready:
+		case <-j.finished:
+			// the final status is queued before the job is flagged finished
+			queue.drain(listener)
+			return nil
+		case <-done:
+			return errors.Errorf("stopped listening to ingest job `%s`", j.ID)
+		}
+	}
+}
+
+func (j *IngestJob) run(ingest func() error) {
+	j.update(func(status *IngestJobStatus) {
+		status.Progress = IngestRunningStatus
+	})
+
+	err := ingest()
+	j.err = err
+
+	j.update(func(status *IngestJobStatus) {
+		j.finishedTime = time.Now()
+		status.Stage = ""
+		if errors.Cause(err) == errIngestCancelled {
+			status.Progress = IngestCancelledStatus
+			status.Error = err.Error()
+		} else if err != nil {
+			status.Progress = IngestErroredStatus
+			status.Error = err.Error()
+		} else {
+			status.Progress = IngestCompletedStatus
+		}
+	})
+	close(j.finished)
+
+	if err != nil {
+		log.Errorf("ingest job %s for dataset '%s' failed: %+v", j.ID, j.Dataset, err)
+	} else {
+		log.Infof("ingest job %s for dataset '%s' completed", j.ID, j.Dataset)
+	}
+}
+
+// runStage executes a single ingest stage, recording its status and timing.
+// A nil job runs the stage without tracking.
+func (j *IngestJob) runStage(name string, stage func() error) error {
+	if j == nil {
+		return stage()
+	}
+
+	j.mu.Lock()
+	cancelled := j.cancelled
+	j.mu.Unlock()
+	if cancelled {
+		return errIngestCancelled
+	}
+
+	start := time.Now()
+	j.update(func(status *IngestJobStatus) {
+		status.Stage = name
+		s := status.stage(name)
+		s.Progress = StageRunningStatus
+		s.StartTime = start
+	})
+
+	err := stage()
+
+	end := time.Now()
+	j.update(func(status *IngestJobStatus) {
+		s := status.stage(name)
+		s.EndTime = end
+		s.Duration = end.Sub(start).Seconds()
+		if err != nil {
+			s.Progress = StageErroredStatus
+			s.Error = err.Error()
+		} else {
+			s.Progress = StageCompletedStatus
+		}
+	})
+
+	return err
+}
+
+// reuseStage flags the stage as having reused the output of a previous run.
+func (j *IngestJob) reuseStage(name string) {
+	if j == nil {
+		return
+	}
+
+	j.update(func(status *IngestJobStatus) {
+		status.stage(name).Progress = StageReusedStatus
+	})
+}
+
+// update applies the change to the status and queues the result for every
+// listener.
+func (j *IngestJob) update(apply func(status *IngestJobStatus)) {
+	j.mu.Lock()
+	apply(&j.status)
+	j.status.LastUpdatedTime = time.Now()
+	status := j.snapshot()
+	for _, queue := range j.listeners {
+		queue.push(status)
+	}
+	j.mu.Unlock()
+}
+
+func (j *IngestJob) snapshot() IngestJobStatus {
+	status := j.status
+	status.Stages = make([]IngestStageStatus, len(j.status.Stages))
+	copy(status.Stages, j.status.Stages)
+	return status
+}
+
+func (s *IngestJobStatus) stageIndex(name string) int {
+	for i := range s.Stages {
+		if s.Stages[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *IngestJobStatus) stage(name string) *IngestStageStatus {
+	if i := s.stageIndex(name); i >= 0 {
+		return &s.Stages[i]
+	}
+	s.Stages = append(s.Stages, IngestStageStatus{
+		Name:     name,
+		Progress: StagePendingStatus,
+	})
+	return &s.Stages[len(s.Stages)-1]
+}
+
+func isTerminalIngestStatus(progress string) bool {
+	return progress == IngestCompletedStatus || progress == IngestErroredStatus || progress == IngestCancelledStatus
+}
@@ -0,0 +1,191 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIngestJob() *IngestJob {
+	return newIngestJob(&IngestStageContext{
+		Dataset: "ingest_job_test",
+		Config:  &IngestTaskConfig{},
+	})
+}
+
+func TestIngestJobCompleted(t *testing.T) {
+	job := newTestIngestJob()
+	startIngestJob(job, func() error {
+		return job.runStage("first", func() error { return nil })
+	})
+	assert.NoError(t, job.Wait())
+
+	fetched, err := FetchIngestJob(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, job, fetched)
+
+	status := job.Status()
+	assert.Equal(t, IngestCompletedStatus, status.Progress)
+	assert.Equal(t, StageCompletedStatus, status.stage("first").Progress)
+
+	// a finished job can not be cancelled
+	assert.Error(t, CancelIngestJob(job.ID))
+	assert.Error(t, CancelIngestJob("unknown"))
+}
+
+func TestIngestJobCancel(t *testing.T) {
+	job := newTestIngestJob()
+	started := make(chan struct{})
+	resume := make(chan struct{})
+	secondRan := false
+	startIngestJob(job, func() error {
+		err := job.runStage("first", func() error {
+			close(started)
+			<-resume
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return job.runStage("second", func() error {
+			secondRan = true
+			return nil
+		})
+	})
+
+	// the job stops before starting its next stage
+	<-started
+	assert.NoError(t, CancelIngestJob(job.ID))
+	close(resume)
+	err := job.Wait()
+	assert.Equal(t, errIngestCancelled, errors.Cause(err))
+	assert.False(t, secondRan)
+
+	status := job.Status()
+	assert.Equal(t, IngestCancelledStatus, status.Progress)
+	assert.Equal(t, StageCompletedStatus, status.stage("first").Progress)
+	assert.Equal(t, -1, status.stageIndex("second"))
+}
+
+func TestIngestJobListen(t *testing.T) {
+	job := newTestIngestJob()
+	resume := make(chan struct{})
+	startIngestJob(job, func() error {
+		<-resume
+		return nil
+	})
+
+	// a listener stops receiving updates once it is done
+	statuses := make(chan IngestJobStatus, 10)
+	done := make(chan struct{})
+	listened := make(chan error)
+	go func() {
+		listened <- job.Listen(func(status IngestJobStatus) {
+			statuses <- status
+		}, done)
+	}()
+	<-statuses
+	close(done)
+	assert.Error(t, <-listened)
+	job.mu.Lock()
+	assert.Len(t, job.listeners, 0)
+	job.mu.Unlock()
+
+	// a listener receives the updates until the job finishes
+	final := make(chan IngestJobStatus, 10)
+	go func() {
+		listened <- job.Listen(func(status IngestJobStatus) {
+			final <- status
+		}, nil)
+	}()
+	<-final
+	close(resume)
+	assert.NoError(t, <-listened)
+	assert.NoError(t, job.Wait())
+	job.mu.Lock()
+	assert.Len(t, job.listeners, 0)
+	job.mu.Unlock()
+
+	var last IngestJobStatus
+	for len(final) > 0 {
+		last = <-final
+	}
+	assert.Equal(t, IngestCompletedStatus, last.Progress)
+}
+
+func TestIngestJobListenOrder(t *testing.T) {
+	job := newTestIngestJob()
+	resume := make(chan struct{})
+	startIngestJob(job, func() error {
+		<-resume
+		for i := 0; i < 100; i++ {
+			job.update(func(status *IngestJobStatus) {})
+		}
+		return nil
+	})
+
+	// the updates race the listeners subscribing
+	listeners := 10
+	listened := make(chan []IngestJobStatus)
+	for i := 0; i < listeners; i++ {
+		go func() {
+			statuses := make([]IngestJobStatus, 0)
+			err := job.Listen(func(status IngestJobStatus) {
+				statuses = append(statuses, status)
+			}, nil)
+			assert.NoError(t, err)
+			listened <- statuses
+		}()
+	}
+	close(resume)
+
+	// every listener receives the statuses in the order they were made
+	for i := 0; i < listeners; i++ {
+		statuses := <-listened
+		for j := 1; j < len(statuses); j++ {
+			assert.False(t, statuses[j].LastUpdatedTime.Before(statuses[j-1].LastUpdatedTime))
+		}
+		assert.Equal(t, IngestCompletedStatus, statuses[len(statuses)-1].Progress)
+	}
+}
+
+func TestIngestJobEviction(t *testing.T) {
+	finished := newTestIngestJob()
+	startIngestJob(finished, func() error { return nil })
+	assert.NoError(t, finished.Wait())
+
+	running := newTestIngestJob()
+	resume := make(chan struct{})
+	startIngestJob(running, func() error {
+		<-resume
+		return nil
+	})
+	defer close(resume)
+
+	// only finished jobs are evicted once past the ttl
+	finished.mu.Lock()
+	finished.finishedTime = time.Now().Add(-2 * ingestJobTTL)
+	finished.mu.Unlock()
+
+	_, err := FetchIngestJob(finished.ID)
+	assert.Error(t, err)
+	_, err = FetchIngestJob(running.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,281 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-ingest/metadata"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil/api/env"
+	"github.com/uncharted-distil/distil/api/util"
+)
+
+const (
+	ingestManifestFilename = "ingest-manifest.json"
+)
+
+var (
+	ingestDatasetLocks   = make(map[string]*ingestDatasetLock)
+	ingestDatasetLocksMu = &sync.Mutex{}
+)
+
+type ingestDatasetLock struct {
+	mu      *sync.Mutex
+	holders int
+}
+
+// lockIngestDataset blocks until no other ingest of the dataset is running,
+// as ingests of a dataset share its manifest and working folder. The returned
+// function releases the lock.
+func lockIngestDataset(dataset string) func() {
+	ingestDatasetLocksMu.Lock()
+	lock, ok := ingestDatasetLocks[dataset]
+	if !ok {
+		lock = &ingestDatasetLock{
+			mu: &sync.Mutex{},
+		}
+		ingestDatasetLocks[dataset] = lock
+	}
+	lock.holders++
+	ingestDatasetLocksMu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		ingestDatasetLocksMu.Lock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(ingestDatasetLocks, dataset)
+		}
+		ingestDatasetLocksMu.Unlock()
+	}
+}
+
+// ValidateRestageStage returns an error if the stage is not one of the
+// enabled stages of the ingest chain. The store stage always runs, so it can
+// not be restaged from.
+func ValidateRestageStage(stage string, config *IngestTaskConfig) error {
+	for _, r := range enabledIngestStages(&IngestStageContext{Config: config}) {
+		if r.stage.Name() == stage {
+			return nil
+		}
+	}
+	return errors.Errorf("ingest has no stage `%s` to restage from", stage)
+}
+
+// ingestManifestEntry records a completed stage along with the hash of the
+// input it ran over and the outputs it produced.
+type ingestManifestEntry struct {
+	Stage         string    `json:"stage"`
+	InputHash     string    `json:"inputHash"`
+	Output        string    `json:"output"`
+	Artifacts     []string  `json:"artifacts"`
+	CompletedTime time.Time `json:"completedTime"`
+}
+
+// ingestManifest tracks the completed stages of a dataset ingest so that a
+// retry can reuse their outputs rather than rerunning them.
+type ingestManifest struct {
+	Dataset string                          `json:"dataset"`
+	Stages  map[string]*ingestManifestEntry `json:"stages"`
+
+	filename    string
+	restageFrom string
+	stale       bool
+}
+
+func loadIngestManifest(dataset string, config *IngestTaskConfig) *ingestManifest {
+	return loadIngestManifestFile(path.Join(env.GetTmpPath(), dataset, ingestManifestFilename), dataset, config)
+}
+
+func loadIngestManifestFile(filename string, dataset string, config *IngestTaskConfig) *ingestManifest {
+	manifest := &ingestManifest{
+		Dataset:     dataset,
+		Stages:      make(map[string]*ingestManifestEntry),
+		filename:    filename,
+		restageFrom: config.RestageFrom,
+		stale:       !config.ResumeEnabled,
+	}
+
+	if !config.ResumeEnabled {
+		return manifest
+	}
+
+	data, err := ioutil.ReadFile(manifest.filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("unable to read ingest manifest %s: %v", manifest.filename, err)
+		}
+		return manifest
+	}
+
+	err = json.Unmarshal(data, manifest)
+	if err != nil || manifest.Stages == nil {
+		log.Warnf("ignoring unreadable ingest manifest %s: %v", manifest.filename, err)
+		manifest.Stages = make(map[string]*ingestManifestEntry)
+	}
+
+	return manifest
+}
+
+// runStage executes the stage unless the manifest records a completed run
+// over an identical input, in which case the recorded output is reused. Once
+// a stage runs, every subsequent stage is considered stale and runs as well.
+func (m *ingestManifest) runStage(job *IngestJob, name string, input string, artifacts []string, stage func() (string, error)) (string, error) {
+	if name == m.restageFrom {
+		m.stale = true
+	}
+
+	hash, err := hashStageInput(name, input)
+	if err != nil {
+		log.Warnf("unable to hash input of %s stage: %v", name, err)
+	}
+
+	if !m.stale && hash != "" {
+		entry, ok := m.Stages[name]
+		if ok && entry.InputHash == hash && filesExist(append(entry.Artifacts, entry.Output)) {
+			log.Infof("reusing output of completed %s stage from %s", name, entry.Output)
+			job.reuseStage(name)
+			return entry.Output, nil
+		}
+	}
+
+	// this stage and everything after it needs to run
+	m.stale = true
+	delete(m.Stages, name)
+
+	var output string
+	err = job.runStage(name, func() (err error) {
+		output, err = stage()
+		return
+	})
+	if err != nil {
+		m.save()
+		return "", err
+	}
+
+	if hash != "" {
+		m.Stages[name] = &ingestManifestEntry{
+			Stage:         name,
+			InputHash:     hash,
+			Output:        output,
+			Artifacts:     artifacts,
+			CompletedTime: time.Now(),
+		}
+	}
+	m.save()
+
+	return output, nil
+}
+
+func (m *ingestManifest) save() {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		log.Warnf("unable to serialize ingest manifest: %v", err)
+		return
+	}
+
+	err = util.WriteFileWithDirs(m.filename, data, os.ModePerm)
+	if err != nil {
+		log.Warnf("unable to store ingest manifest %s: %v", m.filename, err)
+	}
+}
+
+// hashStageInput hashes the schema and every data resource it references.
+// Directory resources (ie media collections) are hashed by their listing
+// and file sizes rather than their content.
+func hashStageInput(stage string, schemaFile string) (string, error) {
+	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaFile)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to load schema")
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(stage))
+
+	err = hashFile(hash, schemaFile)
+	if err != nil {
+		return "", err
+	}
+
+	schemaDir := path.Dir(schemaFile)
+	for _, dr := range meta.DataResources {
+		resPath := path.Join(schemaDir, dr.ResPath)
+		info, err := os.Stat(resPath)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to stat data resource %s", resPath)
+		}
+
+		if !info.IsDir() {
+			err = hashFile(hash, resPath)
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		err = filepath.Walk(resPath, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			hash.Write([]byte(getRelativePath(resPath, p)))
+			hash.Write([]byte(fmt.Sprintf("%d", fi.Size())))
+			return nil
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to list data resource %s", resPath)
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return errors.Wrapf(err, "unable to open %s", filename)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read %s", filename)
+	}
+	return nil
+}
+
+func filesExist(filenames []string) bool {
+	for _, filename := range filenames {
+		if _, err := os.Stat(filename); err != nil {
+			return false
+		}
+	}
+	return true
+}
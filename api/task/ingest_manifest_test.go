@@ -0,0 +1,158 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uncharted-distil/distil/api/compute/ta2mock"
+)
+
+// runTestManifest loads the manifest and runs the stages over the schema,
+// each stage returning its input, and returns the stages that ran.
+func runTestManifest(t *testing.T, filename string, config *IngestTaskConfig, schemaFile string, stages ...string) []string {
+	manifest := loadIngestManifestFile(filename, "manifest_test", config)
+	ran := make([]string, 0)
+	for _, stage := range stages {
+		name := stage
+		output, err := manifest.runStage(nil, name, schemaFile, nil, func() (string, error) {
+			ran = append(ran, name)
+			return schemaFile, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, schemaFile, output)
+	}
+	return ran
+}
+
+func TestIngestManifestResume(t *testing.T) {
+	folder, err := ioutil.TempDir("", "ingest_manifest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+
+	dataset, err := ta2mock.WriteDataset(folder, "manifest_test", 10)
+	assert.NoError(t, err)
+	schemaFile := path.Join(dataset.Folder, "datasetDoc.json")
+	filename := path.Join(folder, ingestManifestFilename)
+	resume := &IngestTaskConfig{ResumeEnabled: true}
+
+	// the first run executes every stage
+	ran := runTestManifest(t, filename, resume, schemaFile, MergeStage, CleanStage, ClassifyStage)
+	assert.Equal(t, []string{MergeStage, CleanStage, ClassifyStage}, ran)
+
+	// up to date stages are reused
+	ran = runTestManifest(t, filename, resume, schemaFile, MergeStage, CleanStage, ClassifyStage)
+	assert.Empty(t, ran)
+
+	// without resuming every stage runs
+	ran = runTestManifest(t, filename, &IngestTaskConfig{}, schemaFile, MergeStage, CleanStage, ClassifyStage)
+	assert.Equal(t, []string{MergeStage, CleanStage, ClassifyStage}, ran)
+
+	// restaging runs the stage and every stage after it
+	restage := &IngestTaskConfig{ResumeEnabled: true, RestageFrom: CleanStage}
+	ran = runTestManifest(t, filename, restage, schemaFile, MergeStage, CleanStage, ClassifyStage)
+	assert.Equal(t, []string{CleanStage, ClassifyStage}, ran)
+
+	// a changed input is stale
+	_, err = ta2mock.WriteDataset(folder, "manifest_test", 20)
+	assert.NoError(t, err)
+	ran = runTestManifest(t, filename, resume, schemaFile, MergeStage, CleanStage, ClassifyStage)
+	assert.Equal(t, []string{MergeStage, CleanStage, ClassifyStage}, ran)
+}
+
+func TestIngestManifestMissingArtifact(t *testing.T) {
+	folder, err := ioutil.TempDir("", "ingest_manifest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+
+	dataset, err := ta2mock.WriteDataset(folder, "manifest_test", 10)
+	assert.NoError(t, err)
+	schemaFile := path.Join(dataset.Folder, "datasetDoc.json")
+	artifact := path.Join(dataset.Folder, "classification.json")
+	filename := path.Join(folder, ingestManifestFilename)
+	resume := &IngestTaskConfig{ResumeEnabled: true}
+
+	run := func() bool {
+		ran := false
+		manifest := loadIngestManifestFile(filename, "manifest_test", resume)
+		_, err := manifest.runStage(nil, ClassifyStage, schemaFile, []string{artifact}, func() (string, error) {
+			ran = true
+			return schemaFile, ioutil.WriteFile(artifact, []byte("{}"), 0644)
+		})
+		assert.NoError(t, err)
+		return ran
+	}
+
+	assert.True(t, run())
+	assert.False(t, run())
+
+	// a stage whose artifacts are gone runs again
+	assert.NoError(t, os.Remove(artifact))
+	assert.True(t, run())
+}
+
+func TestValidateRestageStage(t *testing.T) {
+	config := &IngestTaskConfig{}
+	assert.NoError(t, ValidateRestageStage(CleanStage, config))
+	assert.Error(t, ValidateRestageStage(StoreStage, config))
+	assert.Error(t, ValidateRestageStage("unknown", config))
+
+	// disabled stages can not be restaged from
+	assert.Error(t, ValidateRestageStage(ClusterStage, config))
+	assert.NoError(t, ValidateRestageStage(ClusterStage, &IngestTaskConfig{ClusteringEnabled: true}))
+
+	job := newTestIngestJob()
+	assert.NoError(t, job.ValidateRestageStage(CleanStage))
+	assert.Error(t, job.ValidateRestageStage(StoreStage))
+	assert.Error(t, job.ValidateRestageStage("unknown"))
+}
+
+func TestLockIngestDataset(t *testing.T) {
+	unlock := lockIngestDataset("lock_test")
+
+	locked := make(chan struct{})
+	released := make(chan struct{})
+	go func() {
+		release := lockIngestDataset("lock_test")
+		close(locked)
+		release()
+		close(released)
+	}()
+
+	// a second ingest of the dataset waits for the first one
+	select {
+	case <-locked:
+		t.Fatal("dataset locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// other datasets are not held back
+	lockIngestDataset("other_lock_test")()
+
+	unlock()
+	<-locked
+	<-released
+
+	ingestDatasetLocksMu.Lock()
+	assert.Len(t, ingestDatasetLocks, 0)
+	ingestDatasetLocksMu.Unlock()
+}
@@ -0,0 +1,309 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-ingest/metadata"
+	log "github.com/unchartedsoftware/plog"
+)
+
+// IngestFailurePolicy determines how the ingest chain reacts to a stage failure.
+type IngestFailurePolicy int
+
+const (
+	// FailHard aborts the ingest when the stage fails.
+	FailHard IngestFailurePolicy = iota
+	// FailSoft logs the stage failure and continues with the stage input. The
+	// HardFail config flag promotes soft failures to hard failures.
+	FailSoft
+)
+
+// IngestStageContext holds the dataset details made available to every stage.
+type IngestStageContext struct {
+	Source  metadata.DatasetSource
+	Index   string
+	Dataset string
+	Config  *IngestTaskConfig
+}
+
+// IngestStage is a single step of the ingest chain. It reads the dataset
+// described by the input schema and returns the path of the output schema,
+// which may be the input schema if the stage only produces side artifacts.
+type IngestStage interface {
+	Name() string
+	Enabled(ctx *IngestStageContext) bool
+	Run(ctx *IngestStageContext, schemaFile string) (string, error)
+}
+
+// IngestStageArtifacts is optionally implemented by stages that write files
+// alongside the input schema rather than producing a new schema. The listed
+// files must exist for a previous run of the stage to be reused.
+type IngestStageArtifacts interface {
+	Artifacts(ctx *IngestStageContext, schemaFile string) []string
+}
+
+type registeredIngestStage struct {
+	stage  IngestStage
+	order  int
+	policy IngestFailurePolicy
+}
+
+func (r *registeredIngestStage) hardFail(config *IngestTaskConfig) bool {
+	return r.policy == FailHard || config.HardFail
+}
+
+var (
+	ingestStageRegistry   = make(map[string]*registeredIngestStage)
+	ingestStageRegistryMu = &sync.RWMutex{}
+)
+
+// RegisterIngestStage adds a stage to the ingest chain. Stages run in
+// ascending order; the built in stages are spaced by 100 so custom stages can
+// be slotted between them.
+func RegisterIngestStage(stage IngestStage, order int, policy IngestFailurePolicy) error {
+	ingestStageRegistryMu.Lock()
+	defer ingestStageRegistryMu.Unlock()
+
+	name := stage.Name()
+	if name == StoreStage {
+		return errors.Errorf("ingest stage name `%s` is reserved", name)
+	}
+	if _, ok := ingestStageRegistry[name]; ok {
+		return errors.Errorf("ingest stage `%s` already registered", name)
+	}
+
+	ingestStageRegistry[name] = &registeredIngestStage{
+		stage:  stage,
+		order:  order,
+		policy: policy,
+	}
+	return nil
+}
+
+// UnregisterIngestStage removes a stage from the ingest chain.
+func UnregisterIngestStage(name string) {
+	ingestStageRegistryMu.Lock()
+	defer ingestStageRegistryMu.Unlock()
+
+	delete(ingestStageRegistry, name)
+}
+
+// enabledIngestStages returns the enabled stages in execution order.
+func enabledIngestStages(ctx *IngestStageContext) []*registeredIngestStage {
+	ingestStageRegistryMu.RLock()
+	defer ingestStageRegistryMu.RUnlock()
+
+	stages := make([]*registeredIngestStage, 0)
+	for _, r := range ingestStageRegistry {
+		if r.stage.Enabled(ctx) {
+			stages = append(stages, r)
+		}
+	}
+	sort.Slice(stages, func(i, j int) bool {
+		if stages[i].order == stages[j].order {
+			return stages[i].stage.Name() < stages[j].stage.Name()
+		}
+		return stages[i].order < stages[j].order
+	})
+
+	return stages
+}
+
+// runIngestStages runs the enabled stages in order, each over the schema
+// output by the last stage to succeed, and returns the final schema. A soft
+// failure is logged and the next stage runs over the same schema, while a
+// hard failure or a cancellation aborts the chain.
+func runIngestStages(job *IngestJob, ctx *IngestStageContext, manifest *ingestManifest, schemaFile string) (string, error) {
+	latestSchemaOutput := schemaFile
+	for _, r := range enabledIngestStages(ctx) {
+		name := r.stage.Name()
+		input := latestSchemaOutput
+		artifacts := stageArtifacts(r.stage, ctx, input)
+
+		output, err := manifest.runStage(job, name, input, artifacts, func() (string, error) {
+			return r.stage.Run(ctx, input)
+		})
+		if err != nil {
+			if r.hardFail(ctx.Config) || err == errIngestCancelled {
+				return "", errors.Wrapf(err, "unable to run %s stage", name)
+			}
+			log.Errorf("unable to run %s stage: %v", name, err)
+			continue
+		}
+		latestSchemaOutput = output
+		log.Infof("finished %s stage of the dataset", name)
+	}
+
+	return latestSchemaOutput, nil
+}
+
+func stageArtifacts(stage IngestStage, ctx *IngestStageContext, schemaFile string) []string {
+	if a, ok := stage.(IngestStageArtifacts); ok {
+		return a.Artifacts(ctx, schemaFile)
+	}
+	return nil
+}
+
+// builtinStage adapts the existing stage functions to the IngestStage interface.
+type builtinStage struct {
+	name      string
+	enabled   func(config *IngestTaskConfig) bool
+	run       func(ctx *IngestStageContext, schemaFile string) (string, error)
+	artifacts func(config *IngestTaskConfig) []string
+}
+
+func (b *builtinStage) Name() string {
+	return b.name
+}
+
+func (b *builtinStage) Enabled(ctx *IngestStageContext) bool {
+	return b.enabled == nil || b.enabled(ctx.Config)
+}
+
+func (b *builtinStage) Run(ctx *IngestStageContext, schemaFile string) (string, error) {
+	return b.run(ctx, schemaFile)
+}
+
+func (b *builtinStage) Artifacts(ctx *IngestStageContext, schemaFile string) []string {
+	if b.artifacts == nil {
+		return nil
+	}
+	artifacts := b.artifacts(ctx.Config)
+	for i, artifact := range artifacts {
+		artifacts[i] = path.Join(path.Dir(schemaFile), artifact)
+	}
+	return artifacts
+}
+
+func init() {
+	builtins := []struct {
+		stage  *builtinStage
+		order  int
+		policy IngestFailurePolicy
+	}{
+		{
+			stage: &builtinStage{
+				name: MergeStage,
+				run: func(ctx *IngestStageContext, schemaFile string) (string, error) {
+					return Merge(ctx.Source, schemaFile, ctx.Index, ctx.Dataset, ctx.Config)
+				},
+			},
+			order:  100,
+			policy: FailHard,
+		},
+		{
+			stage: &builtinStage{
+				name: CleanStage,
+				run: func(ctx *IngestStageContext, schemaFile string) (string, error) {
+					return Clean(ctx.Source, schemaFile, ctx.Index, ctx.Dataset, ctx.Config)
+				},
+			},
+			order:  200,
+			policy: FailHard,
+		},
+		{
+			stage: &builtinStage{
+				name: ClusterStage,
+				enabled: func(config *IngestTaskConfig) bool {
+					return config.ClusteringEnabled
+				},
+				run: func(ctx *IngestStageContext, schemaFile string) (string, error) {
+					return Cluster(ctx.Source, schemaFile, ctx.Index, ctx.Dataset, ctx.Config)
+				},
+			},
+			order:  300,
+			policy: FailSoft,
+		},
+		{
+			stage: &builtinStage{
+				name: FeaturizeStage,
+				run: func(ctx *IngestStageContext, schemaFile string) (string, error) {
+					return Featurize(ctx.Source, schemaFile, ctx.Index, ctx.Dataset, ctx.Config)
+				},
+			},
+			order:  400,
+			policy: FailSoft,
+		},
+		{
+			stage: &builtinStage{
+				name: ClassifyStage,
+				run: func(ctx *IngestStageContext, schemaFile string) (string, error) {
+					return schemaFile, Classify(schemaFile, ctx.Index, ctx.Dataset, ctx.Config)
+				},
+				artifacts: func(config *IngestTaskConfig) []string {
+					return []string{config.ClassificationOutputPathRelative}
+				},
+			},
+			order:  500,
+			policy: FailHard,
+		},
+		{
+			stage: &builtinStage{
+				name: RankStage,
+				run: func(ctx *IngestStageContext, schemaFile string) (string, error) {
+					return schemaFile, Rank(schemaFile, ctx.Index, ctx.Dataset, ctx.Config)
+				},
+				artifacts: func(config *IngestTaskConfig) []string {
+					return []string{config.RankingOutputPathRelative}
+				},
+			},
+			order:  600,
+			policy: FailHard,
+		},
+		{
+			stage: &builtinStage{
+				name: SummarizeStage,
+				enabled: func(config *IngestTaskConfig) bool {
+					return config.SummaryEnabled
+				},
+				run: func(ctx *IngestStageContext, schemaFile string) (string, error) {
+					return schemaFile, Summarize(schemaFile, ctx.Index, ctx.Dataset, ctx.Config)
+				},
+				artifacts: func(config *IngestTaskConfig) []string {
+					return []string{config.SummaryMachineOutputPathRelative}
+				},
+			},
+			order:  700,
+			policy: FailSoft,
+		},
+		{
+			stage: &builtinStage{
+				name: GeocodeStage,
+				enabled: func(config *IngestTaskConfig) bool {
+					return config.GeocodingEnabled
+				},
+				run: func(ctx *IngestStageContext, schemaFile string) (string, error) {
+					return GeocodeForwardDataset(ctx.Source, schemaFile, ctx.Index, ctx.Dataset, ctx.Config)
+				},
+			},
+			order:  800,
+			policy: FailHard,
+		},
+	}
+
+	for _, b := range builtins {
+		ingestStageRegistry[b.stage.name] = &registeredIngestStage{
+			stage:  b.stage,
+			order:  b.order,
+			policy: b.policy,
+		}
+	}
+}
@@ -0,0 +1,191 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testIngestStage struct {
+	name     string
+	disabled bool
+	fail     bool
+	ran      *[]string
+}
+
+func (s *testIngestStage) Name() string {
+	return s.name
+}
+
+func (s *testIngestStage) Enabled(ctx *IngestStageContext) bool {
+	return !s.disabled
+}
+
+func (s *testIngestStage) Run(ctx *IngestStageContext, schemaFile string) (string, error) {
+	*s.ran = append(*s.ran, s.name)
+	if s.fail {
+		return "", errors.Errorf("%s failed", s.name)
+	}
+	return path.Join(schemaFile, s.name), nil
+}
+
+type testIngestStageRegistration struct {
+	stage  *testIngestStage
+	order  int
+	policy IngestFailurePolicy
+}
+
+// useIngestStages replaces the registered stages, returning a function
+// restoring them.
+func useIngestStages(t *testing.T, registrations []testIngestStageRegistration) func() {
+	ingestStageRegistryMu.Lock()
+	previous := ingestStageRegistry
+	ingestStageRegistry = make(map[string]*registeredIngestStage)
+	ingestStageRegistryMu.Unlock()
+
+	for _, r := range registrations {
+		assert.NoError(t, RegisterIngestStage(r.stage, r.order, r.policy))
+	}
+
+	return func() {
+		ingestStageRegistryMu.Lock()
+		ingestStageRegistry = previous
+		ingestStageRegistryMu.Unlock()
+	}
+}
+
+func TestRunIngestStages(t *testing.T) {
+	folder, err := ioutil.TempDir("", "ingest_stage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+
+	tests := []struct {
+		name     string
+		stages   []*testIngestStage
+		orders   []int
+		policies []IngestFailurePolicy
+		hardFail bool
+		ran      []string
+		output   string
+		err      bool
+	}{
+		{
+			name: "ordering",
+			stages: []*testIngestStage{
+				{name: "c"},
+				{name: "a"},
+				{name: "b"},
+				{name: "d", disabled: true},
+			},
+			orders:   []int{300, 100, 100, 200},
+			policies: []IngestFailurePolicy{FailHard, FailHard, FailHard, FailHard},
+			ran:      []string{"a", "b", "c"},
+			output:   "schema/a/b/c",
+		},
+		{
+			name: "soft failure continues",
+			stages: []*testIngestStage{
+				{name: "a"},
+				{name: "b", fail: true},
+				{name: "c"},
+			},
+			orders:   []int{100, 200, 300},
+			policies: []IngestFailurePolicy{FailHard, FailSoft, FailHard},
+			ran:      []string{"a", "b", "c"},
+			output:   "schema/a/c",
+		},
+		{
+			name: "hard failure aborts",
+			stages: []*testIngestStage{
+				{name: "a"},
+				{name: "b", fail: true},
+				{name: "c"},
+			},
+			orders:   []int{100, 200, 300},
+			policies: []IngestFailurePolicy{FailHard, FailHard, FailHard},
+			ran:      []string{"a", "b"},
+			err:      true,
+		},
+		{
+			name: "soft failure promoted",
+			stages: []*testIngestStage{
+				{name: "a", fail: true},
+				{name: "b"},
+			},
+			orders:   []int{100, 200},
+			policies: []IngestFailurePolicy{FailSoft, FailSoft},
+			hardFail: true,
+			ran:      []string{"a"},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ran := make([]string, 0)
+			registrations := make([]testIngestStageRegistration, len(test.stages))
+			for i, stage := range test.stages {
+				stage.ran = &ran
+				registrations[i] = testIngestStageRegistration{
+					stage:  stage,
+					order:  test.orders[i],
+					policy: test.policies[i],
+				}
+			}
+			restore := useIngestStages(t, registrations)
+			defer restore()
+
+			ctx := &IngestStageContext{
+				Dataset: test.name,
+				Config:  &IngestTaskConfig{HardFail: test.hardFail},
+			}
+			manifest := &ingestManifest{
+				Dataset:  test.name,
+				Stages:   make(map[string]*ingestManifestEntry),
+				filename: path.Join(folder, test.name, ingestManifestFilename),
+				stale:    true,
+			}
+
+			output, err := runIngestStages(nil, ctx, manifest, "schema")
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.output, output)
+			}
+			assert.Equal(t, test.ran, ran)
+		})
+	}
+}
+
+func TestRegisterIngestStage(t *testing.T) {
+	restore := useIngestStages(t, nil)
+	defer restore()
+
+	ran := make([]string, 0)
+	assert.NoError(t, RegisterIngestStage(&testIngestStage{name: "a", ran: &ran}, 100, FailHard))
+	assert.Error(t, RegisterIngestStage(&testIngestStage{name: "a", ran: &ran}, 200, FailHard))
+	assert.Error(t, RegisterIngestStage(&testIngestStage{name: StoreStage, ran: &ran}, 200, FailHard))
+
+	UnregisterIngestStage("a")
+	assert.Len(t, enabledIngestStages(&IngestStageContext{Config: &IngestTaskConfig{}}), 0)
+}
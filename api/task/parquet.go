@@ -0,0 +1,182 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+const (
+	parquetReaderThreads = 4
+	parquetBatchSize     = 10000
+)
+
+// convertParquet reads a flat parquet file into csv rows, seeding the column
+// types from the parquet schema. Rows are converted in batches to bound the
+// memory used by large files.
+func convertParquet(filename string, csvFile string) ([]*model.Variable, error) {
+	file, err := local.NewLocalFileReader(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open parquet file")
+	}
+	defer file.Close()
+
+	pr, err := reader.NewParquetColumnReader(file, parquetReaderThreads)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read parquet file")
+	}
+	defer pr.ReadStop()
+
+	schema := pr.SchemaHandler
+	if len(schema.SchemaElements)-1 != len(schema.ValueColumns) {
+		return nil, errors.New("nested parquet schemas are not supported")
+	}
+
+	columns := make([]string, len(schema.ValueColumns))
+	kinds := make([]int, len(schema.ValueColumns))
+	elements := make([]*parquet.SchemaElement, len(schema.ValueColumns))
+	for c, columnPath := range schema.ValueColumns {
+		elements[c] = schema.SchemaElements[schema.MapIndex[columnPath]]
+		columns[c] = elements[c].GetName()
+		kinds[c] = parquetKind(elements[c])
+	}
+
+	output, err := os.Create(csvFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create csv file")
+	}
+	defer output.Close()
+
+	writer := csv.NewWriter(output)
+	err = writer.Write(columns)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to write header")
+	}
+
+	numRows := pr.GetNumRows()
+	for read := int64(0); read < numRows; read += parquetBatchSize {
+		batchSize := numRows - read
+		if batchSize > parquetBatchSize {
+			batchSize = parquetBatchSize
+		}
+
+		lines := make([][]string, batchSize)
+		for i := range lines {
+			lines[i] = make([]string, len(columns))
+		}
+
+		for c := range columns {
+			values, _, _, err := pr.ReadColumnByIndex(int64(c), batchSize)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to read parquet column %s", columns[c])
+			}
+			if int64(len(values)) != batchSize {
+				return nil, errors.Errorf("parquet column %s returned %d values but expected %d", columns[c], len(values), batchSize)
+			}
+
+			for r, value := range values {
+				lines[r][c] = formatParquetValue(elements[c], value)
+			}
+		}
+
+		err = writer.WriteAll(lines)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to write data")
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to write data")
+	}
+
+	return newConvertedVariables(columns, kinds), nil
+}
+
+func parquetKind(element *parquet.SchemaElement) int {
+	if element.ConvertedType != nil {
+		switch *element.ConvertedType {
+		case parquet.ConvertedType_UTF8, parquet.ConvertedType_ENUM, parquet.ConvertedType_JSON:
+			return kindText
+		case parquet.ConvertedType_DATE, parquet.ConvertedType_TIMESTAMP_MILLIS, parquet.ConvertedType_TIMESTAMP_MICROS:
+			return kindDateTime
+		case parquet.ConvertedType_DECIMAL:
+			return kindText
+		}
+	}
+
+	switch element.GetType() {
+	case parquet.Type_BOOLEAN:
+		return kindBool
+	case parquet.Type_INT32, parquet.Type_INT64:
+		return kindInteger
+	case parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		return kindFloat
+	default:
+		return kindText
+	}
+}
+
+func formatParquetValue(element *parquet.SchemaElement, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	if element.ConvertedType != nil {
+		switch *element.ConvertedType {
+		case parquet.ConvertedType_DATE:
+			if days, ok := value.(int32); ok {
+				return time.Unix(int64(days)*24*60*60, 0).UTC().Format("2006-01-02")
+			}
+		case parquet.ConvertedType_TIMESTAMP_MILLIS:
+			if millis, ok := value.(int64); ok {
+				return time.Unix(0, millis*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+			}
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			if micros, ok := value.(int64); ok {
+				return time.Unix(0, micros*int64(time.Microsecond)).UTC().Format(time.RFC3339)
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
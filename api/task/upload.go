@@ -0,0 +1,372 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+
+	"github.com/uncharted-distil/distil/api/env"
+	"github.com/uncharted-distil/distil/api/util"
+)
+
+const (
+	// UploadFormatCSV is a raw csv file.
+	UploadFormatCSV = "csv"
+	// UploadFormatParquet is an Apache Parquet file.
+	UploadFormatParquet = "parquet"
+	// UploadFormatNDJSON is a newline delimited JSON file.
+	UploadFormatNDJSON = "ndjson"
+	// UploadFormatZip is a zip archive of a complete D3M dataset.
+	UploadFormatZip = "zip"
+)
+
+// column kinds observed while inferring the type of a converted column.
+const (
+	kindUnknown = iota
+	kindBool
+	kindInteger
+	kindFloat
+	kindDateTime
+	kindText
+)
+
+// DetectUploadFormat determines the format of an uploaded file from its
+// content type, falling back to the file extension. Unrecognized files are
+// treated as csv.
+func DetectUploadFormat(filename string, contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/x-parquet", "application/vnd.apache.parquet", "application/parquet":
+		return UploadFormatParquet
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return UploadFormatNDJSON
+	case "application/zip", "application/x-zip-compressed":
+		return UploadFormatZip
+	}
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".parquet", ".pq":
+		return UploadFormatParquet
+	case ".ndjson", ".jsonl":
+		return UploadFormatNDJSON
+	case ".zip":
+		return UploadFormatZip
+	}
+
+	return UploadFormatCSV
+}
+
+// CreateDatasetFromUpload converts an uploaded file of the given format into
+// a valid D3M dataset. Column types known from the source schema are used to
+// seed the dataset schema. Zip archives must contain a complete D3M dataset.
+func CreateDatasetFromUpload(dataset string, uploadFile string, format string, outputPath string, config *IngestTaskConfig) (string, error) {
+	var convert func(filename string, csvFile string) ([]*model.Variable, error)
+
+	switch format {
+	case UploadFormatParquet:
+		convert = convertParquet
+	case UploadFormatNDJSON:
+		convert = convertNDJSON
+	case UploadFormatZip:
+		return createDatasetFromArchive(dataset, uploadFile, outputPath, config.MaxArchiveSize, config.MaxArchiveEntries)
+	case UploadFormatCSV:
+		return CreateDatasetFromFile(dataset, uploadFile, outputPath, config)
+	default:
+		return "", errors.Errorf("unsupported upload format `%s`", format)
+	}
+
+	return createDataset(dataset, func(dataPath string) ([]*model.Variable, error) {
+		err := os.MkdirAll(path.Dir(dataPath), os.ModePerm)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create data folder")
+		}
+
+		variables, err := convert(uploadFile, dataPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to convert %s data", format)
+		}
+		return variables, nil
+	}, outputPath, config)
+}
+
+// createDatasetFromArchive extracts a zipped D3M dataset into the output
+// folder. The archive can hold the dataset at its root, within a single top
+// level folder, or in the TRAIN/dataset_TRAIN structure. Extraction stops
+// once the archive exceeds the max size or number of entries.
+func createDatasetFromArchive(dataset string, zipFile string, outputPath string, maxSize int64, maxEntries int) (string, error) {
+	extractedPath := path.Join(env.GetTmpPath(), "uploads", fmt.Sprintf("%s-%d", dataset, time.Now().UnixNano()))
+	defer os.RemoveAll(extractedPath)
+	err := util.UnzipLimited(zipFile, extractedPath, maxSize, maxEntries)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to extract dataset archive")
+	}
+
+	datasetPath, err := findDatasetRoot(extractedPath)
+	if err != nil {
+		return "", err
+	}
+
+	outputDatasetPath := path.Join(outputPath, dataset)
+	err = os.RemoveAll(outputDatasetPath)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to remove existing dataset")
+	}
+
+	err = util.Copy(datasetPath, outputDatasetPath)
+	if err != nil {
+		return "", err
+	}
+
+	return outputDatasetPath, nil
+}
+
+func findDatasetRoot(dir string) (string, error) {
+	if util.IsDatasetDir(dir) {
+		return path.Join(dir, "TRAIN", "dataset_TRAIN"), nil
+	}
+	if _, err := os.Stat(path.Join(dir, compute.D3MDataSchema)); err == nil {
+		return dir, nil
+	}
+
+	// descend into a single top level folder
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read extracted archive")
+	}
+	folders := make([]string, 0)
+	for _, f := range files {
+		if f.IsDir() && !strings.HasPrefix(f.Name(), "__MACOSX") {
+			folders = append(folders, f.Name())
+		}
+	}
+	if len(folders) == 1 {
+		return findDatasetRoot(path.Join(dir, folders[0]))
+	}
+
+	return "", errors.Errorf("archive does not contain a D3M dataset (missing %s)", compute.D3MDataSchema)
+}
+
+// convertNDJSON flattens newline delimited JSON objects into csv rows. Columns
+// are ordered by first appearance and nested values are kept as JSON text. The
+// file is read twice, first to collect the columns and then to write the rows.
+func convertNDJSON(filename string, csvFile string) ([]*model.Variable, error) {
+	columns := make([]string, 0)
+	columnIndices := make(map[string]int)
+	kinds := make([]int, 0)
+
+	err := scanNDJSON(filename, func(keys []string, values []interface{}) error {
+		for i, key := range keys {
+			index, ok := columnIndices[key]
+			if !ok {
+				index = len(columns)
+				columnIndices[key] = index
+				columns = append(columns, key)
+				kinds = append(kinds, kindUnknown)
+			}
+
+			_, kind := formatJSONValue(values[i])
+			kinds[index] = mergeKind(kinds[index], kind)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("no columns found")
+	}
+
+	output, err := os.Create(csvFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create csv file")
+	}
+	defer output.Close()
+
+	writer := csv.NewWriter(output)
+	err = writer.Write(columns)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to write header")
+	}
+
+	err = scanNDJSON(filename, func(keys []string, values []interface{}) error {
+		line := make([]string, len(columns))
+		for i, key := range keys {
+			line[columnIndices[key]], _ = formatJSONValue(values[i])
+		}
+		return writer.Write(line)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to write data")
+	}
+
+	return newConvertedVariables(columns, kinds), nil
+}
+
+// scanNDJSON parses every non empty line of the file as a JSON object.
+func scanNDJSON(filename string, process func(keys []string, values []interface{}) error) error {
+	input, err := os.Open(filename)
+	if err != nil {
+		return errors.Wrap(err, "unable to open data")
+	}
+	defer input.Close()
+
+	reader := bufio.NewReader(input)
+	lineNumber := 0
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return errors.Wrap(readErr, "unable to read data")
+		}
+
+		lineNumber++
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			keys, values, err := parseNDJSONObject(line)
+			if err != nil {
+				return errors.Wrapf(err, "unable to parse line %d", lineNumber)
+			}
+			err = process(keys, values)
+			if err != nil {
+				return errors.Wrapf(err, "unable to process line %d", lineNumber)
+			}
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// parseNDJSONObject parses a single JSON object, preserving the key order.
+func parseNDJSONObject(line []byte) ([]string, []interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, errors.New("line is not a JSON object")
+	}
+
+	keys := make([]string, 0)
+	values := make([]interface{}, 0)
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, nil, errors.Errorf("unexpected object key %v", token)
+		}
+
+		var value interface{}
+		err = decoder.Decode(&value)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+
+	return keys, values, nil
+}
+
+func formatJSONValue(value interface{}) (string, int) {
+	switch v := value.(type) {
+	case nil:
+		return "", kindUnknown
+	case bool:
+		return strconv.FormatBool(v), kindBool
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return v.String(), kindInteger
+		}
+		return v.String(), kindFloat
+	case string:
+		return v, kindText
+	default:
+		nested, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v), kindText
+		}
+		return string(nested), kindText
+	}
+}
+
+// mergeKind combines the kind inferred so far for a column with the kind of
+// a newly observed value.
+func mergeKind(current int, observed int) int {
+	if current == kindUnknown {
+		return observed
+	}
+	if observed == kindUnknown || observed == current {
+		return current
+	}
+	if (current == kindInteger && observed == kindFloat) || (current == kindFloat && observed == kindInteger) {
+		return kindFloat
+	}
+	return kindText
+}
+
+func kindToType(kind int) string {
+	switch kind {
+	case kindBool:
+		return model.BoolType
+	case kindInteger:
+		return model.IntegerType
+	case kindFloat:
+		return model.FloatType
+	case kindDateTime:
+		return model.DateTimeType
+	default:
+		return model.TextType
+	}
+}
+
+func newConvertedVariables(columns []string, kinds []int) []*model.Variable {
+	variables := make([]*model.Variable, 0)
+	for i, column := range columns {
+		typ := kindToType(kinds[i])
+		v := model.NewVariable(i, column, column, column, typ, typ, []string{"attribute"}, model.VarRoleData, nil, variables, false)
+		variables = append(variables, v)
+	}
+	return variables
+}
@@ -0,0 +1,83 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uncharted-distil/distil-compute/model"
+)
+
+func TestDetectUploadFormat(t *testing.T) {
+	assert.Equal(t, UploadFormatParquet, DetectUploadFormat("data.bin", "application/x-parquet"))
+	assert.Equal(t, UploadFormatNDJSON, DetectUploadFormat("data.bin", "application/x-ndjson; charset=utf-8"))
+	assert.Equal(t, UploadFormatParquet, DetectUploadFormat("data.PARQUET", "application/octet-stream"))
+	assert.Equal(t, UploadFormatNDJSON, DetectUploadFormat("data.jsonl", ""))
+	assert.Equal(t, UploadFormatCSV, DetectUploadFormat("data.csv", "text/csv"))
+}
+
+func TestConvertNDJSON(t *testing.T) {
+	data := []byte(`{"name": "alpha", "count": 1, "score": 1}
+{"name": "bravo", "count": 2, "score": 2.5, "extra": {"a": true}}
+
+{"count": null, "name": "charlie", "flag": false}
+`)
+
+	dir, err := ioutil.TempDir("", "ndjson")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := path.Join(dir, "data.ndjson")
+	csvFile := path.Join(dir, "data.csv")
+	err = ioutil.WriteFile(filename, data, os.ModePerm)
+	assert.NoError(t, err)
+
+	variables, err := convertNDJSON(filename, csvFile)
+	assert.NoError(t, err)
+
+	csvData, err := ioutil.ReadFile(csvFile)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "name,count,score,extra,flag\n"+
+		"alpha,1,1,,\n"+
+		"bravo,2,2.5,\"{\"\"a\"\":true}\",\n"+
+		"charlie,,,,false\n", string(csvData))
+
+	assert.Len(t, variables, 5)
+	assert.Equal(t, model.TextType, variables[0].Type)
+	assert.Equal(t, model.IntegerType, variables[1].Type)
+	assert.Equal(t, model.FloatType, variables[2].Type)
+	assert.Equal(t, model.TextType, variables[3].Type)
+	assert.Equal(t, model.BoolType, variables[4].Type)
+}
+
+func TestConvertNDJSONInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ndjson")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := path.Join(dir, "data.ndjson")
+	err = ioutil.WriteFile(filename, []byte(`[1, 2, 3]`), os.ModePerm)
+	assert.NoError(t, err)
+
+	_, err = convertNDJSON(filename, path.Join(dir, "data.csv"))
+	assert.Error(t, err)
+}
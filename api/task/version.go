@@ -0,0 +1,99 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	elastic "gopkg.in/olivere/elastic.v5"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	pgstorage "github.com/uncharted-distil/distil/api/model/storage/postgres"
+)
+
+const (
+	metadataType = "metadata"
+)
+
+// datasetVersion captures the lineage of an ingested dataset.
+type datasetVersion struct {
+	Version int
+	Parent  string
+	Root    string
+}
+
+// newDatasetVersion determines the version of a dataset being ingested. When
+// an existing dataset matches, the new dataset becomes its next version and is
+// given a versioned id and storage name so the previous version, along with
+// its solutions, remains queryable. The name is kept as is, the version being
+// stored separately.
+func newDatasetVersion(meta *model.Metadata, match *api.Dataset) *datasetVersion {
+	if match == nil {
+		return &datasetVersion{
+			Version: 1,
+			Root:    meta.ID,
+		}
+	}
+
+	version := match.Version + 1
+	if match.Version < 1 {
+		version = 2
+	}
+
+	versionedID := fmt.Sprintf("%s_v%d", meta.ID, version)
+	meta.ID = versionedID
+	meta.StorageName = model.NormalizeDatasetID(versionedID)
+
+	return &datasetVersion{
+		Version: version,
+		Parent:  match.ID,
+		Root:    match.GetRoot(),
+	}
+}
+
+// metadataDocumentID returns the id under which the metadata of the dataset
+// is stored. Elasticsearch documents are prefixed as done by the metadata
+// ingest.
+func metadataDocumentID(id string, config *IngestTaskConfig) string {
+	if config.MetadataStorage == pgstorage.Provenance {
+		return id
+	}
+	return config.ESDatasetPrefix + id
+}
+
+// storeDatasetVersion adds the version lineage to the metadata document of
+// the dataset.
+func storeDatasetVersion(client *elastic.Client, index string, id string, version *datasetVersion) error {
+	_, err := client.Update().
+		Index(index).
+		Type(metadataType).
+		Id(id).
+		Doc(map[string]interface{}{
+			"datasetVersion": version.Version,
+			"parentDataset":  version.Parent,
+			"rootDataset":    version.Root,
+		}).
+		Refresh("true").
+		Do(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "unable to store version of dataset %s", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	"github.com/uncharted-distil/distil/api/compute/ta2mock"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func newVersionTestVariables() []*model.Variable {
+	return []*model.Variable{
+		{Name: model.D3MIndexName, Index: 0, Type: model.IntegerType},
+		{Name: "alpha", Index: 1, Type: model.FloatType},
+	}
+}
+
+func newVersionTestMetadata() *model.Metadata {
+	return &model.Metadata{
+		ID:          "version_test",
+		Name:        "Version Test",
+		StorageName: "version_test",
+		DataResources: []*model.DataResource{
+			{Variables: newVersionTestVariables()},
+		},
+	}
+}
+
+func TestDatasetReingestVersion(t *testing.T) {
+	config := &IngestTaskConfig{}
+	first := &api.Dataset{
+		ID:          "version_test",
+		Name:        "Version Test",
+		StorageName: "version_test",
+		Version:     1,
+		Variables:   newVersionTestVariables(),
+	}
+	unrelated := &api.Dataset{
+		ID:          "other_test",
+		Name:        "Other Test",
+		StorageName: "other_test",
+		Version:     4,
+		Variables:   newVersionTestVariables(),
+	}
+	storage := ta2mock.NewMetadataStorage(first, unrelated)
+
+	// re-ingesting creates the next version under its own storage
+	meta := newVersionTestMetadata()
+	match, err := matchDataset(storage, meta, config)
+	assert.NoError(t, err)
+	assert.Equal(t, first, match)
+	version := newDatasetVersion(meta, match)
+	assert.Equal(t, 2, version.Version)
+	assert.Equal(t, "version_test", version.Parent)
+	assert.Equal(t, "version_test", version.Root)
+	assert.Equal(t, "version_test_v2", meta.ID)
+	assert.Equal(t, "Version Test", meta.Name)
+	assert.NotEqual(t, first.StorageName, meta.StorageName)
+
+	// the previous version remains readable
+	previous, err := storage.FetchDataset("version_test", true, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "version_test", previous.StorageName)
+	assert.Len(t, previous.Variables, 2)
+
+	// the latest version is the one matched next
+	second := &api.Dataset{
+		ID:          meta.ID,
+		Name:        meta.Name,
+		StorageName: meta.StorageName,
+		Version:     version.Version,
+		Parent:      version.Parent,
+		Root:        version.Root,
+		Variables:   newVersionTestVariables(),
+	}
+	storage = ta2mock.NewMetadataStorage(first, second, unrelated)
+	meta = newVersionTestMetadata()
+	match, err = matchDataset(storage, meta, config)
+	assert.NoError(t, err)
+	assert.Equal(t, second, match)
+	version = newDatasetVersion(meta, match)
+	assert.Equal(t, 3, version.Version)
+	assert.Equal(t, "version_test_v2", version.Parent)
+	assert.Equal(t, "version_test", version.Root)
+	assert.Equal(t, "version_test_v3", meta.ID)
+}
+
+func TestDatasetVersionNoMatch(t *testing.T) {
+	config := &IngestTaskConfig{}
+	storage := ta2mock.NewMetadataStorage(&api.Dataset{
+		ID:        "version_test",
+		Version:   1,
+		Variables: []*model.Variable{{Name: "beta"}},
+	})
+
+	// different columns start a new dataset
+	meta := newVersionTestMetadata()
+	match, err := matchDataset(storage, meta, config)
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+	version := newDatasetVersion(meta, match)
+	assert.Equal(t, 1, version.Version)
+	assert.Equal(t, "version_test", version.Root)
+	assert.Equal(t, "version_test", meta.ID)
+}
+
+func TestMetadataDocumentID(t *testing.T) {
+	assert.Equal(t, "d_version_test", metadataDocumentID("version_test", &IngestTaskConfig{ESDatasetPrefix: "d_"}))
+	assert.Equal(t, "version_test", metadataDocumentID("version_test", &IngestTaskConfig{ESDatasetPrefix: "d_", MetadataStorage: "postgres"}))
+}
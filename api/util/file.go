@@ -22,6 +22,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/otiai10/copy"
 	"github.com/pkg/errors"
@@ -45,64 +46,118 @@ func WriteFileWithDirs(filename string, data []byte, perm os.FileMode) error {
 
 // Unzip extracts an archive to the given location.
 func Unzip(zipFile string, destination string) error {
+	return UnzipLimited(zipFile, destination, 0, 0)
+}
+
+// UnzipLimited extracts an archive to the given location, failing once the
+// extracted files exceed the max size in bytes or the archive holds more
+// than the max number of entries. Zero limits are unlimited. Entries that
+// would be extracted outside of the destination are rejected.
+func UnzipLimited(zipFile string, destination string, maxSize int64, maxEntries int) (err error) {
 	r, err := zip.OpenReader(zipFile)
 	if err != nil {
 		return errors.Wrap(err, "unable to open archive")
 	}
 	defer func() {
-		if err := r.Close(); err != nil {
-			panic(err)
+		errClose := r.Close()
+		if err == nil && errClose != nil {
+			err = errors.Wrap(errClose, "unable to close archive")
 		}
 	}()
 
-	os.MkdirAll(destination, os.ModePerm)
+	if maxEntries > 0 && len(r.File) > maxEntries {
+		return errors.Errorf("archive holds %d entries, more than the limit of %d", len(r.File), maxEntries)
+	}
 
-	// Closure to address file descriptors issue with all the deferred .Close() methods
-	extractAndWriteFile := func(f *zip.File) error {
-		rc, err := f.Open()
-		if err != nil {
-			return errors.Wrap(err, "unable to open archived file")
-		}
-		defer func() {
-			if err := rc.Close(); err != nil {
-				panic(err)
-			}
-		}()
-
-		path := filepath.Join(destination, f.Name)
-
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(path, os.ModePerm)
-		} else {
-			os.MkdirAll(filepath.Dir(path), os.ModePerm)
-			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
-			if err != nil {
-				return errors.Wrap(err, "unable to write archived file")
-			}
-			defer func() {
-				if err := f.Close(); err != nil {
-					panic(err)
-				}
-			}()
-
-			_, err = io.Copy(f, rc)
-			if err != nil {
-				return errors.Wrap(err, "unable to copy archived file")
-			}
-		}
-		return nil
+	destination = filepath.Clean(destination)
+	err = os.MkdirAll(destination, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "unable to create destination folder")
 	}
 
+	remaining := maxSize
 	for _, f := range r.File {
-		err := extractAndWriteFile(f)
+		written, err := extractArchivedFile(f, destination, remaining, maxSize > 0)
 		if err != nil {
 			return errors.Wrap(err, "unable to extract files")
 		}
+		remaining -= written
 	}
 
 	return nil
 }
 
+// archivedFilePath returns where an archived file is extracted, making sure
+// it is within the destination.
+func archivedFilePath(destination string, name string) (string, error) {
+	target := filepath.Join(destination, name)
+	if target != destination && !strings.HasPrefix(target, destination+string(os.PathSeparator)) {
+		return "", errors.Errorf("archived file `%s` is outside of the destination", name)
+	}
+	return target, nil
+}
+
+func extractArchivedFile(f *zip.File, destination string, remaining int64, limited bool) (written int64, err error) {
+	target, err := archivedFilePath(destination, f.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	if f.FileInfo().IsDir() {
+		err = os.MkdirAll(target, os.ModePerm)
+		if err != nil {
+			return 0, errors.Wrap(err, "unable to create archived folder")
+		}
+		return 0, nil
+	}
+	if limited && int64(f.UncompressedSize64) > remaining {
+		return 0, errors.New("archive exceeds the max extracted size")
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to open archived file")
+	}
+	defer func() {
+		errClose := rc.Close()
+		if err == nil && errClose != nil {
+			err = errors.Wrap(errClose, "unable to close archived file")
+		}
+	}()
+
+	err = os.MkdirAll(filepath.Dir(target), os.ModePerm)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to create archived folder")
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to write archived file")
+	}
+	defer func() {
+		errClose := out.Close()
+		if err == nil && errClose != nil {
+			err = errors.Wrap(errClose, "unable to close extracted file")
+		}
+	}()
+
+	// the declared size can not be trusted so the copy itself is limited
+	if !limited {
+		written, err = io.Copy(out, rc)
+	} else {
+		written, err = io.CopyN(out, rc, remaining+1)
+		if err == io.EOF {
+			err = nil
+		}
+		if err == nil && written > remaining {
+			err = errors.New("archive exceeds the max extracted size")
+		}
+	}
+	if err != nil {
+		return written, errors.Wrap(err, "unable to copy archived file")
+	}
+	return written, nil
+}
+
 // Copy copies a source folder to a destination folder.
 func Copy(sourceFolder string, destinationFolder string) error {
 	// copy the source folder to have all the linked files for merging
@@ -0,0 +1,106 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package util
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeZip(t *testing.T, filename string, files map[string]string) {
+	f, err := os.Create(filename)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		entry, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = entry.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+}
+
+func TestUnzipLimited(t *testing.T) {
+	dir, err := ioutil.TempDir("", "unzip")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := path.Join(dir, "data.zip")
+	writeZip(t, archive, map[string]string{
+		"dataset/datasetDoc.json":           "{}",
+		"dataset/tables/learningData.csv":   "a,b\n1,2\n",
+		"dataset/tables/extra/nested/empty": "",
+	})
+
+	destination := path.Join(dir, "out")
+	err = UnzipLimited(archive, destination, 1024, 10)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadFile(path.Join(destination, "dataset", "tables", "learningData.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2\n", string(data))
+
+	// too many entries
+	err = UnzipLimited(archive, path.Join(dir, "entries"), 1024, 2)
+	assert.Error(t, err)
+}
+
+func TestUnzipLimitedTraversal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "unzip")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := path.Join(dir, "traversal.zip")
+	writeZip(t, archive, map[string]string{
+		"../../escaped.txt": "escaped",
+	})
+
+	destination := path.Join(dir, "nested", "out")
+	err = UnzipLimited(archive, destination, 0, 0)
+	assert.Error(t, err)
+	_, err = os.Stat(path.Join(dir, "escaped.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// the unlimited extraction is just as strict
+	err = Unzip(archive, destination)
+	assert.Error(t, err)
+}
+
+func TestUnzipLimitedOversized(t *testing.T) {
+	dir, err := ioutil.TempDir("", "unzip")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// highly compressible content, as in a zip bomb
+	archive := path.Join(dir, "oversized.zip")
+	writeZip(t, archive, map[string]string{
+		"a.csv": strings.Repeat("0", 600),
+		"b.csv": strings.Repeat("0", 600),
+	})
+
+	err = UnzipLimited(archive, path.Join(dir, "out"), 1000, 0)
+	assert.Error(t, err)
+
+	err = UnzipLimited(archive, path.Join(dir, "fits"), 1200, 0)
+	assert.NoError(t, err)
+}
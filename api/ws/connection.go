@@ -16,6 +16,7 @@
 package ws
 
 import (
+	"net"
 	"net/http"
 	"sync"
 	"time"
@@ -28,6 +29,40 @@ const (
 	maxMessageSize = 256 * 256
 )
 
+var (
+	userHeader   = ""
+	userHeaderMu = &sync.RWMutex{}
+)
+
+// SetUserHeader sets the request header holding the user authenticated by a
+// proxy in front of the server. The header must only be set when the proxy
+// overwrites it, as clients could otherwise claim to be any user.
+func SetUserHeader(header string) {
+	userHeaderMu.Lock()
+	defer userHeaderMu.Unlock()
+	userHeader = header
+}
+
+// requestUser identifies the user of a request by the user header, falling
+// back to the address of the client.
+func requestUser(r *http.Request) string {
+	userHeaderMu.RLock()
+	header := userHeader
+	userHeaderMu.RUnlock()
+	if header != "" {
+		user := r.Header.Get(header)
+		if user != "" {
+			return user
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  maxMessageSize,
 	WriteBufferSize: maxMessageSize,
@@ -44,6 +79,9 @@ type Connection struct {
 	conn    *websocket.Conn
 	mu      *sync.Mutex
 	handler requestHandler
+	user    string
+	done    chan struct{}
+	closed  bool
 }
 
 // NewConnection returns a pointer to a new tile dispatcher object.
@@ -58,7 +96,9 @@ func NewConnection(w http.ResponseWriter, r *http.Request, handler requestHandle
 	return &Connection{
 		conn:    conn,
 		handler: handler,
+		user:    requestUser(r),
 		mu:      &sync.Mutex{},
+		done:    make(chan struct{}),
 	}, nil
 }
 
@@ -93,4 +133,19 @@ func (c *Connection) Close() {
 	defer c.mu.Unlock()
 	// close websocket connection
 	c.conn.Close()
+	// signal the requests still waiting on the connection
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+	}
+}
+
+// User returns the user of the connection.
+func (c *Connection) User() string {
+	return c.user
+}
+
+// Done returns a channel closed once the connection is closed.
+func (c *Connection) Done() <-chan struct{} {
+	return c.done
 }
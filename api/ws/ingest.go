@@ -0,0 +1,60 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package ws
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil/api/task"
+	jutil "github.com/uncharted-distil/distil/api/util/json"
+)
+
+// IngestStatusRequest represents a request to follow the progress of an
+// ingest job.
+type IngestStatusRequest struct {
+	JobID string `json:"jobId"`
+}
+
+func handleIngestStatus(conn *Connection, msg *Message) {
+	// unmarshal request
+	request := &IngestStatusRequest{}
+	err := json.Unmarshal(msg.Raw, request)
+	if err != nil {
+		handleErr(conn, msg, errors.Wrap(err, "unable to parse ingest status request"))
+		return
+	}
+
+	job, err := task.FetchIngestJob(request.JobID)
+	if err != nil {
+		handleErr(conn, msg, err)
+		return
+	}
+
+	// push each stage transition to the client until the job finishes or
+	// the connection closes
+	err = job.Listen(func(status task.IngestJobStatus) {
+		handleSuccess(conn, msg, jutil.StructToMap(status))
+	}, conn.Done())
+	if err != nil {
+		handleErr(conn, msg, err)
+		return
+	}
+
+	// complete the request
+	handleComplete(conn, msg)
+}
@@ -33,12 +33,14 @@ import (
 )
 
 const (
-	createSolutions   = "CREATE_SOLUTIONS"
-	stopSolutions     = "STOP_SOLUTIONS"
-	categoricalType   = "categorical"
-	numericalType     = "numerical"
-	defaultResourceID = "0"
-	datasetSizeLimit  = 10000
+	createSolutions    = "CREATE_SOLUTIONS"
+	stopSolutions      = "STOP_SOLUTIONS"
+	subscribeSolutions = "SUBSCRIBE_SOLUTIONS"
+	ingestStatus       = "INGEST_STATUS"
+	categoricalType    = "categorical"
+	numericalType      = "numerical"
+	defaultResourceID  = "0"
+	datasetSizeLimit   = 10000
 )
 
 var (
@@ -105,6 +107,12 @@ func handleMessage(conn *Connection, client *compute.Client, metadataCtor model.
 	case stopSolutions:
 		handleStopSolutions(conn, client, msg)
 		return
+	case subscribeSolutions:
+		handleSubscribeSolutions(conn, solutionCtor, msg)
+		return
+	case ingestStatus:
+		handleIngestStatus(conn, msg)
+		return
 	default:
 		// unrecognized type
 		handleErr(conn, msg, errors.New("unrecognized message type"))
@@ -141,6 +149,14 @@ func handleCreateSolutions(conn *Connection, client *compute.Client, metadataCto
 		return
 	}
 
+	// every target must exist, the first one guiding the defaults
+	for _, targetFeature := range request.TargetFeatures {
+		_, err = metaStorage.FetchVariable(request.Dataset, targetFeature)
+		if err != nil {
+			handleErr(conn, msg, err)
+			return
+		}
+	}
 	targetVar, err := metaStorage.FetchVariable(request.Dataset, request.TargetFeature)
 	if err != nil {
 		handleErr(conn, msg, err)
@@ -158,7 +174,7 @@ func handleCreateSolutions(conn *Connection, client *compute.Client, metadataCto
 		log.Infof("Defaulting task sub type to `%s`", request.SubTask)
 	}
 	if len(request.Metrics) == 0 {
-		request.Metrics = api.DefaultMetrics(targetVar.Type)
+		request.Metrics = api.DefaultTaskMetrics(request.Task, targetVar.Type)
 		log.Infof("Defaulting metrics to `%s`", strings.Join(request.Metrics, ","))
 	}
 	if request.MaxTime == 0 {
@@ -166,6 +182,26 @@ func handleCreateSolutions(conn *Connection, client *compute.Client, metadataCto
 		log.Infof("Defaulting max search time to `%d`", request.MaxTime)
 	}
 
+	// wait for the TA2 system to have capacity for the search
+	ticket, err := api.GetSearchScheduler().Enqueue(request, conn.User(), func(queueID string, position int) {
+		handleSuccess(conn, msg, jutil.StructToMap(api.SolutionStatus{
+			Progress:      api.RequestQueuedStatus,
+			RequestID:     queueID,
+			QueuePosition: position,
+			Timestamp:     time.Now(),
+		}))
+	})
+	if err != nil {
+		handleErr(conn, msg, err)
+		return
+	}
+	defer ticket.Release()
+	err = ticket.Wait(conn.Done())
+	if err != nil {
+		handleErr(conn, msg, err)
+		return
+	}
+
 	// persist the request information and dispatch the request
 	err = request.PersistAndDispatch(client, solutionStorage, metaStorage, dataStorage)
 	if err != nil {
@@ -208,3 +244,66 @@ func handleStopSolutions(conn *Connection, client *compute.Client, msg *Message)
 	}
 	return
 }
+
+func handleSubscribeSolutions(conn *Connection, solutionCtor model.SolutionStorageCtor, msg *Message) {
+	// unmarshal request
+	request, err := api.NewSubscribeSolutionsRequest(msg.Raw)
+	if err != nil {
+		handleErr(conn, msg, err)
+		return
+	}
+
+	// follow the request if it was resumed after a restart
+	resumed := api.GetResumedRequest(request.RequestID)
+	if resumed != nil {
+		err = resumed.Subscribe(func(status api.SolutionStatus) {
+			// check for error
+			if status.Error != nil {
+				handleErr(conn, msg, status.Error)
+				return
+			}
+			// send status to client
+			handleSuccess(conn, msg, jutil.StructToMap(status))
+		})
+		if err != nil {
+			handleErr(conn, msg, err)
+			return
+		}
+		handleComplete(conn, msg)
+		return
+	}
+
+	// otherwise send the stored status of the request
+	solutionStorage, err := solutionCtor()
+	if err != nil {
+		handleErr(conn, msg, err)
+		return
+	}
+
+	req, err := solutionStorage.FetchRequest(request.RequestID)
+	if err != nil {
+		handleErr(conn, msg, err)
+		return
+	}
+
+	if req.Progress == api.RequestErroredStatus {
+		state, err := solutionStorage.FetchRequestState(request.RequestID)
+		if err != nil {
+			handleErr(conn, msg, err)
+			return
+		}
+		reason := "request failed"
+		if state != nil && state.ErrorReason != "" {
+			reason = state.ErrorReason
+		}
+		handleErr(conn, msg, errors.New(reason))
+		return
+	}
+
+	handleSuccess(conn, msg, jutil.StructToMap(api.SolutionStatus{
+		RequestID: req.RequestID,
+		Progress:  req.Progress,
+		Timestamp: req.LastUpdatedTime,
+	}))
+	handleComplete(conn, msg)
+}
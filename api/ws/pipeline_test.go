@@ -0,0 +1,103 @@
+//
+//   Copyright © 2019 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package ws
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+
+	api "github.com/uncharted-distil/distil/api/compute"
+	"github.com/uncharted-distil/distil/api/compute/ta2mock"
+	"github.com/uncharted-distil/distil/api/model"
+)
+
+func TestCreateSolutions(t *testing.T) {
+	folder, err := ioutil.TempDir("", "ws")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+
+	dataset, err := ta2mock.WriteDataset(folder, "ws_dataset", 20)
+	assert.NoError(t, err)
+	api.SetDatasetDir(path.Join(folder, "tmp"))
+
+	ta2 := ta2mock.NewServer(&ta2mock.Config{Solutions: 2})
+	endpoint, err := ta2.Start()
+	assert.NoError(t, err)
+	defer ta2.Stop()
+
+	client, err := compute.NewClient(endpoint, false, "distil-test", 10*time.Second, 100, true)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	metaStorage := ta2mock.NewMetadataStorage(dataset)
+	dataStorage := ta2mock.NewDataStorage()
+	solutionStorage := ta2mock.NewSolutionStorage()
+
+	server := httptest.NewServer(http.HandlerFunc(SolutionHandler(client,
+		func() (model.MetadataStorage, error) { return metaStorage, nil },
+		func() (model.DataStorage, error) { return dataStorage, nil },
+		func() (model.SolutionStorage, error) { return solutionStorage, nil })))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(strings.Replace(server.URL, "http", "ws", 1), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.WriteJSON(map[string]interface{}{
+		"type":    createSolutions,
+		"id":      "create",
+		"target":  ta2mock.TargetName,
+		"dataset": "ws_dataset",
+		"filters": map[string]interface{}{
+			"variables": []string{ta2mock.FeatureName, ta2mock.TargetName},
+		},
+	})
+	assert.NoError(t, err)
+
+	// read the statuses until the request completes
+	conn.SetReadDeadline(time.Now().Add(time.Minute))
+	for {
+		response := make(map[string]interface{})
+		err = conn.ReadJSON(&response)
+		assert.NoError(t, err)
+		if err != nil {
+			break
+		}
+		assert.Equal(t, "create", response["id"])
+		assert.Nil(t, response["error"])
+		if response["complete"] == true {
+			break
+		}
+	}
+
+	// both solutions were produced for the ended search
+	ended := ta2.EndedSearches()
+	assert.Len(t, ended, 1)
+	solutionIDs, err := solutionStorage.FetchSolutionIDsByProgress(ended[0], []string{api.SolutionCompletedStatus})
+	assert.NoError(t, err)
+	assert.Len(t, solutionIDs, 2)
+	assert.Len(t, dataStorage.Results(), 2)
+}
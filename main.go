@@ -67,6 +67,11 @@ func registerRoutePost(mux *goji.Mux, pattern string, handler func(http.Response
 	mux.HandleFunc(pat.Post(pattern), handler)
 }
 
+func registerRouteDelete(mux *goji.Mux, pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	log.Infof("Registering DELETE route %s", pattern)
+	mux.HandleFunc(pat.Delete(pattern), handler)
+}
+
 func main() {
 	log.Infof("version: %s built: %s", version, timestamp)
 	servicesToWait := make(map[string]service.Heartbeat)
@@ -94,6 +99,25 @@ func main() {
 	api.SetInputDir(config.D3MInputDirRoot)
 	api.SetAugmentDir(path.Join(config.TmpDataPath, config.AugmentedSubFolder))
 
+	// set the checks flagging suspect solutions
+	err = api.SetSolutionChecks(strings.Split(config.SolutionChecks, ","))
+	if err != nil {
+		log.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	api.SetRangeCheckStdDevs(config.SolutionCheckStdDevs)
+
+	// limit the solution searches running on the TA2 system, budgets being
+	// minutes of search time per window
+	api.SetSearchScheduler(api.NewScheduler(&api.SchedulerConfig{
+		MaxConcurrent: config.SolutionSearchMaxConcurrent,
+		MaxPriority:   config.SolutionSearchMaxPriority,
+		UserBudget:    time.Duration(config.SolutionSearchUserBudget) * time.Minute,
+		GlobalBudget:  time.Duration(config.SolutionSearchGlobalBudget) * time.Minute,
+		BudgetWindow:  time.Duration(config.SolutionSearchBudgetWindow) * time.Minute,
+	}))
+	ws.SetUserHeader(config.SolutionSearchUserHeader)
+
 	// instantiate elastic client constructor.
 	esClientCtor := elastic.NewClient(config.ElasticEndpoint, false)
 
@@ -106,9 +130,11 @@ func main() {
 		_, err := postgresClientCtor()
 		return err == nil
 	}
-	servicesToWait["elastic"] = func() bool {
-		_, err := esClientCtor()
-		return err == nil
+	if config.MetadataStorage != pg.Provenance {
+		servicesToWait["elastic"] = func() bool {
+			_, err := esClientCtor()
+			return err == nil
+		}
 	}
 
 	// make sure a connection can be made to postgres - doesn't appear to be thread safe and
@@ -125,17 +151,47 @@ func main() {
 		}
 	}
 
-	// instantiate the metadata storage (using ES).
-	esMetadataStorageCtor := es.NewMetadataStorage(config.ESDatasetsIndex, esClientCtor)
+	// instantiate the metadata storage (using ES or postgres).
+	var metadataStorageCtor model.MetadataStorageCtor
+	if config.MetadataStorage == pg.Provenance {
+		metadataStorageCtor = pg.NewMetadataStorage(postgresClientCtor)
+
+		// make sure the metadata tables exist before any dataset is read
+		pgClient, err := postgresClientCtor()
+		if err != nil {
+			log.Errorf("%+v", err)
+			os.Exit(1)
+		}
+		err = pg.NewMetadataStorageFromClient(pgClient).CreateMetadataTables()
+		if err != nil {
+			log.Errorf("%+v", err)
+			os.Exit(1)
+		}
+	} else {
+		metadataStorageCtor = es.NewMetadataStorage(config.ESDatasetsIndex, esClientCtor)
+	}
 
 	// instantiate the metadata storage (using filesystem).
 	fileMetadataStorageCtor := file.NewMetadataStorage(config.TmpDataPath)
 
 	// instantiate the postgres data storage constructor.
-	pgDataStorageCtor := pg.NewDataStorage(postgresClientCtor, esMetadataStorageCtor)
+	pgDataStorageCtor := pg.NewDataStorage(postgresClientCtor, metadataStorageCtor)
 
 	// instantiate the postgres solution storage constructor.
-	pgSolutionStorageCtor := pg.NewSolutionStorage(postgresClientCtor, esMetadataStorageCtor)
+	pgSolutionStorageCtor := pg.NewSolutionStorage(postgresClientCtor, metadataStorageCtor)
+
+	// bring the solution metadata tables up to the expected schema version,
+	// refusing to start against a schema written by a newer build.
+	migrationClient, err := postgresClientCtor()
+	if err != nil {
+		log.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	err = pg.MigrateSolutionTables(migrationClient)
+	if err != nil {
+		log.Errorf("%+v", err)
+		os.Exit(1)
+	}
 
 	var solutionClient *compute.Client
 	if config.UseTA2Runner {
@@ -168,6 +224,22 @@ func main() {
 	}
 	defer solutionClient.Close()
 
+	// pick up the solution searches interrupted by the last shutdown
+	pgSolutionStorage, err := pgSolutionStorageCtor()
+	if err != nil {
+		log.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	pgDataStorage, err := pgDataStorageCtor()
+	if err != nil {
+		log.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	err = api.ResumeRequests(solutionClient, pgSolutionStorage, pgDataStorage)
+	if err != nil {
+		log.Errorf("unable to resume interrupted requests: %+v", err)
+	}
+
 	// reset the exported problem list
 	if config.IsTask1 {
 		problemListingFile := path.Join(config.UserProblemPath, routes.ProblemLabelFile)
@@ -226,7 +298,12 @@ func main() {
 		ESEndpoint:                         config.ElasticEndpoint,
 		ESTimeout:                          config.ElasticTimeout,
 		ESDatasetPrefix:                    config.ElasticDatasetPrefix,
+		MetadataStorage:                    config.MetadataStorage,
 		HardFail:                           config.IngestHardFail,
+		ResumeEnabled:                      config.IngestResumeEnabled,
+		RestageFrom:                        config.IngestRestageFrom,
+		MaxArchiveSize:                     config.MaxArchiveSize,
+		MaxArchiveEntries:                  config.MaxArchiveEntries,
 	}
 	sourceFolder := config.DataFolderPath
 
@@ -244,7 +321,8 @@ func main() {
 			log.Errorf("%+v", err)
 			os.Exit(1)
 		}
-		err = task.IngestDataset(metadata.Contrib, esMetadataStorageCtor, config.ESDatasetsIndex, "initial", ingestConfig)
+		job := task.SubmitIngestJob(metadata.Contrib, metadataStorageCtor, config.ESDatasetsIndex, "initial", ingestConfig)
+		err = job.Wait()
 		if err != nil {
 			log.Errorf("%+v", err)
 			os.Exit(1)
@@ -262,36 +340,46 @@ func main() {
 	routes.SetVerboseError(config.VerboseError)
 
 	// GET
-	registerRoute(mux, "/distil/datasets", routes.DatasetsHandler([]model.MetadataStorageCtor{esMetadataStorageCtor, nyuDatamartMetadataStorageCtor, isiDatamartMetadataStorageCtor}))
-	registerRoute(mux, "/distil/datasets/:dataset", routes.DatasetHandler(esMetadataStorageCtor))
+	registerRoute(mux, "/distil/datasets", routes.DatasetsHandler([]model.MetadataStorageCtor{metadataStorageCtor, nyuDatamartMetadataStorageCtor, isiDatamartMetadataStorageCtor}))
+	registerRoute(mux, "/distil/datasets/:dataset", routes.DatasetHandler(metadataStorageCtor))
 	registerRoute(mux, "/distil/solutions/:dataset/:target/:solution-id", routes.SolutionHandler(pgSolutionStorageCtor))
-	registerRoute(mux, "/distil/variables/:dataset", routes.VariablesHandler(esMetadataStorageCtor))
-	registerRoute(mux, "/distil/variable-rankings/:dataset/:target", routes.VariableRankingHandler(esMetadataStorageCtor))
-	registerRoute(mux, "/distil/residuals-extrema/:dataset/:target", routes.ResidualsExtremaHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
+	registerRoute(mux, "/distil/variables/:dataset", routes.VariablesHandler(metadataStorageCtor))
+	registerRoute(mux, "/distil/variable-rankings/:dataset/:target", routes.VariableRankingHandler(metadataStorageCtor))
+	registerRoute(mux, "/distil/residuals-extrema/:dataset/:target", routes.ResidualsExtremaHandler(metadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
 	registerRoute(mux, "/distil/abort", routes.AbortHandler())
-	registerRoute(mux, "/distil/export/:solution-id", routes.ExportHandler(pgSolutionStorageCtor, esMetadataStorageCtor, solutionClient, config.D3MOutputDir))
+	registerRoute(mux, "/distil/export/:solution-id", routes.ExportHandler(pgSolutionStorageCtor, metadataStorageCtor, solutionClient, config.D3MOutputDir, config.EvaluationMode))
 	registerRoute(mux, "/distil/config", routes.ConfigHandler(config, version, timestamp, problemPath, datasetDocPath))
-	registerRoute(mux, "/ws", ws.SolutionHandler(solutionClient, esMetadataStorageCtor, pgDataStorageCtor, pgSolutionStorageCtor))
+	registerRoute(mux, "/distil/ingest/:job-id", routes.IngestStatusHandler())
+	registerRoute(mux, "/distil/predictions/:results-uuid", routes.PredictionDownloadHandler(pgSolutionStorageCtor))
+	registerRoute(mux, "/distil/forecast/:dataset/:target/:results-uuid", routes.ForecastHandler(pgSolutionStorageCtor, pgDataStorageCtor))
+	registerRoute(mux, "/ws", ws.SolutionHandler(solutionClient, metadataStorageCtor, pgDataStorageCtor, pgSolutionStorageCtor))
 
 	// POST
-	registerRoutePost(mux, "/distil/variables/:dataset", routes.VariableTypeHandler(pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/discovery/:dataset/:target", routes.ProblemDiscoveryHandler(pgDataStorageCtor, esMetadataStorageCtor, config.UserProblemPath, userAgent, config.SkipPreprocessing))
-	registerRoutePost(mux, "/distil/data/:dataset/:invert", routes.DataHandler(pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/import/:datasetID/:source/:provenance", routes.ImportHandler(nyuDatamartMetadataStorageCtor, isiDatamartMetadataStorageCtor, fileMetadataStorageCtor, esMetadataStorageCtor, ingestConfig))
+	registerRoutePost(mux, "/distil/variables/:dataset", routes.VariableTypeHandler(pgDataStorageCtor))
+	registerRoutePost(mux, "/distil/discovery/:dataset/:target", routes.ProblemDiscoveryHandler(pgDataStorageCtor, metadataStorageCtor, config.UserProblemPath, userAgent, config.SkipPreprocessing))
+	registerRoutePost(mux, "/distil/data/:dataset/:invert", routes.DataHandler(pgDataStorageCtor, metadataStorageCtor))
+	registerRoutePost(mux, "/distil/import/:datasetID/:source/:provenance", routes.ImportHandler(nyuDatamartMetadataStorageCtor, isiDatamartMetadataStorageCtor, fileMetadataStorageCtor, metadataStorageCtor, ingestConfig))
+	registerRoutePost(mux, "/distil/predict/:solution-id", routes.PredictHandler(solutionClient, pgSolutionStorageCtor, metadataStorageCtor, pgDataStorageCtor,
+		fileMetadataStorageCtor, path.Join(config.TmpDataPath, config.AugmentedSubFolder), config.MaxUploadSize, ingestConfig))
 	registerRoutePost(mux, "/distil/results/:dataset/:solution-id", routes.ResultsHandler(pgSolutionStorageCtor, pgDataStorageCtor))
 	registerRoutePost(mux, "/distil/variable-summary/:dataset/:variable", routes.VariableSummaryHandler(pgDataStorageCtor))
 	registerRoutePost(mux, "/distil/training-summary/:dataset/:variable/:results-uuid", routes.TrainingSummaryHandler(pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/target-summary/:dataset/:target/:results-uuid", routes.TargetSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/residuals-summary/:dataset/:target/:results-uuid", routes.ResidualsSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
+	registerRoutePost(mux, "/distil/target-summary/:dataset/:target/:results-uuid", routes.TargetSummaryHandler(metadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
+	registerRoutePost(mux, "/distil/residuals-summary/:dataset/:target/:results-uuid", routes.ResidualsSummaryHandler(metadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
 	registerRoutePost(mux, "/distil/correctness-summary/:dataset/:results-uuid", routes.CorrectnessSummaryHandler(pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/predicted-summary/:dataset/:target/:results-uuid", routes.PredictedSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/geocode/:dataset/:variable", routes.GeocodingHandler(esMetadataStorageCtor, pgDataStorageCtor, sourceFolder))
-	registerRoutePost(mux, "/distil/upload/:dataset", routes.UploadHandler(path.Join(config.TmpDataPath, config.AugmentedSubFolder), ingestConfig))
-	registerRoutePost(mux, "/distil/join/:dataset-left/:column-left/:source-left/:dataset-right/:column-right/:source-right", routes.JoinHandler(esMetadataStorageCtor))
+	registerRoutePost(mux, "/distil/confusion-matrix/:dataset/:results-uuid", routes.ConfusionMatrixHandler(pgSolutionStorageCtor, pgDataStorageCtor))
+	registerRoutePost(mux, "/distil/result-output-summary/:dataset/:output/:results-uuid", routes.ResultOutputSummaryHandler(pgSolutionStorageCtor, pgDataStorageCtor))
+	registerRoutePost(mux, "/distil/predicted-summary/:dataset/:target/:results-uuid", routes.PredictedSummaryHandler(metadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
+	registerRoutePost(mux, "/distil/geocode/:dataset/:variable", routes.GeocodingHandler(metadataStorageCtor, pgDataStorageCtor, sourceFolder))
+	registerRoutePost(mux, "/distil/ingest/:job-id/cancel", routes.IngestCancelHandler())
+	registerRoutePost(mux, "/distil/ingest/:job-id/retry", routes.IngestRetryHandler())
+	registerRouteDelete(mux, "/distil/datasets/:dataset", routes.DatasetDeleteHandler(metadataStorageCtor, pgDataStorageCtor, pgSolutionStorageCtor))
+	registerRoutePost(mux, "/distil/upload/:dataset", routes.UploadHandler(path.Join(config.TmpDataPath, config.AugmentedSubFolder), config.MaxUploadSize, ingestConfig))
+	registerRoutePost(mux, "/distil/join/:dataset-left/:column-left/:source-left/:dataset-right/:column-right/:source-right", routes.JoinHandler(metadataStorageCtor))
 
 	// static
-	registerRoute(mux, "/distil/image/:dataset/:source/:file", routes.ImageHandler(esMetadataStorageCtor, &config))
-	registerRoute(mux, "/distil/timeseries/:dataset/:source/:file", routes.TimeseriesHandler(esMetadataStorageCtor, config.DataFolderPath, &config))
+	registerRoute(mux, "/distil/image/:dataset/:source/:file", routes.ImageHandler(metadataStorageCtor, &config))
+	registerRoute(mux, "/distil/timeseries/:dataset/:source/:file", routes.TimeseriesHandler(metadataStorageCtor, config.DataFolderPath, &config))
 	registerRoute(mux, "/distil/graphs/:dataset/:file", routes.GraphsHandler(config.DataFolderPath))
 	registerRoute(mux, "/*", routes.FileHandler("./dist"))
 
@@ -20,6 +20,26 @@ function getVariables(dataset: string): Promise<Variable[]> {
 	});
 }
 
+const INGEST_COMPLETED = 'INGEST_COMPLETED';
+const INGEST_ERRORED = 'INGEST_ERRORED';
+const INGEST_CANCELLED = 'INGEST_CANCELLED';
+const INGEST_POLL_INTERVAL = 2000;
+
+// polls an ingest job until it reaches a terminal state
+function waitForIngest(jobId: string): Promise<void> {
+	return axios.get(`/distil/ingest/${jobId}`).then(response => {
+		const progress = response.data.progress;
+		if (progress === INGEST_COMPLETED) {
+			return;
+		}
+		if (progress === INGEST_ERRORED || progress === INGEST_CANCELLED) {
+			throw new Error(`ingest job ${jobId} failed: ${response.data.error}`);
+		}
+		return new Promise<void>(resolve => setTimeout(resolve, INGEST_POLL_INTERVAL))
+			.then(() => waitForIngest(jobId));
+	});
+}
+
 export type DatasetContext = ActionContext<DatasetState, DistilState>;
 
 export const actions = {
@@ -129,6 +149,9 @@ export const actions = {
 		}
 		return axios.post(`/distil/import/${args.datasetID}/${args.source}/${args.provenance}`, {})
 			.then(response => {
+				return waitForIngest(response.data.jobId);
+			})
+			.then(() => {
 				return context.dispatch('searchDatasets', args.terms);
 			});
 	},